@@ -4,6 +4,7 @@ go 1.13
 
 require (
 	github.com/application-stacks/runtime-component-operator v0.6.0
+	github.com/blang/semver v3.5.1+incompatible
 	github.com/coreos/prometheus-operator v0.34.0
 	github.com/go-openapi/spec v0.19.4
 	github.com/jetstack/cert-manager v0.12.0
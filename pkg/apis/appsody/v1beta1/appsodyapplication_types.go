@@ -1,12 +1,15 @@
 package v1beta1
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/application-stacks/runtime-component-operator/pkg/common"
 	prometheusv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -55,6 +58,265 @@ type AppsodyApplicationSpec struct {
 	Route             *AppsodyRoute      `json:"route,omitempty"`
 	Bindings          *AppsodyBindings   `json:"bindings,omitempty"`
 	Affinity          *AppsodyAffinity   `json:"affinity,omitempty"`
+	Suspend           *bool              `json:"suspend,omitempty"`
+	// +listType=map
+	// +listMapKey=name
+	Schedule               []AppsodyScheduleWindow        `json:"schedule,omitempty"`
+	ResourceRecommendation *AppsodyResourceRecommendation `json:"resourceRecommendation,omitempty"`
+	// +kubebuilder:validation:Enum=Tag;Digest
+	ImagePinning       ImagePinning              `json:"imagePinning,omitempty"`
+	ImageUpdatePolicy  *AppsodyImageUpdatePolicy `json:"imageUpdatePolicy,omitempty"`
+	ConfigRollout      *AppsodyConfigRollout     `json:"configRollout,omitempty"`
+	DetectArchitecture *bool                     `json:"detectArchitecture,omitempty"`
+	DetectStack        *bool                     `json:"detectStack,omitempty"`
+	NetworkPolicy      *AppsodyNetworkPolicy     `json:"networkPolicy,omitempty"`
+	ServiceMesh        *AppsodyServiceMesh       `json:"serviceMesh,omitempty"`
+	Knative            *AppsodyKnative           `json:"knative,omitempty"`
+	Events             *AppsodyEvents            `json:"events,omitempty"`
+}
+
+// AppsodyEvents subscribes the application to CloudEvents with Knative Eventing
+type AppsodyEvents struct {
+	// Triggers delivering the events of brokers to the application
+	// +listType=map
+	// +listMapKey=name
+	Triggers []AppsodyEventTrigger `json:"triggers,omitempty"`
+	// Sources sending events directly to the application
+	// +listType=map
+	// +listMapKey=name
+	Sources []AppsodyEventSource `json:"sources,omitempty"`
+	// Path of the application receiving the events. Defaults to /.
+	Path string `json:"path,omitempty"`
+}
+
+// AppsodyEventTrigger delivers the events of a broker matching the filter to the application
+type AppsodyEventTrigger struct {
+	Name string `json:"name"`
+	// Name of the broker. Defaults to default.
+	Broker string `json:"broker,omitempty"`
+	// CloudEvents attributes the events must match, such as type and source
+	Filter map[string]string `json:"filter,omitempty"`
+}
+
+// AppsodyEventSource sends events to the application. Exactly one of ping and apiServer is set.
+type AppsodyEventSource struct {
+	Name      string                       `json:"name"`
+	Ping      *AppsodyPingSource           `json:"ping,omitempty"`
+	APIServer *AppsodyAPIServerEventSource `json:"apiServer,omitempty"`
+}
+
+// AppsodyPingSource sends an event on a cron schedule
+type AppsodyPingSource struct {
+	Schedule    string `json:"schedule"`
+	ContentType string `json:"contentType,omitempty"`
+	Data        string `json:"data,omitempty"`
+}
+
+// AppsodyAPIServerEventSource sends the events of Kubernetes resources
+type AppsodyAPIServerEventSource struct {
+	// +listType=atomic
+	Resources []AppsodyAPIServerResource `json:"resources"`
+	// Whether the events hold a reference to the resources or the resources. Defaults to Reference.
+	// +kubebuilder:validation:Enum=Reference;Resource
+	Mode string `json:"mode,omitempty"`
+	// Service account allowed to watch the resources
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// AppsodyAPIServerResource selects Kubernetes resources of a kind
+type AppsodyAPIServerResource struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Selector   *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// AppsodyKnative configures the revisions and the autoscaling of the Knative Service of the application
+type AppsodyKnative struct {
+	// Maximum number of concurrent requests per pod. Defaults to 0, for no limit.
+	// +kubebuilder:validation:Minimum=0
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+	// Maximum duration of a request, in seconds.
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	MinScale *int32 `json:"minScale,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxScale *int32 `json:"maxScale,omitempty"`
+	// Percentage of the concurrency limit targeted by the autoscaler.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	TargetUtilizationPercentage *int32 `json:"targetUtilizationPercentage,omitempty"`
+	// Minimum duration the last pod is kept after the autoscaler decided to scale to zero.
+	// +kubebuilder:validation:Pattern=^[0-9]+(s|m|h)$
+	ScaleToZeroPodRetentionPeriod string `json:"scaleToZeroPodRetentionPeriod,omitempty"`
+	// Traffic split between the revisions. Defaults to all the traffic to the latest revision.
+	// +listType=atomic
+	Traffic []AppsodyKnativeTraffic `json:"traffic,omitempty"`
+}
+
+// AppsodyKnativeTraffic routes a percentage of the traffic to a revision of the Knative Service
+type AppsodyKnativeTraffic struct {
+	// Name of the revision. The latest ready revision is used when it is not set.
+	RevisionName string `json:"revisionName,omitempty"`
+	// Percentage of the traffic. The percentages of all the entries add up to 100.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent *int32 `json:"percent,omitempty"`
+	// Tag exposing the revision on a dedicated URL
+	Tag string `json:"tag,omitempty"`
+}
+
+// AppsodyServiceMesh configures the routing of the Istio service mesh to the application
+type AppsodyServiceMesh struct {
+	// Injects the Istio sidecar in the application pods. Defaults to true.
+	SidecarInjection *bool `json:"sidecarInjection,omitempty"`
+	// Protocol of the service ports, used to name them. Defaults to http.
+	// +kubebuilder:validation:Enum=http;http2;https;grpc;tcp;tls
+	Protocol string `json:"protocol,omitempty"`
+	// Hosts routed to the application in addition to its service
+	// +listType=set
+	Hosts []string `json:"hosts,omitempty"`
+	// Gateways routing the hosts to the application, in addition to the sidecars of the mesh
+	// +listType=set
+	Gateways []string `json:"gateways,omitempty"`
+	// Timeout of the HTTP requests, e.g. 10s
+	// +kubebuilder:validation:Pattern=^[0-9]+(ms|s|m|h)$
+	Timeout string                     `json:"timeout,omitempty"`
+	Retries *AppsodyServiceMeshRetries `json:"retries,omitempty"`
+	// TLS mode of the connections to the application. Defaults to ISTIO_MUTUAL.
+	// +kubebuilder:validation:Enum=DISABLE;SIMPLE;MUTUAL;ISTIO_MUTUAL
+	TLSMode        string                 `json:"tlsMode,omitempty"`
+	CircuitBreaker *AppsodyCircuitBreaker `json:"circuitBreaker,omitempty"`
+}
+
+// AppsodyServiceMeshRetries retries the failed HTTP requests to the application
+type AppsodyServiceMeshRetries struct {
+	// +kubebuilder:validation:Minimum=0
+	Attempts int32 `json:"attempts"`
+	// +kubebuilder:validation:Pattern=^[0-9]+(ms|s|m|h)$
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	// Conditions retried, e.g. 5xx,connect-failure
+	RetryOn string `json:"retryOn,omitempty"`
+}
+
+// AppsodyCircuitBreaker limits the connections to the application and ejects the failing pods from the load balancing
+type AppsodyCircuitBreaker struct {
+	// +kubebuilder:validation:Minimum=1
+	MaxConnections *int32 `json:"maxConnections,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxPendingRequests *int32 `json:"maxPendingRequests,omitempty"`
+	// Number of consecutive errors ejecting a pod. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	ConsecutiveErrors *int32 `json:"consecutiveErrors,omitempty"`
+	// Interval between the ejection analysis. Defaults to 10s.
+	// +kubebuilder:validation:Pattern=^[0-9]+(ms|s|m|h)$
+	Interval string `json:"interval,omitempty"`
+	// Minimum ejection duration. Defaults to 30s.
+	// +kubebuilder:validation:Pattern=^[0-9]+(ms|s|m|h)$
+	BaseEjectionTime string `json:"baseEjectionTime,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxEjectionPercent *int32 `json:"maxEjectionPercent,omitempty"`
+}
+
+// AppsodyNetworkPolicy restricts the traffic of the application pods to the peers derived from the exposure,
+// monitoring and service consumption of the application
+type AppsodyNetworkPolicy struct {
+	// Additional peers allowed to reach the application pods on all ports
+	// +listType=atomic
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+	// Restricts the traffic from the application pods when set
+	Egress *AppsodyNetworkPolicyEgress `json:"egress,omitempty"`
+}
+
+// AppsodyNetworkPolicyEgress restricts the traffic from the application pods to DNS, the consumed services and the
+// listed peers
+type AppsodyNetworkPolicyEgress struct {
+	// Additional peers the application pods are allowed to reach on all ports
+	// +listType=atomic
+	To []networkingv1.NetworkPolicyPeer `json:"to,omitempty"`
+}
+
+// AppsodyConfigRollout restarts the pods of the application when the ConfigMaps or Secrets they reference change
+type AppsodyConfigRollout struct {
+	// Defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+	// ConfigMaps whose changes don't restart the pods
+	// +listType=set
+	ExcludeConfigMaps []string `json:"excludeConfigMaps,omitempty"`
+	// Secrets whose changes don't restart the pods
+	// +listType=set
+	ExcludeSecrets []string `json:"excludeSecrets,omitempty"`
+}
+
+// AppsodyImageUpdatePolicy polls the registry of the application image for newer tags
+type AppsodyImageUpdatePolicy struct {
+	// +kubebuilder:validation:Enum=Auto;Manual
+	Mode ImageUpdateMode `json:"mode,omitempty"`
+	// Semantic version range the tags must satisfy, such as ">=1.2.0 <2.0.0"
+	SemverRange string `json:"semverRange,omitempty"`
+	// Regular expression the tags must match
+	TagPattern string `json:"tagPattern,omitempty"`
+	// Seconds between two polls of the registry. Defaults to 300.
+	// +kubebuilder:validation:Minimum=60
+	PollIntervalSeconds *int32 `json:"pollIntervalSeconds,omitempty"`
+}
+
+// ImageUpdateMode defines whether newer images found in the registry are deployed
+type ImageUpdateMode string
+
+const (
+	// ImageUpdateModeAuto deploys the newest image matching the policy
+	ImageUpdateModeAuto ImageUpdateMode = "Auto"
+
+	// ImageUpdateModeManual only reports that a newer image is available
+	ImageUpdateModeManual ImageUpdateMode = "Manual"
+)
+
+// ImagePinning defines how the application image is referenced by the pods
+type ImagePinning string
+
+const (
+	// ImagePinningTag references the application image by tag
+	ImagePinningTag ImagePinning = "Tag"
+
+	// ImagePinningDigest references the application image by the digest its tag resolved to
+	ImagePinningDigest ImagePinning = "Digest"
+)
+
+// AppsodyResourceRecommendation creates a VerticalPodAutoscaler that recommends the resources of the application
+type AppsodyResourceRecommendation struct {
+	// +kubebuilder:validation:Enum=Off;Auto
+	UpdateMode ResourceRecommendationUpdateMode `json:"updateMode,omitempty"`
+	// Difference, as a percentage of the requested resources, above which a warning is raised. Defaults to 50.
+	// +kubebuilder:validation:Minimum=1
+	TolerancePercentage *int32 `json:"tolerancePercentage,omitempty"`
+}
+
+// ResourceRecommendationUpdateMode defines whether the VerticalPodAutoscaler applies its recommendations
+type ResourceRecommendationUpdateMode string
+
+const (
+	// ResourceRecommendationUpdateModeOff only records recommendations
+	ResourceRecommendationUpdateModeOff ResourceRecommendationUpdateMode = "Off"
+
+	// ResourceRecommendationUpdateModeAuto applies recommendations by evicting and recreating pods
+	ResourceRecommendationUpdateModeAuto ResourceRecommendationUpdateMode = "Auto"
+)
+
+// AppsodyScheduleWindow overrides the replica count or autoscaling bounds of the application from the time its
+// schedule fires until another window of the schedule starts
+type AppsodyScheduleWindow struct {
+	Name string `json:"name"`
+	// Cron expression of the start of the window
+	Schedule string `json:"schedule"`
+	// IANA time zone the schedule is evaluated in, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	Replicas    *int32 `json:"replicas,omitempty"`
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
 }
 
 // AppsodyAffinity deployment affinity settings
@@ -76,6 +338,66 @@ type AppsodyApplicationAutoScaling struct {
 
 	// +kubebuilder:validation:Minimum=1
 	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// +listType=atomic
+	Metrics     []autoscalingv2beta2.MetricSpec    `json:"metrics,omitempty"`
+	Behavior    *AppsodyApplicationScalingBehavior `json:"behavior,omitempty"`
+	EventDriven *AppsodyEventDrivenAutoscaling     `json:"eventDriven,omitempty"`
+}
+
+// AppsodyEventDrivenAutoscaling scales the application with a KEDA ScaledObject instead of a HorizontalPodAutoscaler
+type AppsodyEventDrivenAutoscaling struct {
+	// +kubebuilder:validation:Minimum=1
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	Triggers       []AppsodyScaleTrigger         `json:"triggers"`
+	Authentication *AppsodyTriggerAuthentication `json:"authentication,omitempty"`
+}
+
+// AppsodyScaleTrigger is a KEDA scaler, such as `kafka` or `rabbitmq`, and its metadata
+type AppsodyScaleTrigger struct {
+	Type     string            `json:"type"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// AppsodyTriggerAuthentication passes keys of a secret to the parameters of the triggers
+type AppsodyTriggerAuthentication struct {
+	SecretName string `json:"secretName"`
+	// Maps trigger parameters to keys of the secret
+	Parameters map[string]string `json:"parameters"`
+}
+
+// AppsodyApplicationScalingBehavior configures how fast the HorizontalPodAutoscaler scales up and down
+type AppsodyApplicationScalingBehavior struct {
+	ScaleUp   *AppsodyApplicationScalingRules `json:"scaleUp,omitempty"`
+	ScaleDown *AppsodyApplicationScalingRules `json:"scaleDown,omitempty"`
+}
+
+// AppsodyApplicationScalingRules configures scaling in one direction
+type AppsodyApplicationScalingRules struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	StabilizationWindowSeconds *int32 `json:"stabilizationWindowSeconds,omitempty"`
+	// +kubebuilder:validation:Enum=Max;Min;Disabled
+	SelectPolicy *string `json:"selectPolicy,omitempty"`
+	// +listType=atomic
+	Policies []AppsodyApplicationScalingPolicy `json:"policies,omitempty"`
+}
+
+// AppsodyApplicationScalingPolicy limits the change of replicas over a period of time
+type AppsodyApplicationScalingPolicy struct {
+	// +kubebuilder:validation:Enum=Pods;Percent
+	Type string `json:"type"`
+	// +kubebuilder:validation:Minimum=1
+	Value int32 `json:"value"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1800
+	PeriodSeconds int32 `json:"periodSeconds"`
 }
 
 // AppsodyApplicationService ...
@@ -95,6 +417,9 @@ type AppsodyApplicationService struct {
 	NodePort *int32 `json:"nodePort,omitempty"`
 
 	PortName string `json:"portName,omitempty"`
+	// Protocol of the application port. Defaults to http.
+	// +kubebuilder:validation:Enum=http;grpc;h2c;tcp
+	Protocol ServiceProtocol `json:"protocol,omitempty"`
 
 	Ports []corev1.ServicePort `json:"ports,omitempty"`
 
@@ -132,8 +457,59 @@ type AppsodyApplicationStorage struct {
 	Size                string                        `json:"size,omitempty"`
 	MountPath           string                        `json:"mountPath,omitempty"`
 	VolumeClaimTemplate *corev1.PersistentVolumeClaim `json:"volumeClaimTemplate,omitempty"`
+	// +kubebuilder:validation:Enum=perPod;shared
+	Mode StorageMode `json:"mode,omitempty"`
+	// +kubebuilder:validation:Enum=Retain;Delete
+	ReclaimPolicy StorageReclaimPolicy  `json:"reclaimPolicy,omitempty"`
+	Backup        *AppsodyStorageBackup `json:"backup,omitempty"`
+}
+
+// AppsodyStorageBackup defines volume snapshot backups of the application's persistent volume claims
+type AppsodyStorageBackup struct {
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	BeforeRollout           *bool   `json:"beforeRollout,omitempty"`
+	Schedule                string  `json:"schedule,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	Retention   *int32 `json:"retention,omitempty"`
+	RestoreFrom string `json:"restoreFrom,omitempty"`
 }
 
+// ServiceProtocol defines the protocol of the application port
+type ServiceProtocol string
+
+const (
+	// ServiceProtocolHTTP serves HTTP/1.1. This is the default.
+	ServiceProtocolHTTP ServiceProtocol = "http"
+	// ServiceProtocolGRPC serves gRPC over HTTP/2
+	ServiceProtocolGRPC ServiceProtocol = "grpc"
+	// ServiceProtocolH2C serves HTTP/2 over cleartext
+	ServiceProtocolH2C ServiceProtocol = "h2c"
+	// ServiceProtocolTCP serves another protocol over TCP
+	ServiceProtocolTCP ServiceProtocol = "tcp"
+)
+
+// StorageMode defines how persistent storage is provisioned for the application
+type StorageMode string
+
+const (
+	// StorageModePerPod provisions a volume per pod using a StatefulSet
+	StorageModePerPod StorageMode = "perPod"
+
+	// StorageModeShared provisions a single ReadWriteMany volume shared by all pods of a Deployment
+	StorageModeShared StorageMode = "shared"
+)
+
+// StorageReclaimPolicy defines what happens to a shared volume claim when it is no longer used
+type StorageReclaimPolicy string
+
+const (
+	// StorageReclaimPolicyRetain keeps the shared volume claim
+	StorageReclaimPolicyRetain StorageReclaimPolicy = "Retain"
+
+	// StorageReclaimPolicyDelete deletes the shared volume claim along with the application
+	StorageReclaimPolicyDelete StorageReclaimPolicy = "Delete"
+)
+
 // AppsodyApplicationMonitoring ...
 type AppsodyApplicationMonitoring struct {
 	Labels    map[string]string       `json:"labels,omitempty"`
@@ -150,8 +526,40 @@ type AppsodyRoute struct {
 	CertificateSecretRef          *string                                    `json:"certificateSecretRef,omitempty"`
 	Host                          string                                     `json:"host,omitempty"`
 	Path                          string                                     `json:"path,omitempty"`
+	// +listType=atomic
+	Headers []AppsodyHTTPHeaderMatch `json:"headers,omitempty"`
+	Gateway *AppsodyGatewayReference `json:"gateway,omitempty"`
+	// Name of the IngressClass of the Ingress. Defaults to the defaultIngressClass operator configuration.
+	IngressClassName string `json:"ingressClassName,omitempty"`
 }
 
+// AppsodyGatewayReference is the Gateway API Gateway the HTTPRoute of the application is attached to
+type AppsodyGatewayReference struct {
+	Name string `json:"name"`
+	// Defaults to the namespace of the application
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the listener of the Gateway
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// AppsodyHTTPHeaderMatch matches the requests routed to the application by the value of a header
+type AppsodyHTTPHeaderMatch struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// +kubebuilder:validation:Enum=Exact;RegularExpression
+	Type HTTPHeaderMatchType `json:"type,omitempty"`
+}
+
+// HTTPHeaderMatchType defines how the value of a header is matched
+type HTTPHeaderMatchType string
+
+const (
+	// HTTPHeaderMatchExact matches the exact value of the header. This is the default.
+	HTTPHeaderMatchExact HTTPHeaderMatchType = "Exact"
+	// HTTPHeaderMatchRegularExpression matches the value of the header with a regular expression
+	HTTPHeaderMatchRegularExpression HTTPHeaderMatchType = "RegularExpression"
+)
+
 // ServiceBindingAuth allows a service to provide authentication information
 type ServiceBindingAuth struct {
 	// The secret that contains the username for authenticating
@@ -176,6 +584,128 @@ type AppsodyApplicationStatus struct {
 	// +listType=set
 	ResolvedBindings []string `json:"resolvedBindings,omitempty"`
 	ImageReference   string   `json:"imageReference,omitempty"`
+	// +listType=atomic
+	Snapshots      []StatusSnapshot `json:"snapshots,omitempty"`
+	RestoredFrom   string           `json:"restoredFrom,omitempty"`
+	Suspended      bool             `json:"suspended,omitempty"`
+	ScheduleWindow string           `json:"scheduleWindow,omitempty"`
+
+	ResourceRecommendation *StatusResourceRecommendation `json:"resourceRecommendation,omitempty"`
+	PinnedImage            string                        `json:"pinnedImage,omitempty"`
+	// Version of the image policies the application image was verified against
+	VerifiedImagePolicy string             `json:"verifiedImagePolicy,omitempty"`
+	ImageUpdate         *StatusImageUpdate `json:"imageUpdate,omitempty"`
+	// +listType=map
+	// +listMapKey=container
+	RewrittenImages    []StatusRewrittenImage    `json:"rewrittenImages,omitempty"`
+	ImageArchitectures *StatusImageArchitectures `json:"imageArchitectures,omitempty"`
+	DetectedStack      *StatusDetectedStack      `json:"detectedStack,omitempty"`
+	Ingress            *StatusIngress            `json:"ingress,omitempty"`
+	Knative            *StatusKnative            `json:"knative,omitempty"`
+	ModeTransition     *StatusModeTransition     `json:"modeTransition,omitempty"`
+}
+
+// StatusModeTransition reports the switch of the application between the Deployment, StatefulSet and Knative modes.
+// The resources of the previous mode keep serving until the resources of the new mode are ready.
+type StatusModeTransition struct {
+	From      ApplicationMode     `json:"from"`
+	To        ApplicationMode     `json:"to"`
+	Phase     ModeTransitionPhase `json:"phase"`
+	StartTime *metav1.Time        `json:"startTime,omitempty"`
+	// Per-pod volume claims of the StatefulSet that are kept but no longer used by the application
+	AbandonedClaims []string `json:"abandonedClaims,omitempty"`
+}
+
+// ApplicationMode defines how the application is run
+type ApplicationMode string
+
+const (
+	// ApplicationModeDeployment runs the application with a Deployment
+	ApplicationModeDeployment ApplicationMode = "Deployment"
+	// ApplicationModeStatefulSet runs the application with a StatefulSet and a volume per pod
+	ApplicationModeStatefulSet ApplicationMode = "StatefulSet"
+	// ApplicationModeKnative runs the application with a Knative Service
+	ApplicationModeKnative ApplicationMode = "Knative"
+)
+
+// ModeTransitionPhase defines the progress of a switch between modes
+type ModeTransitionPhase string
+
+const (
+	// ModeTransitionPhaseProvisioning waits for the resources of the new mode to be ready
+	ModeTransitionPhaseProvisioning ModeTransitionPhase = "Provisioning"
+	// ModeTransitionPhaseCleaningUp moves the exposure to the new mode and deletes the resources of the previous mode
+	ModeTransitionPhaseCleaningUp ModeTransitionPhase = "CleaningUp"
+)
+
+// StatusKnative reports the revisions of the Knative Service of the application and their traffic
+type StatusKnative struct {
+	APIVersion                string `json:"apiVersion"`
+	URL                       string `json:"url,omitempty"`
+	LatestCreatedRevisionName string `json:"latestCreatedRevisionName,omitempty"`
+	LatestReadyRevisionName   string `json:"latestReadyRevisionName,omitempty"`
+	// +listType=atomic
+	Traffic []StatusKnativeTraffic `json:"traffic,omitempty"`
+}
+
+// StatusKnativeTraffic reports the traffic routed to a revision of the Knative Service
+type StatusKnativeTraffic struct {
+	RevisionName   string `json:"revisionName,omitempty"`
+	LatestRevision bool   `json:"latestRevision,omitempty"`
+	Percent        int32  `json:"percent"`
+	Tag            string `json:"tag,omitempty"`
+	URL            string `json:"url,omitempty"`
+}
+
+// StatusIngress reports the Ingress exposing the application
+type StatusIngress struct {
+	APIVersion    string `json:"apiVersion"`
+	Host          string `json:"host,omitempty"`
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// StatusDetectedStack reports the Appsody stack read from the labels of the application image
+type StatusDetectedStack struct {
+	Image   string `json:"image"`
+	ID      string `json:"id,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// StatusImageArchitectures reports the architectures of the linux platforms provided by the application image
+type StatusImageArchitectures struct {
+	Image string `json:"image"`
+	// +listType=set
+	Architectures []string `json:"architectures,omitempty"`
+}
+
+// StatusRewrittenImage records the original image of a container whose image was rewritten by the registry rewrite
+// rules of the operator
+type StatusRewrittenImage struct {
+	Container     string `json:"container"`
+	OriginalImage string `json:"originalImage"`
+	Image         string `json:"image"`
+}
+
+// StatusImageUpdate reports the newest image found in the registry by the image update policy
+type StatusImageUpdate struct {
+	LatestImage  string       `json:"latestImage,omitempty"`
+	LastPollTime *metav1.Time `json:"lastPollTime,omitempty"`
+}
+
+// StatusResourceRecommendation reports the resources recommended by the VerticalPodAutoscaler for the application container
+type StatusResourceRecommendation struct {
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	Limits   corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// StatusSnapshot represents a volume snapshot of one of the application's persistent volume claims
+type StatusSnapshot struct {
+	Name         string       `json:"name"`
+	BackupID     string       `json:"backupID"`
+	ClaimName    string       `json:"claimName"`
+	Trigger      string       `json:"trigger,omitempty"`
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	ReadyToUse   bool         `json:"readyToUse"`
 }
 
 // StatusCondition ...
@@ -198,6 +728,36 @@ const (
 
 	// StatusConditionTypeDependenciesSatisfied ...
 	StatusConditionTypeDependenciesSatisfied StatusConditionType = "DependenciesSatisfied"
+
+	// StatusConditionTypeAutoscalingSupported is false when requested autoscaling settings are not supported by the cluster
+	StatusConditionTypeAutoscalingSupported StatusConditionType = "AutoscalingSupported"
+
+	// StatusConditionTypeImageVerified is false when the signature of the application image could not be verified
+	StatusConditionTypeImageVerified StatusConditionType = "ImageVerified"
+
+	// StatusConditionTypeArchitectureSupported is false when the application image doesn't provide one of the requested architectures
+	StatusConditionTypeArchitectureSupported StatusConditionType = "ArchitectureSupported"
+
+	// StatusConditionTypeStackMatched is false when the stack of the application image differs from the declared stack
+	StatusConditionTypeStackMatched StatusConditionType = "StackMatched"
+
+	// StatusConditionTypeStackCompliant is false when the stack of the application is not allowed by a stack policy
+	StatusConditionTypeStackCompliant StatusConditionType = "StackCompliant"
+
+	// StatusConditionTypeIngressTLSReady is false while the TLS secret of the Ingress is missing
+	StatusConditionTypeIngressTLSReady StatusConditionType = "IngressTLSReady"
+
+	// StatusConditionTypeEventsReady is false while the triggers and the event sources of the application are not ready
+	StatusConditionTypeEventsReady StatusConditionType = "EventsReady"
+
+	// StatusConditionTypeNetworkPolicyComplete is false when the NetworkPolicy can't allow the router or Prometheus because the selector of their namespaces is not configured
+	StatusConditionTypeNetworkPolicyComplete StatusConditionType = "NetworkPolicyComplete"
+
+	// StatusConditionTypeGatewayTLSReady is false when no listener of the Gateway terminates TLS with the certificate of the application
+	StatusConditionTypeGatewayTLSReady StatusConditionType = "GatewayTLSReady"
+
+	// StatusConditionTypeConfigWatched is false when ConfigMaps or Secrets referenced by the application are not labelled for config rollout, so their changes are polled instead of watched
+	StatusConditionTypeConfigWatched StatusConditionType = "ConfigWatched"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -448,6 +1008,21 @@ func (a *AppsodyApplicationAutoScaling) GetTargetCPUUtilizationPercentage() *int
 	return a.TargetCPUUtilizationPercentage
 }
 
+// GetTargetMemoryUtilizationPercentage returns target memory usage
+func (a *AppsodyApplicationAutoScaling) GetTargetMemoryUtilizationPercentage() *int32 {
+	return a.TargetMemoryUtilizationPercentage
+}
+
+// IsEventDriven returns true if the application is scaled by a KEDA ScaledObject
+func (a *AppsodyApplicationAutoScaling) IsEventDriven() bool {
+	return a.EventDriven != nil
+}
+
+// RequiresV2beta2 returns true if the autoscaling settings can't be expressed by an autoscaling/v1 HorizontalPodAutoscaler
+func (a *AppsodyApplicationAutoScaling) RequiresV2beta2() bool {
+	return a.TargetMemoryUtilizationPercentage != nil || len(a.Metrics) > 0 || a.Behavior != nil
+}
+
 // GetSize returns persistent volume size
 func (s *AppsodyApplicationStorage) GetSize() string {
 	return s.Size
@@ -463,6 +1038,31 @@ func (s *AppsodyApplicationStorage) GetVolumeClaimTemplate() *corev1.PersistentV
 	return s.VolumeClaimTemplate
 }
 
+// GetMode returns storage mode
+func (s *AppsodyApplicationStorage) GetMode() StorageMode {
+	return s.Mode
+}
+
+// GetReclaimPolicy returns reclaim policy of the shared volume claim
+func (s *AppsodyApplicationStorage) GetReclaimPolicy() StorageReclaimPolicy {
+	return s.ReclaimPolicy
+}
+
+// GetBackup returns volume snapshot backup settings
+func (s *AppsodyApplicationStorage) GetBackup() *AppsodyStorageBackup {
+	return s.Backup
+}
+
+// IsShared returns true if a single volume should be shared by all pods
+func (s *AppsodyApplicationStorage) IsShared() bool {
+	return s.Mode == StorageModeShared
+}
+
+// IsBeforeRolloutEnabled returns true if snapshots should be taken before the pod template changes
+func (b *AppsodyStorageBackup) IsBeforeRolloutEnabled() bool {
+	return b.BeforeRollout == nil || *b.BeforeRollout
+}
+
 // GetAnnotations returns a set of annotations to be added to the service
 func (s *AppsodyApplicationService) GetAnnotations() map[string]string {
 	return s.Annotations
@@ -489,6 +1089,16 @@ func (s *AppsodyApplicationService) GetTargetPort() *int32 {
 	return s.TargetPort
 }
 
+// IsTLS returns true if the application port serves TLS, with a certificate issued or set for the service
+func (s *AppsodyApplicationService) IsTLS() bool {
+	return s.Certificate != nil || (s.CertificateSecretRef != nil && *s.CertificateSecretRef != "")
+}
+
+// IsHTTP2 returns true if the application port serves HTTP/2, with gRPC or over cleartext
+func (s *AppsodyApplicationService) IsHTTP2() bool {
+	return s.Protocol == ServiceProtocolGRPC || s.Protocol == ServiceProtocolH2C
+}
+
 // GetPortName returns name of service port
 func (s *AppsodyApplicationService) GetPortName() string {
 	return s.PortName
@@ -675,6 +1285,115 @@ func (a *AppsodyAffinity) GetNodeAffinityLabels() map[string]string {
 	return a.NodeAffinityLabels
 }
 
+// meshProtocols maps the protocols of the application port to the protocols of the service mesh
+var meshProtocols = map[ServiceProtocol]string{
+	ServiceProtocolHTTP: "http",
+	ServiceProtocolGRPC: "grpc",
+	ServiceProtocolH2C:  "http2",
+	ServiceProtocolTCP:  "tcp",
+}
+
+// initializeHTTP2 sets the defaults of applications serving HTTP/2. The port is named after the protocol, as HTTP/2
+// is negotiated from the port name, and Knative requires the h2c name. Routes to gRPC services serving TLS re-encrypt
+// instead of terminating TLS at the edge, which downgrades to HTTP/1.1.
+func (cr *AppsodyApplication) initializeHTTP2() {
+	svc := cr.Spec.Service
+	if svc.PortName == "" {
+		if cr.Spec.CreateKnativeService != nil && *cr.Spec.CreateKnativeService {
+			svc.PortName = string(ServiceProtocolH2C)
+		} else if cr.Spec.ServiceMesh == nil {
+			svc.PortName = fmt.Sprintf("%s-%d", svc.Protocol, svc.Port)
+		}
+	}
+
+	if svc.Protocol != ServiceProtocolGRPC {
+		return
+	}
+	if cr.Spec.Expose != nil && *cr.Spec.Expose && svc.IsTLS() {
+		if cr.Spec.Route == nil {
+			cr.Spec.Route = &AppsodyRoute{}
+		}
+		if cr.Spec.Route.Termination == nil {
+			termination := routev1.TLSTerminationReencrypt
+			cr.Spec.Route.Termination = &termination
+		}
+	}
+}
+
+// IsSidecarInjectionEnabled returns true if the Istio sidecar is injected in the application pods
+func (cr *AppsodyApplication) IsSidecarInjectionEnabled() bool {
+	return cr.Spec.ServiceMesh != nil && (cr.Spec.ServiceMesh.SidecarInjection == nil || *cr.Spec.ServiceMesh.SidecarInjection)
+}
+
+// IsStackDetectionEnabled returns true if the stack is read from the labels of the application image
+func (cr *AppsodyApplication) IsStackDetectionEnabled() bool {
+	return cr.Spec.DetectStack != nil && *cr.Spec.DetectStack
+}
+
+// GetStack returns the stack set in `stack`, or else the stack detected from the application image
+func (cr *AppsodyApplication) GetStack() string {
+	if cr.Spec.Stack == "" && cr.Status.DetectedStack != nil {
+		return cr.Status.DetectedStack.ID
+	}
+	return cr.Spec.Stack
+}
+
+// IsArchitectureDetectionEnabled returns true if the architectures of the application image are read from the registry
+func (cr *AppsodyApplication) IsArchitectureDetectionEnabled() bool {
+	return cr.Spec.DetectArchitecture != nil && *cr.Spec.DetectArchitecture
+}
+
+// GetRequestedArchitectures returns the architectures set in `architecture`, or else in `affinity.architecture`
+func (cr *AppsodyApplication) GetRequestedArchitectures() []string {
+	if len(cr.Spec.Architecture) > 0 || cr.Spec.Affinity == nil {
+		return cr.Spec.Architecture
+	}
+	return cr.Spec.Affinity.Architecture
+}
+
+// IsConfigRolloutEnabled returns true if the pods are restarted when the ConfigMaps or Secrets they reference change.
+// Config rollout is enabled unless configRollout.enabled is false.
+func (cr *AppsodyApplication) IsConfigRolloutEnabled() bool {
+	return cr.Spec.ConfigRollout == nil || cr.Spec.ConfigRollout.Enabled == nil || *cr.Spec.ConfigRollout.Enabled
+}
+
+// IsSuspended returns true if the application is scaled down to zero
+func (cr *AppsodyApplication) IsSuspended() bool {
+	return cr.Spec.Suspend != nil && *cr.Spec.Suspend
+}
+
+// GetUpdateMode returns the update mode of the VerticalPodAutoscaler, defaulting to Off
+func (r *AppsodyResourceRecommendation) GetUpdateMode() ResourceRecommendationUpdateMode {
+	if r.UpdateMode == "" {
+		return ResourceRecommendationUpdateModeOff
+	}
+	return r.UpdateMode
+}
+
+// GetTolerancePercentage returns the difference above which recommendations raise a warning
+func (r *AppsodyResourceRecommendation) GetTolerancePercentage() int32 {
+	if r.TolerancePercentage == nil {
+		return 50
+	}
+	return *r.TolerancePercentage
+}
+
+// GetMode returns the mode of the image update policy, defaulting to Auto
+func (p *AppsodyImageUpdatePolicy) GetMode() ImageUpdateMode {
+	if p.Mode == "" {
+		return ImageUpdateModeAuto
+	}
+	return p.Mode
+}
+
+// GetPollInterval returns the time between two polls of the registry
+func (p *AppsodyImageUpdatePolicy) GetPollInterval() time.Duration {
+	if p.PollIntervalSeconds == nil {
+		return 5 * time.Minute
+	}
+	return time.Duration(*p.PollIntervalSeconds) * time.Second
+}
+
 // Initialize the AppsodyApplication instance with values from the default and constant ConfigMap
 func (cr *AppsodyApplication) Initialize(defaults AppsodyApplicationSpec, constants *AppsodyApplicationSpec) {
 	if cr.Spec.PullPolicy == nil {
@@ -747,8 +1466,13 @@ func (cr *AppsodyApplication) Initialize(defaults AppsodyApplicationSpec, consta
 		cr.Spec.CreateKnativeService = defaults.CreateKnativeService
 	}
 
+	if cr.Spec.Knative == nil {
+		cr.Spec.Knative = defaults.Knative
+	}
+
+	// The service defaults are copied, as the service of the application is defaulted below
 	if cr.Spec.Service == nil {
-		cr.Spec.Service = defaults.Service
+		cr.Spec.Service = defaults.Service.DeepCopy()
 	}
 
 	// This is to handle when there is no service in the CR nor defaults
@@ -791,6 +1515,15 @@ func (cr *AppsodyApplication) Initialize(defaults AppsodyApplicationSpec, consta
 		}
 	}
 
+	if cr.Spec.Storage != nil {
+		if cr.Spec.Storage.Mode == "" {
+			cr.Spec.Storage.Mode = StorageModePerPod
+		}
+		if cr.Spec.Storage.ReclaimPolicy == "" {
+			cr.Spec.Storage.ReclaimPolicy = StorageReclaimPolicyRetain
+		}
+	}
+
 	if cr.Spec.Service.Provides != nil && cr.Spec.Service.Provides.Protocol == "" {
 		cr.Spec.Service.Provides.Protocol = "http"
 	}
@@ -799,6 +1532,28 @@ func (cr *AppsodyApplication) Initialize(defaults AppsodyApplicationSpec, consta
 		cr.applyConstants(defaults, constants)
 	}
 
+	if cr.Spec.Service.Protocol == "" {
+		cr.Spec.Service.Protocol = ServiceProtocolHTTP
+	}
+	if cr.Spec.Service.IsHTTP2() {
+		cr.initializeHTTP2()
+	}
+
+	if cr.Spec.ServiceMesh != nil {
+		if cr.Spec.ServiceMesh.Protocol == "" {
+			cr.Spec.ServiceMesh.Protocol = meshProtocols[cr.Spec.Service.Protocol]
+		}
+		// Name the service ports after their protocol, as required by the service mesh
+		if cr.Spec.Service.PortName == "" {
+			cr.Spec.Service.PortName = fmt.Sprintf("%s-%d", cr.Spec.ServiceMesh.Protocol, cr.Spec.Service.Port)
+		}
+		for i := range cr.Spec.Service.Ports {
+			if cr.Spec.Service.Ports[i].Name == "" {
+				cr.Spec.Service.Ports[i].Name = fmt.Sprintf("%s-%d", cr.Spec.ServiceMesh.Protocol, cr.Spec.Service.Ports[i].Port)
+			}
+		}
+	}
+
 	if cr.Spec.Service.Certificate != nil {
 		if cr.Spec.Service.Certificate.IssuerRef.Name == "" {
 			cr.Spec.Service.Certificate.IssuerRef.Name = common.Config[common.OpConfigPropDefaultIssuer]
@@ -969,8 +1724,11 @@ func (cr *AppsodyApplication) GetLabels() map[string]string {
 		"app.kubernetes.io/part-of":    cr.Spec.ApplicationName,
 	}
 
-	if cr.Spec.Stack != "" {
-		labels["stack.appsody.dev/id"] = cr.Spec.Stack
+	if stack := cr.GetStack(); stack != "" {
+		labels["stack.appsody.dev/id"] = stack
+		if detected := cr.Status.DetectedStack; detected != nil && detected.ID == stack && detected.Version != "" {
+			labels["stack.appsody.dev/version"] = detected.Version
+		}
 	}
 
 	if cr.Spec.Version != "" {
@@ -1115,6 +1873,26 @@ func convertToCommonStatusConditionType(c StatusConditionType) common.StatusCond
 		return common.StatusConditionTypeReconciled
 	case StatusConditionTypeDependenciesSatisfied:
 		return common.StatusConditionTypeDependenciesSatisfied
+	case StatusConditionTypeAutoscalingSupported:
+		return common.StatusConditionType(StatusConditionTypeAutoscalingSupported)
+	case StatusConditionTypeImageVerified:
+		return common.StatusConditionType(StatusConditionTypeImageVerified)
+	case StatusConditionTypeArchitectureSupported:
+		return common.StatusConditionType(StatusConditionTypeArchitectureSupported)
+	case StatusConditionTypeStackMatched:
+		return common.StatusConditionType(StatusConditionTypeStackMatched)
+	case StatusConditionTypeStackCompliant:
+		return common.StatusConditionType(StatusConditionTypeStackCompliant)
+	case StatusConditionTypeIngressTLSReady:
+		return common.StatusConditionType(StatusConditionTypeIngressTLSReady)
+	case StatusConditionTypeEventsReady:
+		return common.StatusConditionType(StatusConditionTypeEventsReady)
+	case StatusConditionTypeNetworkPolicyComplete:
+		return common.StatusConditionType(StatusConditionTypeNetworkPolicyComplete)
+	case StatusConditionTypeGatewayTLSReady:
+		return common.StatusConditionType(StatusConditionTypeGatewayTLSReady)
+	case StatusConditionTypeConfigWatched:
+		return common.StatusConditionType(StatusConditionTypeConfigWatched)
 	default:
 		panic(c)
 	}
@@ -1126,6 +1904,26 @@ func convertFromCommonStatusConditionType(c common.StatusConditionType) StatusCo
 		return StatusConditionTypeReconciled
 	case common.StatusConditionTypeDependenciesSatisfied:
 		return StatusConditionTypeDependenciesSatisfied
+	case common.StatusConditionType(StatusConditionTypeAutoscalingSupported):
+		return StatusConditionTypeAutoscalingSupported
+	case common.StatusConditionType(StatusConditionTypeImageVerified):
+		return StatusConditionTypeImageVerified
+	case common.StatusConditionType(StatusConditionTypeArchitectureSupported):
+		return StatusConditionTypeArchitectureSupported
+	case common.StatusConditionType(StatusConditionTypeStackMatched):
+		return StatusConditionTypeStackMatched
+	case common.StatusConditionType(StatusConditionTypeStackCompliant):
+		return StatusConditionTypeStackCompliant
+	case common.StatusConditionType(StatusConditionTypeIngressTLSReady):
+		return StatusConditionTypeIngressTLSReady
+	case common.StatusConditionType(StatusConditionTypeEventsReady):
+		return StatusConditionTypeEventsReady
+	case common.StatusConditionType(StatusConditionTypeNetworkPolicyComplete):
+		return StatusConditionTypeNetworkPolicyComplete
+	case common.StatusConditionType(StatusConditionTypeGatewayTLSReady):
+		return StatusConditionTypeGatewayTLSReady
+	case common.StatusConditionType(StatusConditionTypeConfigWatched):
+		return StatusConditionTypeConfigWatched
 	default:
 		panic(c)
 	}
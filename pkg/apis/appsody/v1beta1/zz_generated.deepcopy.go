@@ -9,11 +9,57 @@ import (
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	v1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 	routev1 "github.com/openshift/api/route/v1"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyAPIServerEventSource) DeepCopyInto(out *AppsodyAPIServerEventSource) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]AppsodyAPIServerResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyAPIServerEventSource.
+func (in *AppsodyAPIServerEventSource) DeepCopy() *AppsodyAPIServerEventSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyAPIServerEventSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyAPIServerResource) DeepCopyInto(out *AppsodyAPIServerResource) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyAPIServerResource.
+func (in *AppsodyAPIServerResource) DeepCopy() *AppsodyAPIServerResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyAPIServerResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppsodyAffinity) DeepCopyInto(out *AppsodyAffinity) {
 	*out = *in
@@ -98,6 +144,28 @@ func (in *AppsodyApplicationAutoScaling) DeepCopyInto(out *AppsodyApplicationAut
 		*out = new(int32)
 		**out = **in
 	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2beta2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(AppsodyApplicationScalingBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EventDriven != nil {
+		in, out := &in.EventDriven, &out.EventDriven
+		*out = new(AppsodyEventDrivenAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -174,6 +242,79 @@ func (in *AppsodyApplicationMonitoring) DeepCopy() *AppsodyApplicationMonitoring
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyApplicationScalingBehavior) DeepCopyInto(out *AppsodyApplicationScalingBehavior) {
+	*out = *in
+	if in.ScaleUp != nil {
+		in, out := &in.ScaleUp, &out.ScaleUp
+		*out = new(AppsodyApplicationScalingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleDown != nil {
+		in, out := &in.ScaleDown, &out.ScaleDown
+		*out = new(AppsodyApplicationScalingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyApplicationScalingBehavior.
+func (in *AppsodyApplicationScalingBehavior) DeepCopy() *AppsodyApplicationScalingBehavior {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyApplicationScalingBehavior)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyApplicationScalingPolicy) DeepCopyInto(out *AppsodyApplicationScalingPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyApplicationScalingPolicy.
+func (in *AppsodyApplicationScalingPolicy) DeepCopy() *AppsodyApplicationScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyApplicationScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyApplicationScalingRules) DeepCopyInto(out *AppsodyApplicationScalingRules) {
+	*out = *in
+	if in.StabilizationWindowSeconds != nil {
+		in, out := &in.StabilizationWindowSeconds, &out.StabilizationWindowSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SelectPolicy != nil {
+		in, out := &in.SelectPolicy, &out.SelectPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]AppsodyApplicationScalingPolicy, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyApplicationScalingRules.
+func (in *AppsodyApplicationScalingRules) DeepCopy() *AppsodyApplicationScalingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyApplicationScalingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppsodyApplicationService) DeepCopyInto(out *AppsodyApplicationService) {
 	*out = *in
@@ -372,6 +513,63 @@ func (in *AppsodyApplicationSpec) DeepCopyInto(out *AppsodyApplicationSpec) {
 		*out = new(AppsodyAffinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = make([]AppsodyScheduleWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceRecommendation != nil {
+		in, out := &in.ResourceRecommendation, &out.ResourceRecommendation
+		*out = new(AppsodyResourceRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageUpdatePolicy != nil {
+		in, out := &in.ImageUpdatePolicy, &out.ImageUpdatePolicy
+		*out = new(AppsodyImageUpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigRollout != nil {
+		in, out := &in.ConfigRollout, &out.ConfigRollout
+		*out = new(AppsodyConfigRollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DetectArchitecture != nil {
+		in, out := &in.DetectArchitecture, &out.DetectArchitecture
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DetectStack != nil {
+		in, out := &in.DetectStack, &out.DetectStack
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(AppsodyNetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMesh != nil {
+		in, out := &in.ServiceMesh, &out.ServiceMesh
+		*out = new(AppsodyServiceMesh)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Knative != nil {
+		in, out := &in.Knative, &out.Knative
+		*out = new(AppsodyKnative)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = new(AppsodyEvents)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -415,6 +613,53 @@ func (in *AppsodyApplicationStatus) DeepCopyInto(out *AppsodyApplicationStatus)
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]StatusSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceRecommendation != nil {
+		in, out := &in.ResourceRecommendation, &out.ResourceRecommendation
+		*out = new(StatusResourceRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageUpdate != nil {
+		in, out := &in.ImageUpdate, &out.ImageUpdate
+		*out = new(StatusImageUpdate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RewrittenImages != nil {
+		in, out := &in.RewrittenImages, &out.RewrittenImages
+		*out = make([]StatusRewrittenImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageArchitectures != nil {
+		in, out := &in.ImageArchitectures, &out.ImageArchitectures
+		*out = new(StatusImageArchitectures)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DetectedStack != nil {
+		in, out := &in.DetectedStack, &out.DetectedStack
+		*out = new(StatusDetectedStack)
+		**out = **in
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(StatusIngress)
+		**out = **in
+	}
+	if in.Knative != nil {
+		in, out := &in.Knative, &out.Knative
+		*out = new(StatusKnative)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ModeTransition != nil {
+		in, out := &in.ModeTransition, &out.ModeTransition
+		*out = new(StatusModeTransition)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -436,6 +681,11 @@ func (in *AppsodyApplicationStorage) DeepCopyInto(out *AppsodyApplicationStorage
 		*out = new(v1.PersistentVolumeClaim)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(AppsodyStorageBackup)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -476,172 +726,942 @@ func (in *AppsodyBindings) DeepCopy() *AppsodyBindings {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AppsodyRoute) DeepCopyInto(out *AppsodyRoute) {
+func (in *AppsodyCircuitBreaker) DeepCopyInto(out *AppsodyCircuitBreaker) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Termination != nil {
-		in, out := &in.Termination, &out.Termination
-		*out = new(routev1.TLSTerminationType)
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int32)
 		**out = **in
 	}
-	if in.InsecureEdgeTerminationPolicy != nil {
-		in, out := &in.InsecureEdgeTerminationPolicy, &out.InsecureEdgeTerminationPolicy
-		*out = new(routev1.InsecureEdgeTerminationPolicyType)
+	if in.MaxPendingRequests != nil {
+		in, out := &in.MaxPendingRequests, &out.MaxPendingRequests
+		*out = new(int32)
 		**out = **in
 	}
-	if in.Certificate != nil {
-		in, out := &in.Certificate, &out.Certificate
-		*out = new(Certificate)
-		(*in).DeepCopyInto(*out)
+	if in.ConsecutiveErrors != nil {
+		in, out := &in.ConsecutiveErrors, &out.ConsecutiveErrors
+		*out = new(int32)
+		**out = **in
 	}
-	if in.CertificateSecretRef != nil {
-		in, out := &in.CertificateSecretRef, &out.CertificateSecretRef
-		*out = new(string)
+	if in.MaxEjectionPercent != nil {
+		in, out := &in.MaxEjectionPercent, &out.MaxEjectionPercent
+		*out = new(int32)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyRoute.
-func (in *AppsodyRoute) DeepCopy() *AppsodyRoute {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyCircuitBreaker.
+func (in *AppsodyCircuitBreaker) DeepCopy() *AppsodyCircuitBreaker {
 	if in == nil {
 		return nil
 	}
-	out := new(AppsodyRoute)
+	out := new(AppsodyCircuitBreaker)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Certificate) DeepCopyInto(out *Certificate) {
+func (in *AppsodyConfigRollout) DeepCopyInto(out *AppsodyConfigRollout) {
 	*out = *in
-	if in.Organization != nil {
-		in, out := &in.Organization, &out.Organization
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Duration != nil {
-		in, out := &in.Duration, &out.Duration
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.RenewBefore != nil {
-		in, out := &in.RenewBefore, &out.RenewBefore
-		*out = new(metav1.Duration)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
 		**out = **in
 	}
-	if in.DNSNames != nil {
-		in, out := &in.DNSNames, &out.DNSNames
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.IPAddresses != nil {
-		in, out := &in.IPAddresses, &out.IPAddresses
+	if in.ExcludeConfigMaps != nil {
+		in, out := &in.ExcludeConfigMaps, &out.ExcludeConfigMaps
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.URISANs != nil {
-		in, out := &in.URISANs, &out.URISANs
+	if in.ExcludeSecrets != nil {
+		in, out := &in.ExcludeSecrets, &out.ExcludeSecrets
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	out.IssuerRef = in.IssuerRef
-	if in.Usages != nil {
-		in, out := &in.Usages, &out.Usages
-		*out = make([]v1alpha2.KeyUsage, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Certificate.
-func (in *Certificate) DeepCopy() *Certificate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyConfigRollout.
+func (in *AppsodyConfigRollout) DeepCopy() *AppsodyConfigRollout {
 	if in == nil {
 		return nil
 	}
-	out := new(Certificate)
+	out := new(AppsodyConfigRollout)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceBindingAuth) DeepCopyInto(out *ServiceBindingAuth) {
+func (in *AppsodyEventDrivenAutoscaling) DeepCopyInto(out *AppsodyEventDrivenAutoscaling) {
 	*out = *in
-	in.Username.DeepCopyInto(&out.Username)
-	in.Password.DeepCopyInto(&out.Password)
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]AppsodyScaleTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(AppsodyTriggerAuthentication)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBindingAuth.
-func (in *ServiceBindingAuth) DeepCopy() *ServiceBindingAuth {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyEventDrivenAutoscaling.
+func (in *AppsodyEventDrivenAutoscaling) DeepCopy() *AppsodyEventDrivenAutoscaling {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceBindingAuth)
+	out := new(AppsodyEventDrivenAutoscaling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceBindingConsumes) DeepCopyInto(out *ServiceBindingConsumes) {
+func (in *AppsodyEventSource) DeepCopyInto(out *AppsodyEventSource) {
 	*out = *in
+	if in.Ping != nil {
+		in, out := &in.Ping, &out.Ping
+		*out = new(AppsodyPingSource)
+		**out = **in
+	}
+	if in.APIServer != nil {
+		in, out := &in.APIServer, &out.APIServer
+		*out = new(AppsodyAPIServerEventSource)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBindingConsumes.
-func (in *ServiceBindingConsumes) DeepCopy() *ServiceBindingConsumes {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyEventSource.
+func (in *AppsodyEventSource) DeepCopy() *AppsodyEventSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceBindingConsumes)
+	out := new(AppsodyEventSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceBindingProvides) DeepCopyInto(out *ServiceBindingProvides) {
+func (in *AppsodyEventTrigger) DeepCopyInto(out *AppsodyEventTrigger) {
 	*out = *in
-	if in.Auth != nil {
-		in, out := &in.Auth, &out.Auth
-		*out = new(ServiceBindingAuth)
-		(*in).DeepCopyInto(*out)
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBindingProvides.
-func (in *ServiceBindingProvides) DeepCopy() *ServiceBindingProvides {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyEventTrigger.
+func (in *AppsodyEventTrigger) DeepCopy() *AppsodyEventTrigger {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceBindingProvides)
+	out := new(AppsodyEventTrigger)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatusCondition) DeepCopyInto(out *StatusCondition) {
+func (in *AppsodyEvents) DeepCopyInto(out *AppsodyEvents) {
 	*out = *in
-	if in.LastTransitionTime != nil {
-		in, out := &in.LastTransitionTime, &out.LastTransitionTime
-		*out = (*in).DeepCopy()
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]AppsodyEventTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]AppsodyEventSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCondition.
-func (in *StatusCondition) DeepCopy() *StatusCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyEvents.
+func (in *AppsodyEvents) DeepCopy() *AppsodyEvents {
 	if in == nil {
 		return nil
 	}
-	out := new(StatusCondition)
+	out := new(AppsodyEvents)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyGatewayReference) DeepCopyInto(out *AppsodyGatewayReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyGatewayReference.
+func (in *AppsodyGatewayReference) DeepCopy() *AppsodyGatewayReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyGatewayReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyHTTPHeaderMatch) DeepCopyInto(out *AppsodyHTTPHeaderMatch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyHTTPHeaderMatch.
+func (in *AppsodyHTTPHeaderMatch) DeepCopy() *AppsodyHTTPHeaderMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyHTTPHeaderMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyImageUpdatePolicy) DeepCopyInto(out *AppsodyImageUpdatePolicy) {
+	*out = *in
+	if in.PollIntervalSeconds != nil {
+		in, out := &in.PollIntervalSeconds, &out.PollIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyImageUpdatePolicy.
+func (in *AppsodyImageUpdatePolicy) DeepCopy() *AppsodyImageUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyImageUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyKnative) DeepCopyInto(out *AppsodyKnative) {
+	*out = *in
+	if in.ContainerConcurrency != nil {
+		in, out := &in.ContainerConcurrency, &out.ContainerConcurrency
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinScale != nil {
+		in, out := &in.MinScale, &out.MinScale
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxScale != nil {
+		in, out := &in.MaxScale, &out.MaxScale
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetUtilizationPercentage != nil {
+		in, out := &in.TargetUtilizationPercentage, &out.TargetUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Traffic != nil {
+		in, out := &in.Traffic, &out.Traffic
+		*out = make([]AppsodyKnativeTraffic, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyKnative.
+func (in *AppsodyKnative) DeepCopy() *AppsodyKnative {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyKnative)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyKnativeTraffic) DeepCopyInto(out *AppsodyKnativeTraffic) {
+	*out = *in
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyKnativeTraffic.
+func (in *AppsodyKnativeTraffic) DeepCopy() *AppsodyKnativeTraffic {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyKnativeTraffic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyNetworkPolicy) DeepCopyInto(out *AppsodyNetworkPolicy) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = new(AppsodyNetworkPolicyEgress)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyNetworkPolicy.
+func (in *AppsodyNetworkPolicy) DeepCopy() *AppsodyNetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyNetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyNetworkPolicyEgress) DeepCopyInto(out *AppsodyNetworkPolicyEgress) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyNetworkPolicyEgress.
+func (in *AppsodyNetworkPolicyEgress) DeepCopy() *AppsodyNetworkPolicyEgress {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyNetworkPolicyEgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyPingSource) DeepCopyInto(out *AppsodyPingSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyPingSource.
+func (in *AppsodyPingSource) DeepCopy() *AppsodyPingSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyPingSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyResourceRecommendation) DeepCopyInto(out *AppsodyResourceRecommendation) {
+	*out = *in
+	if in.TolerancePercentage != nil {
+		in, out := &in.TolerancePercentage, &out.TolerancePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyResourceRecommendation.
+func (in *AppsodyResourceRecommendation) DeepCopy() *AppsodyResourceRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyResourceRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyRoute) DeepCopyInto(out *AppsodyRoute) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Termination != nil {
+		in, out := &in.Termination, &out.Termination
+		*out = new(routev1.TLSTerminationType)
+		**out = **in
+	}
+	if in.InsecureEdgeTerminationPolicy != nil {
+		in, out := &in.InsecureEdgeTerminationPolicy, &out.InsecureEdgeTerminationPolicy
+		*out = new(routev1.InsecureEdgeTerminationPolicyType)
+		**out = **in
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(Certificate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateSecretRef != nil {
+		in, out := &in.CertificateSecretRef, &out.CertificateSecretRef
+		*out = new(string)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]AppsodyHTTPHeaderMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(AppsodyGatewayReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyRoute.
+func (in *AppsodyRoute) DeepCopy() *AppsodyRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyScaleTrigger) DeepCopyInto(out *AppsodyScaleTrigger) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyScaleTrigger.
+func (in *AppsodyScaleTrigger) DeepCopy() *AppsodyScaleTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyScaleTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyScheduleWindow) DeepCopyInto(out *AppsodyScheduleWindow) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyScheduleWindow.
+func (in *AppsodyScheduleWindow) DeepCopy() *AppsodyScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyServiceMesh) DeepCopyInto(out *AppsodyServiceMesh) {
+	*out = *in
+	if in.SidecarInjection != nil {
+		in, out := &in.SidecarInjection, &out.SidecarInjection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(AppsodyServiceMeshRetries)
+		**out = **in
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(AppsodyCircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyServiceMesh.
+func (in *AppsodyServiceMesh) DeepCopy() *AppsodyServiceMesh {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyServiceMesh)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyServiceMeshRetries) DeepCopyInto(out *AppsodyServiceMeshRetries) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyServiceMeshRetries.
+func (in *AppsodyServiceMeshRetries) DeepCopy() *AppsodyServiceMeshRetries {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyServiceMeshRetries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyStorageBackup) DeepCopyInto(out *AppsodyStorageBackup) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.BeforeRollout != nil {
+		in, out := &in.BeforeRollout, &out.BeforeRollout
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyStorageBackup.
+func (in *AppsodyStorageBackup) DeepCopy() *AppsodyStorageBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyStorageBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppsodyTriggerAuthentication) DeepCopyInto(out *AppsodyTriggerAuthentication) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppsodyTriggerAuthentication.
+func (in *AppsodyTriggerAuthentication) DeepCopy() *AppsodyTriggerAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(AppsodyTriggerAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Certificate) DeepCopyInto(out *Certificate) {
+	*out = *in
+	if in.Organization != nil {
+		in, out := &in.Organization, &out.Organization
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.URISANs != nil {
+		in, out := &in.URISANs, &out.URISANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.IssuerRef = in.IssuerRef
+	if in.Usages != nil {
+		in, out := &in.Usages, &out.Usages
+		*out = make([]v1alpha2.KeyUsage, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Certificate.
+func (in *Certificate) DeepCopy() *Certificate {
+	if in == nil {
+		return nil
+	}
+	out := new(Certificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceBindingAuth) DeepCopyInto(out *ServiceBindingAuth) {
+	*out = *in
+	in.Username.DeepCopyInto(&out.Username)
+	in.Password.DeepCopyInto(&out.Password)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBindingAuth.
+func (in *ServiceBindingAuth) DeepCopy() *ServiceBindingAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceBindingConsumes) DeepCopyInto(out *ServiceBindingConsumes) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBindingConsumes.
+func (in *ServiceBindingConsumes) DeepCopy() *ServiceBindingConsumes {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingConsumes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceBindingProvides) DeepCopyInto(out *ServiceBindingProvides) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(ServiceBindingAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBindingProvides.
+func (in *ServiceBindingProvides) DeepCopy() *ServiceBindingProvides {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingProvides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCondition) DeepCopyInto(out *StatusCondition) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCondition.
+func (in *StatusCondition) DeepCopy() *StatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusDetectedStack) DeepCopyInto(out *StatusDetectedStack) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusDetectedStack.
+func (in *StatusDetectedStack) DeepCopy() *StatusDetectedStack {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusDetectedStack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusImageArchitectures) DeepCopyInto(out *StatusImageArchitectures) {
+	*out = *in
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusImageArchitectures.
+func (in *StatusImageArchitectures) DeepCopy() *StatusImageArchitectures {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusImageArchitectures)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusImageUpdate) DeepCopyInto(out *StatusImageUpdate) {
+	*out = *in
+	if in.LastPollTime != nil {
+		in, out := &in.LastPollTime, &out.LastPollTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusImageUpdate.
+func (in *StatusImageUpdate) DeepCopy() *StatusImageUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusImageUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusIngress) DeepCopyInto(out *StatusIngress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusIngress.
+func (in *StatusIngress) DeepCopy() *StatusIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusKnative) DeepCopyInto(out *StatusKnative) {
+	*out = *in
+	if in.Traffic != nil {
+		in, out := &in.Traffic, &out.Traffic
+		*out = make([]StatusKnativeTraffic, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusKnative.
+func (in *StatusKnative) DeepCopy() *StatusKnative {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusKnative)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusKnativeTraffic) DeepCopyInto(out *StatusKnativeTraffic) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusKnativeTraffic.
+func (in *StatusKnativeTraffic) DeepCopy() *StatusKnativeTraffic {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusKnativeTraffic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusModeTransition) DeepCopyInto(out *StatusModeTransition) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AbandonedClaims != nil {
+		in, out := &in.AbandonedClaims, &out.AbandonedClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusModeTransition.
+func (in *StatusModeTransition) DeepCopy() *StatusModeTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusModeTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusResourceRecommendation) DeepCopyInto(out *StatusResourceRecommendation) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusResourceRecommendation.
+func (in *StatusResourceRecommendation) DeepCopy() *StatusResourceRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusResourceRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusRewrittenImage) DeepCopyInto(out *StatusRewrittenImage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusRewrittenImage.
+func (in *StatusRewrittenImage) DeepCopy() *StatusRewrittenImage {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusRewrittenImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusSnapshot) DeepCopyInto(out *StatusSnapshot) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusSnapshot.
+func (in *StatusSnapshot) DeepCopy() *StatusSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusSnapshot)
 	in.DeepCopyInto(out)
 	return out
 }
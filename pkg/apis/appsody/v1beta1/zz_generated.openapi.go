@@ -155,9 +155,44 @@ func schema_pkg_apis_appsody_v1beta1_AppsodyApplicationAutoScaling(ref common.Re
 							Format: "int32",
 						},
 					},
+					"targetMemoryUtilizationPercentage": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"metrics": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/autoscaling/v2beta2.MetricSpec"),
+									},
+								},
+							},
+						},
+					},
+					"behavior": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationScalingBehavior"),
+						},
+					},
+					"eventDriven": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyEventDrivenAutoscaling"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationScalingBehavior", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyEventDrivenAutoscaling", "k8s.io/api/autoscaling/v2beta2.MetricSpec"},
 	}
 }
 
@@ -198,6 +233,13 @@ func schema_pkg_apis_appsody_v1beta1_AppsodyApplicationService(ref common.Refere
 							Format: "",
 						},
 					},
+					"protocol": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Protocol of the application port. Defaults to http.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"ports": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
@@ -512,12 +554,89 @@ func schema_pkg_apis_appsody_v1beta1_AppsodyApplicationSpec(ref common.Reference
 							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyAffinity"),
 						},
 					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"schedule": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": "name",
+								"x-kubernetes-list-type":     "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyScheduleWindow"),
+									},
+								},
+							},
+						},
+					},
+					"resourceRecommendation": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyResourceRecommendation"),
+						},
+					},
+					"imagePinning": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"imageUpdatePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyImageUpdatePolicy"),
+						},
+					},
+					"configRollout": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyConfigRollout"),
+						},
+					},
+					"detectArchitecture": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"detectStack": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"networkPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyNetworkPolicy"),
+						},
+					},
+					"serviceMesh": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyServiceMesh"),
+						},
+					},
+					"knative": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyKnative"),
+						},
+					},
+					"events": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyEvents"),
+						},
+					},
 				},
 				Required: []string{"applicationImage"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyAffinity", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationAutoScaling", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationMonitoring", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationService", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationStorage", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyBindings", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyRoute", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvFromSource", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Probe", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyAffinity", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationAutoScaling", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationMonitoring", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationService", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyApplicationStorage", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyBindings", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyConfigRollout", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyEvents", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyImageUpdatePolicy", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyKnative", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyNetworkPolicy", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyResourceRecommendation", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyRoute", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyScheduleWindow", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyServiceMesh", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvFromSource", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Probe", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
 	}
 }
 
@@ -590,11 +709,111 @@ func schema_pkg_apis_appsody_v1beta1_AppsodyApplicationStatus(ref common.Referen
 							Format: "",
 						},
 					},
+					"snapshots": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusSnapshot"),
+									},
+								},
+							},
+						},
+					},
+					"restoredFrom": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"suspended": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"scheduleWindow": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"resourceRecommendation": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusResourceRecommendation"),
+						},
+					},
+					"pinnedImage": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"imageUpdate": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusImageUpdate"),
+						},
+					},
+					"rewrittenImages": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": "container",
+								"x-kubernetes-list-type":     "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusRewrittenImage"),
+									},
+								},
+							},
+						},
+					},
+					"imageArchitectures": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusImageArchitectures"),
+						},
+					},
+					"detectedStack": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusDetectedStack"),
+						},
+					},
+					"ingress": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusIngress"),
+						},
+					},
+					"knative": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusKnative"),
+						},
+					},
+					"modeTransition": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusModeTransition"),
+						},
+					},
+					"verifiedImagePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusCondition"},
+			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusCondition", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusDetectedStack", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusImageArchitectures", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusImageUpdate", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusIngress", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusKnative", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusModeTransition", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusResourceRecommendation", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusRewrittenImage", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.StatusSnapshot"},
 	}
 }
 
@@ -654,11 +873,39 @@ func schema_pkg_apis_appsody_v1beta1_AppsodyRoute(ref common.ReferenceCallback)
 							Format: "",
 						},
 					},
+					"headers": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyHTTPHeaderMatch"),
+									},
+								},
+							},
+						},
+					},
+					"gateway": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyGatewayReference"),
+						},
+					},
+					"ingressClassName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.Certificate"},
+			"github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyGatewayReference", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.AppsodyHTTPHeaderMatch", "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1.Certificate"},
 	}
 }
 
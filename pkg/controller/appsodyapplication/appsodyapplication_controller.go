@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/application-stacks/runtime-component-operator/pkg/common"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
-	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 
 	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
 	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
 	prometheusv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
@@ -22,10 +23,13 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	applicationsv1beta1 "sigs.k8s.io/application/pkg/apis/app/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -144,6 +148,14 @@ func setup(mgr manager.Manager) {
 		}
 		return nil
 	})
+	mgr.GetFieldIndexer().IndexField(&appsodyv1beta1.AppsodyApplication{}, indexFieldConfigMaps, func(obj runtime.Object) []string {
+		configMaps, _ := appsodyutils.GetConfigRolloutReferences(obj.(*appsodyv1beta1.AppsodyApplication))
+		return configMaps
+	})
+	mgr.GetFieldIndexer().IndexField(&appsodyv1beta1.AppsodyApplication{}, indexFieldSecrets, func(obj runtime.Object) []string {
+		_, secrets := appsodyutils.GetConfigRolloutReferences(obj.(*appsodyv1beta1.AppsodyApplication))
+		return secrets
+	})
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -195,6 +207,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the services consumed by AppsodyApplications, to update the NetworkPolicies of the providers
+	err = c.Watch(&source.Kind{Type: &appsodyv1beta1.AppsodyApplication{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: consumedServicesMapper}, pred)
+	if err != nil {
+		return err
+	}
+
 	predSubResource := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			// Ignore updates to CR status in which case metadata.Generation does not change
@@ -288,6 +306,54 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// The ConfigMaps and Secrets labelled for config rollout are watched to roll out the pods, the other ones are polled
+	configInformers := newConfigInformers(kubernetes.NewForConfigOrDie(mgr.GetConfig()), watchNamespaces)
+	reconciler.configReader = &informerConfigReader{informers: configInformers, apiReader: mgr.GetAPIReader()}
+	for _, factory := range configInformers {
+		err = c.Watch(
+			&source.Informer{Informer: factory.Core().V1().ConfigMaps().Informer()},
+			&EnqueueRequestsForCustomIndexField{
+				Matcher: &ConfigMatcher{
+					klient:     mgr.GetClient(),
+					indexField: indexFieldConfigMaps,
+				},
+			})
+		if err != nil {
+			return err
+		}
+
+		err = c.Watch(
+			&source.Informer{Informer: factory.Core().V1().Secrets().Informer()},
+			&EnqueueRequestsForCustomIndexField{
+				Matcher: &ConfigMatcher{
+					klient:     mgr.GetClient(),
+					indexField: indexFieldSecrets,
+				},
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		for _, factory := range configInformers {
+			factory.Start(stop)
+		}
+		<-stop
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &networkingv1.NetworkPolicy{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+	}, predSubResource)
+	if err != nil {
+		return err
+	}
+
 	ok, _ := reconciler.IsGroupVersionSupported(imagev1.SchemeGroupVersion.String(), "ImageStream")
 	if ok {
 		c.Watch(
@@ -308,12 +374,19 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		}, predSubResource)
 	}
 
-	ok, _ = reconciler.IsGroupVersionSupported(servingv1alpha1.SchemeGroupVersion.String(), "Service")
-	if ok {
+	if apiVersion := reconciler.getKnativeAPIVersion(); apiVersion == servingv1alpha1.SchemeGroupVersion.String() {
 		c.Watch(&source.Kind{Type: &servingv1alpha1.Service{}}, &handler.EnqueueRequestForOwner{
 			IsController: true,
 			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
 		}, predSubResource)
+	} else if apiVersion != "" {
+		ksvc := &unstructured.Unstructured{}
+		ksvc.SetAPIVersion(apiVersion)
+		ksvc.SetKind("Service")
+		c.Watch(&source.Kind{Type: ksvc}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+		}, predSubResource)
 	}
 
 	ok, _ = reconciler.IsGroupVersionSupported(certmngrv1alpha2.SchemeGroupVersion.String(), "Certificate")
@@ -331,6 +404,72 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
 		}, predSubResource)
 	}
+
+	ok, _ = reconciler.IsGroupVersionSupported(appsodyutils.KedaAPIVersion, "ScaledObject")
+	if ok {
+		scaledObject := &unstructured.Unstructured{}
+		scaledObject.SetAPIVersion(appsodyutils.KedaAPIVersion)
+		scaledObject.SetKind("ScaledObject")
+		c.Watch(&source.Kind{Type: scaledObject}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+		}, predSubResource)
+	}
+
+	ok, _ = reconciler.IsGroupVersionSupported(appsodyutils.VerticalPodAutoscalerAPIVersion, "VerticalPodAutoscaler")
+	if ok {
+		vpa := &unstructured.Unstructured{}
+		vpa.SetAPIVersion(appsodyutils.VerticalPodAutoscalerAPIVersion)
+		vpa.SetKind("VerticalPodAutoscaler")
+		c.Watch(&source.Kind{Type: vpa}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+		}, predSubResource)
+	}
+
+	for _, k := range eventKinds {
+		if apiVersion := reconciler.getEventAPIVersion(k.kind); apiVersion != "" {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(apiVersion)
+			obj.SetKind(k.kind)
+			c.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+			}, predSubResource)
+		}
+	}
+
+	for _, kind := range []string{"VirtualService", "DestinationRule"} {
+		if apiVersion := reconciler.getIstioAPIVersion(kind); apiVersion != "" {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(apiVersion)
+			obj.SetKind(kind)
+			c.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+			}, predSubResource)
+		}
+	}
+
+	if apiVersion := reconciler.getHTTPRouteAPIVersion(); apiVersion != "" {
+		route := &unstructured.Unstructured{}
+		route.SetAPIVersion(apiVersion)
+		route.SetKind("HTTPRoute")
+		c.Watch(&source.Kind{Type: route}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+		}, predSubResource)
+	}
+
+	if apiVersion := reconciler.getVolumeSnapshotAPIVersion(); apiVersion != "" {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetAPIVersion(apiVersion)
+		snapshot.SetKind("VolumeSnapshot")
+		c.Watch(&source.Kind{Type: snapshot}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &appsodyv1beta1.AppsodyApplication{},
+		}, predSubResource)
+	}
 	return nil
 }
 
@@ -342,10 +481,15 @@ type ReconcileAppsodyApplication struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	oputils.ReconcilerBase
-	StackDefaults   map[string]appsodyv1beta1.AppsodyApplicationSpec
-	StackConstants  map[string]*appsodyv1beta1.AppsodyApplicationSpec
-	lastDefautsRV   string
-	lastConstantsRV string
+	StackDefaults     map[string]appsodyv1beta1.AppsodyApplicationSpec
+	StackConstants    map[string]*appsodyv1beta1.AppsodyApplicationSpec
+	ImagePolicies     map[string]*appsodyutils.ImagePolicy
+	StackPolicies     map[string]*appsodyutils.StackPolicy
+	lastDefautsRV     string
+	lastConstantsRV   string
+	lastImagePolicyRV string
+	lastStackPolicyRV string
+	configReader      configReader
 }
 
 // Reconcile reads that state of the cluster for a AppsodyApplication object and makes changes based on the state read
@@ -415,6 +559,42 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 		r.lastConstantsRV = configMap.ResourceVersion
 	}
 
+	configMap, err = r.GetOpConfigMap("appsody-operator-image-policy", ns)
+	if err != nil {
+		// Images are not verified when there is no image policy
+		r.ImagePolicies = nil
+		r.lastImagePolicyRV = ""
+	} else if r.ImagePolicies == nil || r.lastImagePolicyRV != configMap.ResourceVersion {
+		r.ImagePolicies = map[string]*appsodyutils.ImagePolicy{}
+		for name, values := range configMap.Data {
+			// An invalid policy is kept so that the images in its scope fail verification
+			policy, perr := appsodyutils.ParseImagePolicy(name, values)
+			if perr != nil {
+				reqLogger.Error(perr, "Failed to parse image policy "+name)
+			}
+			r.ImagePolicies[name] = policy
+		}
+		r.lastImagePolicyRV = configMap.ResourceVersion
+	}
+
+	configMap, err = r.GetOpConfigMap("appsody-operator-stack-policy", ns)
+	if err != nil {
+		// All stacks are allowed when there is no stack policy
+		r.StackPolicies = nil
+		r.lastStackPolicyRV = ""
+	} else if r.StackPolicies == nil || r.lastStackPolicyRV != configMap.ResourceVersion {
+		r.StackPolicies = map[string]*appsodyutils.StackPolicy{}
+		for name, values := range configMap.Data {
+			policy, perr := appsodyutils.ParseStackPolicy(values)
+			if perr != nil {
+				reqLogger.Error(perr, "Failed to parse stack policy "+name)
+			} else {
+				r.StackPolicies[name] = policy
+			}
+		}
+		r.lastStackPolicyRV = configMap.ResourceVersion
+	}
+
 	configMap, err = r.GetOpConfigMap("appsody-operator", ns)
 	if err != nil {
 		log.Info("Failed to find appsody-operator config map")
@@ -424,6 +604,23 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 	} else {
 		common.Config.LoadFromConfigMap(configMap)
 	}
+	if _, ok := common.Config[appsodyutils.OpConfigImageRewriteRules]; !ok {
+		common.Config[appsodyutils.OpConfigImageRewriteRules] = ""
+	}
+	if _, ok := common.Config[appsodyutils.OpConfigDefaultGateway]; !ok {
+		common.Config[appsodyutils.OpConfigDefaultGateway] = ""
+	}
+	if _, ok := common.Config[appsodyutils.OpConfigDefaultIngressClass]; !ok {
+		common.Config[appsodyutils.OpConfigDefaultIngressClass] = ""
+	}
+	if _, ok := common.Config[appsodyutils.OpConfigCertificateAuthority]; !ok {
+		common.Config[appsodyutils.OpConfigCertificateAuthority] = ""
+	}
+	for _, key := range []string{appsodyutils.OpConfigNetworkPolicyIngressNamespaceSelector, appsodyutils.OpConfigNetworkPolicyMonitoringNamespaceSelector} {
+		if _, ok := common.Config[key]; !ok {
+			common.Config[key] = ""
+		}
+	}
 
 	_, err = controllerutil.CreateOrUpdate(context.TODO(), r.GetClient(), configMap, func() error {
 		configMap.Data = common.Config
@@ -449,28 +646,34 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
-	stackDefaults, ok := r.StackDefaults[instance.Spec.Stack]
+	err = r.reconcileDetectedStack(instance)
+	if err != nil {
+		reqLogger.Error(err, "Error updating the detected stack")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+	stack := instance.GetStack()
+	stackDefaults, ok := r.StackDefaults[stack]
 	if ok {
-		_, ok = r.StackConstants[instance.Spec.Stack]
+		_, ok = r.StackConstants[stack]
 		if ok {
-			instance.Initialize(stackDefaults, r.StackConstants[instance.Spec.Stack])
+			instance.Initialize(stackDefaults, r.StackConstants[stack])
 		} else {
 			instance.Initialize(stackDefaults, r.StackConstants["generic"])
 		}
 	} else {
 		stackDefaults, ok = r.StackDefaults["generic"]
 		if !ok {
-			err = fmt.Errorf("Failed to find stack neither `%v` nor `generic` in the ConfigMap holding default values", instance.Spec.Stack)
+			err = fmt.Errorf("Failed to find stack neither `%v` nor `generic` in the ConfigMap holding default values", stack)
 			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
-		_, ok = r.StackConstants[instance.Spec.Stack]
+		_, ok = r.StackConstants[stack]
 		if ok {
-			instance.Initialize(stackDefaults, r.StackConstants[instance.Spec.Stack])
+			instance.Initialize(stackDefaults, r.StackConstants[stack])
 		} else {
 			instance.Initialize(stackDefaults, r.StackConstants["generic"])
 		}
 	}
-	_, err = oputils.Validate(instance)
+	err = appsodyutils.Validate(instance)
 	// If there's any validation error, don't bother with requeuing
 	if err != nil {
 		reqLogger.Error(err, "Error validating AppsodyApplication")
@@ -511,6 +714,19 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 		Namespace: instance.Namespace,
 	}
 
+	rewriteRules, err := appsodyutils.GetImageRewriteRules()
+	if err != nil {
+		reqLogger.Error(err, "Failed to parse the image rewrite rules")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	now := time.Now()
+	stackRequeueAfter, err := r.reconcileStackCompliance(instance, now)
+	if err != nil {
+		reqLogger.Error(err, "Stack is not compliant with the stack policies")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
 	imageReferenceOld := instance.Status.ImageReference
 	instance.Status.ImageReference = instance.Spec.ApplicationImage
 	if r.IsOpenShift() {
@@ -532,6 +748,23 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 			}
 		}
 	}
+	pinnedImageOld := instance.Status.PinnedImage
+	imageUpdateRequeueAfter := r.reconcileImageUpdate(instance, now, rewriteRules)
+	rewriteImages(instance, rewriteRules)
+	imagePolicies := r.getImagePolicies(instance)
+	err = r.pinImageDigest(instance, imageReferenceOld, len(imagePolicies) > 0)
+	if err != nil {
+		reqLogger.Error(err, "Failed to resolve the digest of the application image")
+	} else if err = r.verifyImageSignature(instance, imagePolicies, imageReferenceOld); err != nil {
+		reqLogger.Error(err, "Failed to verify the signature of the application image")
+	}
+	if err != nil {
+		// Keep running the previous image
+		instance.Status.ImageReference = imageReferenceOld
+		instance.Status.PinnedImage = pinnedImageOld
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+	r.reconcileImageArchitectures(instance)
 	if imageReferenceOld != instance.Status.ImageReference {
 		reqLogger.Info("Updating status.imageReference", "status.imageReference", instance.Status.ImageReference)
 		err = r.UpdateStatus(instance)
@@ -554,6 +787,11 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 	if err != nil || result != (reconcile.Result{}) {
 		return result, nil
 	}
+	certificateRequeueAfter, err := r.reconcileOperatorCertificates(instance, ns, now)
+	if err != nil {
+		reqLogger.Error(err, "Failed to issue certificates with the CA of the operator")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
 
 	if r.IsServiceBindingSupported() {
 		result, err = r.ReconcileBindings(instance)
@@ -567,6 +805,11 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 	if err != nil {
 		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 	}
+	configHash, configRequeueAfter, err := r.getConfigHash(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the ConfigMaps and Secrets referenced by the application")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
 
 	if instance.Spec.ServiceAccountName == nil || *instance.Spec.ServiceAccountName == "" {
 		serviceAccount := &corev1.ServiceAccount{ObjectMeta: defaultMeta}
@@ -587,30 +830,81 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 		}
 	}
 
-	isKnativeSupported, err := r.IsGroupVersionSupported(servingv1alpha1.SchemeGroupVersion.String(), "Service")
+	knativeAPIVersion := r.getKnativeAPIVersion()
+	if knativeAPIVersion == "" {
+		reqLogger.V(1).Info(fmt.Sprintf("%s is not supported on the cluster", appsodyutils.KnativeServingGroup))
+	}
+
+	scaling, err := getScaling(instance, now)
 	if err != nil {
-		r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-	} else if !isKnativeSupported {
-		reqLogger.V(1).Info(fmt.Sprintf("%s is not supported on the cluster", servingv1alpha1.SchemeGroupVersion.String()))
+		reqLogger.Error(err, "Failed to evaluate schedule")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 	}
 
 	if instance.Spec.CreateKnativeService != nil && *instance.Spec.CreateKnativeService {
-		// Clean up non-Knative resources
-		resources := []runtime.Object{
-			&corev1.Service{ObjectMeta: defaultMeta},
-			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: instance.Name + "-headless", Namespace: instance.Namespace}},
-			&appsv1.Deployment{ObjectMeta: defaultMeta},
-			&appsv1.StatefulSet{ObjectMeta: defaultMeta},
-			&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: defaultMeta},
+		if knativeAPIVersion == "" {
+			return r.ManageError(errors.New("failed to reconcile Knative service as operator could not find Knative CRDs"), common.StatusConditionTypeReconciled, instance)
 		}
-		err = r.DeleteResources(resources)
+		knative, err := scaling.knative(instance.Spec.Knative)
 		if err != nil {
-			reqLogger.Error(err, "Failed to clean up non-Knative resources")
+			reqLogger.Error(err, "Failed to apply the scaling of the application to Knative Service")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		r.setScheduleStatus(instance, scaling)
+
+		err = r.reconcileKnativeService(instance, knativeAPIVersion, func(ksvc *servingv1alpha1.Service) {
+			oputils.CustomizeKnativeService(ksvc, instance)
+			rewritePodSpecImages(&ksvc.Spec.Template.Spec.PodSpec, rewriteRules)
+			oputils.CustomizeServiceBinding(resolvedBindingSecret, &ksvc.Spec.Template.Spec.PodSpec, instance)
+			setConfigHashAnnotation(&ksvc.Spec.Template.ObjectMeta, configHash)
+			appsodyutils.CustomizeKnativeAutoscaling(ksvc, knative)
+			appsodyutils.CustomizeKnativeTraffic(ksvc, instance)
+		})
+		if err != nil {
+			reqLogger.Error(err, "Failed to reconcile Knative Service")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+
+		revisionReady, ready, err := r.getKnativeServiceReadiness(instance, knativeAPIVersion)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the readiness of Knative Service")
 			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
 
-		if ok, _ := r.IsGroupVersionSupported(networkingv1beta1.SchemeGroupVersion.String(), "Ingress"); ok {
-			r.DeleteResource(&networkingv1beta1.Ingress{ObjectMeta: defaultMeta})
+		// The Route of the Knative Service can only become ready once it controls the Service with the name of the
+		// application, so the Service of the Deployment or the StatefulSet is handed over once the latest revision
+		// is ready to serve
+		if revisionReady {
+			if err = r.releaseService(instance); err != nil {
+				reqLogger.Error(err, "Failed to release Service to Knative")
+				return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+			}
+		}
+
+		// Keep the Deployment or the StatefulSet running until the Knative Service is ready
+		previousMode, err := r.getExistingWorkloadMode(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get non-Knative resources")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		if previousMode != "" {
+			if err = r.setModeTransition(instance, previousMode, appsodyv1beta1.ApplicationModeKnative, getModeTransitionPhase(ready)); err != nil {
+				reqLogger.Error(err, "Failed to record the mode transition")
+				return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+			}
+			if !revisionReady {
+				reqLogger.Info("Waiting for the latest revision of Knative Service to be ready before handing over Service")
+				return r.manageModeTransition(instance)
+			}
+			if !ready {
+				reqLogger.Info("Waiting for Knative Service to be ready before cleaning up non-Knative resources")
+				return r.manageModeTransition(instance)
+			}
+		}
+		// Clean up non-Knative resources
+		if err = r.reconcileIngress(instance, false); err != nil {
+			reqLogger.Error(err, "Failed to clean up non-Knative resource Ingress")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
 		if r.IsOpenShift() {
 			route := &routev1.Route{ObjectMeta: defaultMeta}
@@ -620,61 +914,129 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 				return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 			}
 		}
+		if err = r.reconcileServiceMesh(instance, false); err != nil {
+			reqLogger.Error(err, "Failed to clean up non-Knative service mesh resources")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		if _, err = r.reconcileHTTPRoute(instance); err != nil {
+			reqLogger.Error(err, "Failed to clean up non-Knative resource HTTPRoute")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		// Knative scales the revisions itself, so the HorizontalPodAutoscaler and the KEDA ScaledObject go away
+		if err = r.reconcileAutoscaling(instance, nil); err != nil {
+			reqLogger.Error(err, "Failed to clean up non-Knative autoscaling resources")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		// Resource recommendation is rejected in Knative mode, so this deletes the VerticalPodAutoscaler
+		if err = r.reconcileVerticalPodAutoscaler(instance); err != nil {
+			reqLogger.Error(err, "Failed to clean up non-Knative resource VerticalPodAutoscaler")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		resources := []runtime.Object{
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: instance.Name + "-headless", Namespace: instance.Namespace}},
+			&appsv1.Deployment{ObjectMeta: defaultMeta},
+			&appsv1.StatefulSet{ObjectMeta: defaultMeta},
+			&networkingv1.NetworkPolicy{ObjectMeta: defaultMeta},
+		}
+		err = r.DeleteResources(resources)
+		if err != nil {
+			reqLogger.Error(err, "Failed to clean up non-Knative resources")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
 
-		if isKnativeSupported {
-			ksvc := &servingv1alpha1.Service{ObjectMeta: defaultMeta}
-			err = r.CreateOrUpdate(ksvc, instance, func() error {
-				oputils.CustomizeKnativeService(ksvc, instance)
-				oputils.CustomizeServiceBinding(resolvedBindingSecret, &ksvc.Spec.Template.Spec.PodSpec, instance)
-				return nil
-			})
-
-			if err != nil {
-				reqLogger.Error(err, "Failed to reconcile Knative Service")
-				return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-			}
-			return r.ManageSuccess(common.StatusConditionTypeReconciled, instance)
+		if err = r.reconcileEvents(instance, knativeAPIVersion); err != nil {
+			reqLogger.Error(err, "Failed to reconcile events")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		r.clearModeTransition(instance)
+		requeueAfter := scaling.requeueAfter(now)
+		if configRequeueAfter > 0 && (requeueAfter == 0 || configRequeueAfter < requeueAfter) {
+			requeueAfter = configRequeueAfter
 		}
-		return r.ManageError(errors.New("failed to reconcile Knative service as operator could not find Knative CRDs"), common.StatusConditionTypeReconciled, instance)
+		result, err := r.ManageSuccess(common.StatusConditionTypeReconciled, instance)
+		if err == nil && result == (reconcile.Result{}) && requeueAfter > 0 {
+			result.RequeueAfter = requeueAfter
+		}
+		return result, err
 	}
+	r.setScheduleStatus(instance, scaling)
 
-	if isKnativeSupported {
-		ksvc := &servingv1alpha1.Service{ObjectMeta: defaultMeta}
-		err = r.DeleteResource(ksvc)
+	// Keep the Knative Service, or the Deployment or the StatefulSet of the other mode, serving until the workload of
+	// the application is ready
+	mode, previousMode := getWorkloadMode(instance), appsodyv1beta1.ApplicationMode("")
+	if knativeAPIVersion != "" {
+		ksvc, err := r.getKnativeService(instance, knativeAPIVersion)
 		if err != nil {
-			reqLogger.Error(err, "Failed to delete Knative Service")
-			r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+			reqLogger.Error(err, "Failed to get Knative Service")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		if ksvc != nil {
+			previousMode = appsodyv1beta1.ApplicationModeKnative
+		}
+	}
+	if previousMode == "" {
+		otherMode := appsodyv1beta1.ApplicationModeStatefulSet
+		if mode == appsodyv1beta1.ApplicationModeStatefulSet {
+			otherMode = appsodyv1beta1.ApplicationModeDeployment
+		}
+		exists, _, err := r.getWorkloadReadiness(instance, otherMode)
+		if err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Failed to get %s", otherMode))
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		if exists {
+			previousMode = otherMode
 		}
 	}
 
-	svc := &corev1.Service{ObjectMeta: defaultMeta}
-	err = r.CreateOrUpdate(svc, instance, func() error {
-		oputils.CustomizeService(svc, ba)
-		svc.Annotations = oputils.MergeMaps(svc.Annotations, instance.Spec.Service.Annotations)
-		monitoringEnabledLabelName := getMonitoringEnabledLabelName(ba)
-		if instance.Spec.Monitoring != nil {
-			svc.Labels[monitoringEnabledLabelName] = "true"
-		} else {
-			if _, ok := svc.Labels[monitoringEnabledLabelName]; ok {
-				delete(svc.Labels, monitoringEnabledLabelName)
+	// The Service is created once the Route of the Knative Service releases it
+	serviceHeld, err := r.isServiceHeldByKnative(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get Service")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+	if !serviceHeld {
+		svc := &corev1.Service{ObjectMeta: defaultMeta}
+		err = r.CreateOrUpdate(svc, instance, func() error {
+			oputils.CustomizeService(svc, ba)
+			svc.Annotations = oputils.MergeMaps(svc.Annotations, instance.Spec.Service.Annotations)
+			monitoringEnabledLabelName := getMonitoringEnabledLabelName(ba)
+			if instance.Spec.Monitoring != nil {
+				svc.Labels[monitoringEnabledLabelName] = "true"
+			} else {
+				if _, ok := svc.Labels[monitoringEnabledLabelName]; ok {
+					delete(svc.Labels, monitoringEnabledLabelName)
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			reqLogger.Error(err, "Failed to reconcile Service")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
-		return nil
-	})
+	}
+
+	restored, err := r.reconcileRestore(instance)
 	if err != nil {
-		reqLogger.Error(err, "Failed to reconcile Service")
+		reqLogger.Error(err, "Failed to restore backup")
 		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 	}
+	if !restored {
+		reqLogger.Info("Waiting for persistent volume claims to be restored", "restoreFrom", instance.Spec.Storage.Backup.RestoreFrom)
+		return reconcile.Result{RequeueAfter: snapshotPollInterval}, nil
+	}
 
-	if instance.Spec.Storage != nil {
-		// Delete Deployment if exists
-		deploy := &appsv1.Deployment{ObjectMeta: defaultMeta}
-		err = r.DeleteResource(deploy)
+	err = r.reconcileSharedStorage(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile shared PersistentVolumeClaim")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
 
-		if err != nil {
-			reqLogger.Error(err, "Failed to delete Deployment")
-			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-		}
+	podTemplate := &corev1.PodTemplateSpec{}
+	customizePodTemplate(podTemplate, instance, resolvedBindingSecret, rewriteRules, configHash)
+	podTemplateHash := appsodyutils.GetHash(podTemplate)
+
+	if instance.Spec.Storage != nil && !instance.Spec.Storage.IsShared() {
 		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: instance.Name + "-headless", Namespace: instance.Namespace}}
 		err = r.CreateOrUpdate(svc, instance, func() error {
 			oputils.CustomizeService(svc, instance)
@@ -687,12 +1049,23 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
 
+		ready, err := r.reconcileRolloutBackup(instance, &appsv1.StatefulSet{}, podTemplateHash)
+		if err != nil {
+			reqLogger.Error(err, "Failed to take snapshots before rollout")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		if !ready {
+			reqLogger.Info("Waiting for snapshots to be taken before rollout")
+			return reconcile.Result{RequeueAfter: snapshotPollInterval}, nil
+		}
+
 		statefulSet := &appsv1.StatefulSet{ObjectMeta: defaultMeta}
 		err = r.CreateOrUpdate(statefulSet, instance, func() error {
 			oputils.CustomizeStatefulSet(statefulSet, instance)
-			oputils.CustomizePodSpec(&statefulSet.Spec.Template, instance)
+			scaling.customizeReplicas(&statefulSet.Spec.Replicas)
+			customizePodTemplate(&statefulSet.Spec.Template, instance, resolvedBindingSecret, rewriteRules, configHash)
 			oputils.CustomizePersistence(statefulSet, instance)
-			oputils.CustomizeServiceBinding(resolvedBindingSecret, &statefulSet.Spec.Template.Spec, instance)
+			statefulSet.Annotations[podTemplateHashAnnotation] = podTemplateHash
 			return nil
 		})
 		if err != nil {
@@ -701,27 +1074,28 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 		}
 
 	} else {
-		// Delete StatefulSet if exists
-		statefulSet := &appsv1.StatefulSet{ObjectMeta: defaultMeta}
-		err = r.DeleteResource(statefulSet)
-		if err != nil {
-			reqLogger.Error(err, "Failed to delete Statefulset")
-			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		if instance.Spec.Storage != nil {
+			ready, err := r.reconcileRolloutBackup(instance, &appsv1.Deployment{}, podTemplateHash)
+			if err != nil {
+				reqLogger.Error(err, "Failed to take snapshots before rollout")
+				return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+			}
+			if !ready {
+				reqLogger.Info("Waiting for snapshots to be taken before rollout")
+				return reconcile.Result{RequeueAfter: snapshotPollInterval}, nil
+			}
 		}
 
-		// Delete StatefulSet if exists
-		headlesssvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: instance.Name + "-headless", Namespace: instance.Namespace}}
-		err = r.DeleteResource(headlesssvc)
-
-		if err != nil {
-			reqLogger.Error(err, "Failed to delete headless Service")
-			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-		}
 		deploy := &appsv1.Deployment{ObjectMeta: defaultMeta}
 		err = r.CreateOrUpdate(deploy, instance, func() error {
 			oputils.CustomizeDeployment(deploy, instance)
-			oputils.CustomizePodSpec(&deploy.Spec.Template, instance)
-			oputils.CustomizeServiceBinding(resolvedBindingSecret, &deploy.Spec.Template.Spec, instance)
+			scaling.customizeReplicas(&deploy.Spec.Replicas)
+			customizePodTemplate(&deploy.Spec.Template, instance, resolvedBindingSecret, rewriteRules, configHash)
+			if instance.Spec.Storage != nil {
+				deploy.Annotations[podTemplateHashAnnotation] = podTemplateHash
+			} else {
+				delete(deploy.Annotations, podTemplateHashAnnotation)
+			}
 			return nil
 		})
 		if err != nil {
@@ -731,31 +1105,100 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 
 	}
 
-	if instance.Spec.Autoscaling != nil {
-		hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: defaultMeta}
-		err = r.CreateOrUpdate(hpa, instance, func() error {
-			oputils.CustomizeHPA(hpa, instance)
-			return nil
-		})
-
+	if previousMode != "" {
+		_, ready, err := r.getWorkloadReadiness(instance, mode)
 		if err != nil {
-			reqLogger.Error(err, "Failed to reconcile HorizontalPodAutoscaler")
+			reqLogger.Error(err, fmt.Sprintf("Failed to get the readiness of %s", mode))
 			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
-	} else {
-		hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: defaultMeta}
-		err = r.DeleteResource(hpa)
-		if err != nil {
-			reqLogger.Error(err, "Failed to delete HorizontalPodAutoscaler")
+		if err = r.setModeTransition(instance, previousMode, mode, getModeTransitionPhase(ready)); err != nil {
+			reqLogger.Error(err, "Failed to record the mode transition")
 			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
+		if !ready {
+			reqLogger.Info(fmt.Sprintf("Waiting for %s to be ready before cleaning up %s resources", mode, previousMode))
+			return r.manageModeTransition(instance)
+		}
+	}
+	if serviceHeld {
+		// Deleting the Knative Service makes its Route release the Service of the application
+		if knativeAPIVersion != "" {
+			if err = r.deleteKnativeService(instance, knativeAPIVersion); err != nil {
+				reqLogger.Error(err, "Failed to delete Knative Service")
+				return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+			}
+		}
+		if err = r.setModeTransition(instance, appsodyv1beta1.ApplicationModeKnative, mode, appsodyv1beta1.ModeTransitionPhaseCleaningUp); err != nil {
+			reqLogger.Error(err, "Failed to record the mode transition")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+		reqLogger.Info(fmt.Sprintf("Waiting for Knative to release Service %s", instance.Name))
+		return r.manageModeTransition(instance)
+	}
+
+	requeueAfter, err := r.reconcileBackups(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile backups")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	if scheduleRequeueAfter := scaling.requeueAfter(now); scheduleRequeueAfter > 0 && (requeueAfter == 0 || scheduleRequeueAfter < requeueAfter) {
+		requeueAfter = scheduleRequeueAfter
+	}
+	if imageUpdateRequeueAfter > 0 && (requeueAfter == 0 || imageUpdateRequeueAfter < requeueAfter) {
+		requeueAfter = imageUpdateRequeueAfter
+	}
+	if stackRequeueAfter > 0 && (requeueAfter == 0 || stackRequeueAfter < requeueAfter) {
+		requeueAfter = stackRequeueAfter
+	}
+	if certificateRequeueAfter > 0 && (requeueAfter == 0 || certificateRequeueAfter < requeueAfter) {
+		requeueAfter = certificateRequeueAfter
+	}
+	if configRequeueAfter > 0 && (requeueAfter == 0 || configRequeueAfter < requeueAfter) {
+		requeueAfter = configRequeueAfter
+	}
+
+	err = r.reconcileAutoscaling(instance, scaling.autoscaling)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile autoscaling")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	err = r.reconcileVerticalPodAutoscaler(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile VerticalPodAutoscaler")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	err = r.reconcileServiceMesh(instance, instance.Spec.ServiceMesh != nil)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile service mesh")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	err = r.reconcileEvents(instance, "")
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile events")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	err = r.reconcileNetworkPolicy(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile NetworkPolicy")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+
+	useHTTPRoute, err := r.reconcileHTTPRoute(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile HTTPRoute")
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 	}
 
 	if ok, err := r.IsGroupVersionSupported(routev1.SchemeGroupVersion.String(), "Route"); err != nil {
 		reqLogger.Error(err, fmt.Sprintf("Failed to check if %s is supported", routev1.SchemeGroupVersion.String()))
 		r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 	} else if ok {
-		if instance.Spec.Expose != nil && *instance.Spec.Expose {
+		if instance.Spec.Expose != nil && *instance.Spec.Expose && !useHTTPRoute {
 			route := &routev1.Route{ObjectMeta: defaultMeta}
 			err = r.CreateOrUpdate(route, instance, func() error {
 				key, cert, caCert, destCACert, err := r.GetRouteTLSValues(ba)
@@ -778,29 +1221,10 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 			}
 		}
 	} else {
-
-		if ok, err := r.IsGroupVersionSupported(networkingv1beta1.SchemeGroupVersion.String(), "Ingress"); err != nil {
-			reqLogger.Error(err, fmt.Sprintf("Failed to check if %s is supported", networkingv1beta1.SchemeGroupVersion.String()))
-			r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-		} else if ok {
-			if instance.Spec.Expose != nil && *instance.Spec.Expose {
-				ing := &networkingv1beta1.Ingress{ObjectMeta: defaultMeta}
-				err = r.CreateOrUpdate(ing, instance, func() error {
-					oputils.CustomizeIngress(ing, instance)
-					return nil
-				})
-				if err != nil {
-					reqLogger.Error(err, "Failed to reconcile Ingress")
-					return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-				}
-			} else {
-				ing := &networkingv1beta1.Ingress{ObjectMeta: defaultMeta}
-				err = r.DeleteResource(ing)
-				if err != nil {
-					reqLogger.Error(err, "Failed to delete Ingress")
-					return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
-				}
-			}
+		err = r.reconcileIngress(instance, instance.Spec.Expose != nil && *instance.Spec.Expose && !useHTTPRoute)
+		if err != nil {
+			reqLogger.Error(err, "Failed to reconcile Ingress")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
 		}
 	}
 
@@ -831,7 +1255,68 @@ func (r *ReconcileAppsodyApplication) Reconcile(request reconcile.Request) (reco
 		reqLogger.V(1).Info(fmt.Sprintf("%s is not supported", prometheusv1.SchemeGroupVersion.String()))
 	}
 
-	return r.ManageSuccess(common.StatusConditionTypeReconciled, instance)
+	// Clean up the resources of the previous mode
+	resources := []runtime.Object{&appsv1.Deployment{ObjectMeta: defaultMeta}}
+	if mode == appsodyv1beta1.ApplicationModeDeployment {
+		resources = []runtime.Object{
+			&appsv1.StatefulSet{ObjectMeta: defaultMeta},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: instance.Name + "-headless", Namespace: instance.Namespace}},
+		}
+	}
+	err = r.DeleteResources(resources)
+	if err != nil {
+		reqLogger.Error(err, fmt.Sprintf("Failed to clean up resources of the previous mode of %s", mode))
+		return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+	}
+	if knativeAPIVersion != "" {
+		err = r.deleteKnativeService(instance, knativeAPIVersion)
+		if err != nil {
+			reqLogger.Error(err, "Failed to delete Knative Service")
+			return r.ManageError(err, common.StatusConditionTypeReconciled, instance)
+		}
+	}
+	r.clearModeTransition(instance)
+
+	result, err = r.ManageSuccess(common.StatusConditionTypeReconciled, instance)
+	if err == nil && result == (reconcile.Result{}) && requeueAfter > 0 {
+		result.RequeueAfter = requeueAfter
+	}
+	return result, err
+}
+
+// setScheduleStatus records the schedule window in effect and whether the application is suspended in the status,
+// with an event when a schedule window starts
+func (r *ReconcileAppsodyApplication) setScheduleStatus(instance *appsodyv1beta1.AppsodyApplication, scaling *scaling) {
+	if scaling.window != instance.Status.ScheduleWindow && scaling.window != "" {
+		r.GetRecorder().Event(instance, "Normal", "ScheduleWindowStarted", fmt.Sprintf("Schedule window %s is in effect", scaling.window))
+	}
+	instance.Status.ScheduleWindow = scaling.window
+	instance.Status.Suspended = scaling.suspended
+}
+
+// customizePodTemplate applies the pod template settings shared by Deployments and StatefulSets
+func customizePodTemplate(pts *corev1.PodTemplateSpec, instance *appsodyv1beta1.AppsodyApplication, resolvedBindingSecret *corev1.Secret, rewriteRules []appsodyutils.ImageRewriteRule, configHash string) {
+	oputils.CustomizePodSpec(pts, instance)
+	appsodyutils.CustomizeTCPReadinessProbe(pts, instance)
+	if archs := instance.Status.ImageArchitectures; archs != nil && len(archs.Architectures) > 0 && len(instance.GetRequestedArchitectures()) == 0 {
+		appsodyutils.CustomizeArchitectureAffinity(pts, archs.Architectures)
+	}
+	rewritePodSpecImages(&pts.Spec, rewriteRules)
+	if instance.Spec.Storage != nil && instance.Spec.Storage.IsShared() {
+		appsodyutils.CustomizeSharedStorage(pts, instance)
+	}
+	oputils.CustomizeServiceBinding(resolvedBindingSecret, &pts.Spec, instance)
+	setConfigHashAnnotation(&pts.ObjectMeta, configHash)
+	appsodyutils.CustomizeSidecarInjection(&pts.ObjectMeta, instance)
+}
+
+// rewritePodSpecImages applies the registry rewrite rules to the images of the init and sidecar containers. The image
+// of the application container is already rewritten in status.imageReference.
+func rewritePodSpecImages(spec *corev1.PodSpec, rewriteRules []appsodyutils.ImageRewriteRule) {
+	spec.InitContainers = appsodyutils.RewriteContainerImages(spec.InitContainers, rewriteRules)
+	if len(spec.Containers) > 1 {
+		spec.Containers = append(spec.Containers[:1:1], appsodyutils.RewriteContainerImages(spec.Containers[1:], rewriteRules)...)
+	}
 }
 
 func getMonitoringEnabledLabelName(ba common.BaseComponent) string {
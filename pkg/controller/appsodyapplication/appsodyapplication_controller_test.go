@@ -2,12 +2,14 @@ package appsodyapplication
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strconv"
 	"testing"
 
 	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
 	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
 	prometheusv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 
@@ -18,7 +20,9 @@ import (
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/discovery"
@@ -28,6 +32,7 @@ import (
 	coretesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	applicationsv1beta1 "sigs.k8s.io/application/pkg/apis/app/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -143,7 +148,11 @@ func TestAppsodyController(t *testing.T) {
 	}
 	updateAppsody(r, appsody, t)
 
-	// Reconcile again to check for the StatefulSet and updated resources
+	// Reconcile again to check for the StatefulSet and updated resources. The Deployment keeps serving until the
+	// StatefulSet is ready.
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+	markWorkloadReady(r, req, appsodyv1beta1.ApplicationModeStatefulSet, t)
 	res, err = r.Reconcile(req)
 	verifyReconcile(res, err, t)
 
@@ -176,7 +185,11 @@ func TestAppsodyController(t *testing.T) {
 	}
 	updateAppsody(r, appsody, t)
 
-	// Reconcile again to check for the KNativeService and updated resources
+	// Reconcile again to check for the KNativeService and updated resources. The StatefulSet keeps serving until the
+	// KnativeService is ready.
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+	markKnativeServiceReady(r, req, t)
 	res, err = r.Reconcile(req)
 	verifyReconcile(res, err, t)
 
@@ -208,7 +221,11 @@ func TestAppsodyController(t *testing.T) {
 	appsody.Spec = appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, Expose: &expose}
 	updateAppsody(r, appsody, t)
 
-	// Reconcile again to check for the route and updated resources
+	// Reconcile again to check for the route and updated resources. The KnativeService keeps serving until the
+	// Deployment is ready.
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+	markWorkloadReady(r, req, appsodyv1beta1.ApplicationModeDeployment, t)
 	res, err = r.Reconcile(req)
 	verifyReconcile(res, err, t)
 
@@ -380,6 +397,91 @@ func TestConfigMapConstants(t *testing.T) {
 	verifyTests("configMapConstants", configMapConstTests, t)
 }
 
+func TestServiceProtocol(t *testing.T) {
+	expose, createKnativeService, secretRef := true, true, "grpc-tls"
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:  stack,
+		Expose: &expose,
+		Service: &appsodyv1beta1.AppsodyApplicationService{
+			Port:                 9000,
+			Protocol:             appsodyv1beta1.ServiceProtocolGRPC,
+			CertificateSecretRef: &secretRef,
+		},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+	tlsSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretRef, Namespace: namespace}}
+
+	r := newTestReconciler(t, appsody, tlsSecret)
+
+	// The port, the probes and the Route of a gRPC application carry HTTP/2
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	svc, deploy, route := &corev1.Service{}, &appsv1.Deployment{}, &routev1.Route{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("Get Service: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, deploy); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("Get Route: (%v)", err)
+	}
+	container := deploy.Spec.Template.Spec.Containers[0]
+	grpcTests := []Test{
+		{"service port name", "grpc-9000", svc.Spec.Ports[0].Name},
+		{"container port name", "grpc-9000", container.Ports[0].Name},
+		{"route target port", "grpc-9000", route.Spec.Port.TargetPort.String()},
+		{"route termination", string(routev1.TLSTerminationReencrypt), string(route.Spec.TLS.Termination)},
+		{"readiness probe port", "9000", container.ReadinessProbe.TCPSocket.Port.String()},
+		{"no liveness probe", true, container.LivenessProbe == nil},
+	}
+	verifyTests("grpc", grpcTests, t)
+
+	// The readiness probe is not saved in the CR, so it goes away with the gRPC protocol
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if appsody.Spec.ReadinessProbe != nil || appsody.Spec.LivenessProbe != nil {
+		t.Fatalf("Probes were saved in the CR")
+	}
+	appsody.Spec.Service.Protocol = appsodyv1beta1.ServiceProtocolTCP
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	deploy = &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, deploy); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	verifyTests("tcp", []Test{{"no readiness probe", true, deploy.Spec.Template.Spec.Containers[0].ReadinessProbe == nil}}, t)
+
+	// Knative requires the h2c port name for HTTP/2
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec = appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:                stack,
+		CreateKnativeService: &createKnativeService,
+		Service:              &appsodyv1beta1.AppsodyApplicationService{Port: 9000, Protocol: appsodyv1beta1.ServiceProtocolH2C},
+	}
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	ksvc := &servingv1alpha1.Service{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ksvc); err != nil {
+		t.Fatalf("Get KnativeService: (%v)", err)
+	}
+	knativeTests := []Test{
+		{"port name", "h2c", ksvc.Spec.Template.Spec.Containers[0].Ports[0].Name},
+		{"no tcp readiness probe", true, ksvc.Spec.Template.Spec.Containers[0].ReadinessProbe == nil},
+	}
+	verifyTests("knative h2c", knativeTests, t)
+}
+
 // Helper Functions
 func createAppsodyApp(n, ns string, spec appsodyv1beta1.AppsodyApplicationSpec) *appsodyv1beta1.AppsodyApplication {
 	app := &appsodyv1beta1.AppsodyApplication{
@@ -389,6 +491,74 @@ func createAppsodyApp(n, ns string, spec appsodyv1beta1.AppsodyApplicationSpec)
 	return app
 }
 
+// newTestReconciler creates a reconciler with a fake client tracking the objects, the default stack of the tests
+// and a fake discovery client
+func newTestReconciler(t *testing.T, objs ...runtime.Object) *ReconcileAppsodyApplication {
+	// Set the logger to development mode for verbose logs
+	logf.SetLogger(logf.ZapLogger(true))
+	os.Setenv("WATCH_NAMESPACE", namespace)
+
+	s := scheme.Scheme
+	addThirdPartySchemes(s, t)
+	s.AddKnownTypes(appsodyv1beta1.SchemeGroupVersion, &appsodyv1beta1.AppsodyApplication{}, &appsodyv1beta1.AppsodyApplicationList{})
+	cl := fakeclient.NewFakeClient(objs...)
+
+	rb := oputils.NewReconcilerBase(cl, s, &rest.Config{}, record.NewFakeRecorder(10))
+	defaultsMap := map[string]appsodyv1beta1.AppsodyApplicationSpec{stack: {Service: service}}
+	constantsMap := map[string]*appsodyv1beta1.AppsodyApplicationSpec{}
+
+	r := &ReconcileAppsodyApplication{ReconcilerBase: rb, StackDefaults: defaultsMap, StackConstants: constantsMap}
+	r.configReader = &clientConfigReader{client: cl}
+	r.SetDiscoveryClient(createFakeDiscoveryClient())
+	return r
+}
+
+// clientConfigReader reads the ConfigMaps and Secrets from the client, like the operator, reporting those labelled for
+// config rollout as watched
+type clientConfigReader struct {
+	client client.Client
+}
+
+func (c *clientConfigReader) GetConfigMap(namespace string, name string) (*corev1.ConfigMap, bool, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, cm); err != nil {
+		return nil, false, err
+	}
+	return cm, cm.Labels[appsodyutils.ConfigRolloutLabel] == "true", nil
+}
+
+func (c *clientConfigReader) GetSecret(namespace string, name string) (*corev1.Secret, bool, error) {
+	secret := &corev1.Secret{}
+	if err := c.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return nil, false, err
+	}
+	return secret, secret.Labels[appsodyutils.ConfigRolloutLabel] == "true", nil
+}
+
+// addUnstructuredKinds registers the kinds of the group version, and their lists, as unstructured objects
+func addUnstructuredKinds(gv schema.GroupVersion, kinds ...string) {
+	for _, kind := range kinds {
+		scheme.Scheme.AddKnownTypeWithName(gv.WithKind(kind), &unstructured.Unstructured{})
+		scheme.Scheme.AddKnownTypeWithName(gv.WithKind(kind+"List"), &unstructured.UnstructuredList{})
+	}
+}
+
+func addThirdPartySchemes(s *runtime.Scheme, t *testing.T) {
+	addToSchemes := []func(*runtime.Scheme) error{
+		servingv1alpha1.AddToScheme,
+		routev1.AddToScheme,
+		imagev1.AddToScheme,
+		applicationsv1beta1.AddToScheme,
+		certmngrv1alpha2.AddToScheme,
+		prometheusv1.AddToScheme,
+	}
+	for _, addToScheme := range addToSchemes {
+		if err := addToScheme(s); err != nil {
+			t.Fatalf("Unable to add scheme: (%v)", err)
+		}
+	}
+}
+
 func createFakeDiscoveryClient() discovery.DiscoveryInterface {
 	fakeDiscoveryClient := &fakediscovery.FakeDiscovery{Fake: &coretesting.Fake{}}
 	fakeDiscoveryClient.Resources = []*metav1.APIResourceList{
@@ -458,6 +628,97 @@ func verifyReconcile(res reconcile.Result, err error, t *testing.T) {
 	}
 }
 
+// getModeTransition returns the modes and the phase of the mode transition of the application
+func getModeTransition(appsody *appsodyv1beta1.AppsodyApplication) string {
+	transition := appsody.Status.ModeTransition
+	if transition == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", transition.From, transition.To, transition.Phase)
+}
+
+// verifyModeTransition checks that the reconcile waits for the new mode of the application to be ready
+func verifyModeTransition(res reconcile.Result, err error, t *testing.T) {
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res != (reconcile.Result{RequeueAfter: modeTransitionPollInterval}) {
+		t.Errorf("reconcile did not wait for the mode transition (%v)", res)
+	}
+}
+
+// markKnativeServiceReady sets the Ready condition of the Knative Service to true, as Knative would
+func markKnativeServiceReady(r *ReconcileAppsodyApplication, req reconcile.Request, t *testing.T) {
+	setKnativeServiceConditions(r, req, t, "ConfigurationsReady", "Ready")
+}
+
+// markKnativeRevisionReady sets the latest revision of the Knative Service as ready, before its Route, as Knative would
+func markKnativeRevisionReady(r *ReconcileAppsodyApplication, req reconcile.Request, t *testing.T) {
+	setKnativeServiceConditions(r, req, t, "ConfigurationsReady")
+}
+
+// setKnativeServiceConditions sets the conditions of the Knative Service to true
+func setKnativeServiceConditions(r *ReconcileAppsodyApplication, req reconcile.Request, t *testing.T, conditionTypes ...string) {
+	ksvc := &unstructured.Unstructured{}
+	ksvc.SetAPIVersion(servingv1alpha1.SchemeGroupVersion.String())
+	ksvc.SetKind("Service")
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, ksvc); err != nil {
+		t.Fatalf("Get KnativeService: (%v)", err)
+	}
+	conditions := []interface{}{}
+	for _, conditionType := range conditionTypes {
+		conditions = append(conditions, map[string]interface{}{"type": conditionType, "status": "True"})
+	}
+	if err := unstructured.SetNestedSlice(ksvc.Object, conditions, "status", "conditions"); err != nil {
+		t.Fatalf("Set KnativeService conditions: (%v)", err)
+	}
+	if err := r.GetClient().Update(context.TODO(), ksvc); err != nil {
+		t.Fatalf("Update KnativeService: (%v)", err)
+	}
+}
+
+// createKnativeRouteService creates the Service that the Route of the Knative Service controls, as Knative would
+func createKnativeRouteService(r *ReconcileAppsodyApplication, req reconcile.Request, t *testing.T) {
+	isController := true
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      req.Name,
+		Namespace: req.Namespace,
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: servingv1alpha1.SchemeGroupVersion.String(), Kind: "Route", Name: req.Name, UID: "route-uid", Controller: &isController},
+		},
+	}}
+	if err := r.GetClient().Create(context.TODO(), svc); err != nil {
+		t.Fatalf("Create Knative Route Service: (%v)", err)
+	}
+}
+
+// markWorkloadReady sets all the replicas of the Deployment or the StatefulSet of the mode as ready, as Kubernetes would
+func markWorkloadReady(r *ReconcileAppsodyApplication, req reconcile.Request, mode appsodyv1beta1.ApplicationMode, t *testing.T) {
+	if mode == appsodyv1beta1.ApplicationModeStatefulSet {
+		statefulSet := &appsv1.StatefulSet{}
+		if err := r.GetClient().Get(context.TODO(), req.NamespacedName, statefulSet); err != nil {
+			t.Fatalf("Get StatefulSet: (%v)", err)
+		}
+		statefulSet.Status.ObservedGeneration = statefulSet.Generation
+		statefulSet.Status.ReadyReplicas = getDesiredReplicas(statefulSet.Spec.Replicas)
+		if err := r.GetClient().Update(context.TODO(), statefulSet); err != nil {
+			t.Fatalf("Update StatefulSet: (%v)", err)
+		}
+		return
+	}
+
+	deploy := &appsv1.Deployment{}
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, deploy); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	deploy.Status.ObservedGeneration = deploy.Generation
+	deploy.Status.UpdatedReplicas = getDesiredReplicas(deploy.Spec.Replicas)
+	deploy.Status.ReadyReplicas = deploy.Status.UpdatedReplicas
+	if err := r.GetClient().Update(context.TODO(), deploy); err != nil {
+		t.Fatalf("Update Deployment: (%v)", err)
+	}
+}
+
 func verifyTests(n string, tests []Test, t *testing.T) {
 	for _, tt := range tests {
 		if tt.actual != tt.expected {
@@ -0,0 +1,137 @@
+package appsodyapplication
+
+import (
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	"github.com/pkg/errors"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// reconcileAutoscaling scales the application with either a KEDA ScaledObject, when autoscaling is event-driven, or a
+// HorizontalPodAutoscaler, and deletes the other one
+func (r *ReconcileAppsodyApplication) reconcileAutoscaling(instance *appsodyv1beta1.AppsodyApplication, autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling) error {
+	if autoscaling != nil && autoscaling.IsEventDriven() {
+		if err := r.reconcileHorizontalPodAutoscaler(instance, nil); err != nil {
+			return err
+		}
+		return r.reconcileScaledObject(instance, autoscaling)
+	}
+	if err := r.reconcileScaledObject(instance, nil); err != nil {
+		return err
+	}
+	return r.reconcileHorizontalPodAutoscaler(instance, autoscaling)
+}
+
+// reconcileScaledObject creates or updates the KEDA ScaledObject of the application and the TriggerAuthentication
+// of its triggers, or deletes them when autoscaling is nil
+func (r *ReconcileAppsodyApplication) reconcileScaledObject(instance *appsodyv1beta1.AppsodyApplication, autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling) error {
+	if ok, _ := r.IsGroupVersionSupported(appsodyutils.KedaAPIVersion, "ScaledObject"); !ok {
+		if autoscaling != nil {
+			return errors.New("failed to reconcile event-driven autoscaling as the operator could not find KEDA CRDs")
+		}
+		return nil
+	}
+
+	so := newKedaObject(instance, "ScaledObject")
+	ta := newKedaObject(instance, "TriggerAuthentication")
+	if autoscaling == nil {
+		return r.DeleteResources([]runtime.Object{so, ta})
+	}
+
+	var err error
+	if autoscaling.EventDriven.Authentication != nil {
+		err = r.CreateOrUpdate(ta, instance, func() error {
+			appsodyutils.CustomizeTriggerAuthentication(ta, instance)
+			return nil
+		})
+	} else {
+		err = r.DeleteResource(ta)
+	}
+	if err != nil {
+		return err
+	}
+	return r.CreateOrUpdate(so, instance, func() error {
+		return appsodyutils.CustomizeScaledObject(so, autoscaling, instance)
+	})
+}
+
+func newKedaObject(instance *appsodyv1beta1.AppsodyApplication, kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(appsodyutils.KedaAPIVersion)
+	obj.SetKind(kind)
+	obj.SetName(instance.Name)
+	obj.SetNamespace(instance.Namespace)
+	return obj
+}
+
+// reconcileHorizontalPodAutoscaler creates or updates the HorizontalPodAutoscaler of the application, or deletes it
+// when autoscaling is nil. An autoscaling/v2beta2 HPA is used when the cluster supports it. Otherwise, an autoscaling/v1
+// HPA is used and the AutoscalingSupported condition reports the settings that could not be applied.
+func (r *ReconcileAppsodyApplication) reconcileHorizontalPodAutoscaler(instance *appsodyv1beta1.AppsodyApplication, autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling) error {
+	defaultMeta := metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}
+	if autoscaling == nil {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeAutoscalingSupported)
+		return r.DeleteResource(&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: defaultMeta})
+	}
+
+	if ok, _ := r.IsGroupVersionSupported(appsodyutils.HorizontalPodAutoscalerV2beta2, "HorizontalPodAutoscaler"); ok {
+		hpa := &unstructured.Unstructured{}
+		hpa.SetAPIVersion(appsodyutils.HorizontalPodAutoscalerV2beta2)
+		hpa.SetKind("HorizontalPodAutoscaler")
+		hpa.SetName(instance.Name)
+		hpa.SetNamespace(instance.Namespace)
+		err := r.CreateOrUpdate(hpa, instance, func() error {
+			return appsodyutils.CustomizeHorizontalPodAutoscaler(hpa, autoscaling, instance)
+		})
+		if err != nil {
+			return err
+		}
+		instance.Status.SetCondition(&appsodyv1beta1.StatusCondition{
+			Type:   appsodyv1beta1.StatusConditionTypeAutoscalingSupported,
+			Status: corev1.ConditionTrue,
+		})
+		return nil
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: defaultMeta}
+	err := r.CreateOrUpdate(hpa, instance, func() error {
+		oputils.CustomizeHPA(hpa, instance)
+		hpa.Spec.MinReplicas = autoscaling.GetMinReplicas()
+		hpa.Spec.MaxReplicas = autoscaling.GetMaxReplicas()
+		hpa.Spec.ScaleTargetRef.Kind = appsodyutils.GetScaleTargetKind(instance)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	condition := &appsodyv1beta1.StatusCondition{
+		Type:   appsodyv1beta1.StatusConditionTypeAutoscalingSupported,
+		Status: corev1.ConditionTrue,
+	}
+	if autoscaling.RequiresV2beta2() {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "UnsupportedMetrics"
+		condition.Message = "The cluster does not support " + appsodyutils.HorizontalPodAutoscalerV2beta2 + ". Only the CPU target, minReplicas and maxReplicas are applied."
+		if old := instance.Status.GetCondition(condition.GetType()); old == nil || old.GetStatus() != corev1.ConditionFalse {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+	}
+	instance.Status.SetCondition(condition)
+	return nil
+}
+
+func removeCondition(instance *appsodyv1beta1.AppsodyApplication, conditionType appsodyv1beta1.StatusConditionType) {
+	conditions := []appsodyv1beta1.StatusCondition{}
+	for _, c := range instance.Status.Conditions {
+		if c.Type != conditionType {
+			conditions = append(conditions, c)
+		}
+	}
+	instance.Status.Conditions = conditions
+}
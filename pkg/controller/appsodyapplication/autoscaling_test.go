@@ -0,0 +1,245 @@
+package appsodyapplication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestAutoscalingV2beta2(t *testing.T) {
+	var cpu, memory, window int32 = 70, 80, 300
+	hpaAutoscaling := &appsodyv1beta1.AppsodyApplicationAutoScaling{
+		MaxReplicas:                       3,
+		TargetCPUUtilizationPercentage:    &cpu,
+		TargetMemoryUtilizationPercentage: &memory,
+		Behavior: &appsodyv1beta1.AppsodyApplicationScalingBehavior{
+			ScaleDown: &appsodyv1beta1.AppsodyApplicationScalingRules{StabilizationWindowSeconds: &window},
+		},
+	}
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, Autoscaling: hpaAutoscaling}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+
+	// Without autoscaling/v2beta2, an autoscaling/v1 HPA is created and the unsupported settings are reported
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, hpa); err != nil {
+		t.Fatalf("Get HPA: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeAutoscalingSupported))
+	if condition == nil {
+		t.Fatalf("AutoscalingSupported condition was not set")
+	}
+	fallbackTests := []Test{
+		{"cpu target", cpu, *hpa.Spec.TargetCPUUtilizationPercentage},
+		{"condition status", corev1.ConditionFalse, condition.GetStatus()},
+	}
+	verifyTests("autoscaling/v1 fallback", fallbackTests, t)
+
+	// With autoscaling/v2beta2, memory targets and behavior are applied
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: autoscalingv2beta2.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler", SingularName: "horizontalpodautoscaler"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	hpaV2 := &unstructured.Unstructured{}
+	hpaV2.SetAPIVersion(autoscalingv2beta2.SchemeGroupVersion.String())
+	hpaV2.SetKind("HorizontalPodAutoscaler")
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, hpaV2); err != nil {
+		t.Fatalf("Get autoscaling/v2beta2 HPA: (%v)", err)
+	}
+	metrics, _, _ := unstructured.NestedSlice(hpaV2.Object, "spec", "metrics")
+	stabilizationWindow, _, _ := unstructured.NestedInt64(hpaV2.Object, "spec", "behavior", "scaleDown", "stabilizationWindowSeconds")
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeAutoscalingSupported))
+	v2Tests := []Test{
+		{"metrics", 2, len(metrics)},
+		{"stabilization window", int64(window), stabilizationWindow},
+		{"condition status", corev1.ConditionTrue, condition.GetStatus()},
+	}
+	verifyTests("autoscaling/v2beta2", v2Tests, t)
+}
+
+func TestEventDrivenAutoscaling(t *testing.T) {
+	eventDriven := &appsodyv1beta1.AppsodyEventDrivenAutoscaling{
+		Triggers: []appsodyv1beta1.AppsodyScaleTrigger{
+			{Type: "rabbitmq", Metadata: map[string]string{"queueName": "orders", "queueLength": "20"}},
+		},
+		Authentication: &appsodyv1beta1.AppsodyTriggerAuthentication{
+			SecretName: "rabbitmq-secret",
+			Parameters: map[string]string{"host": "connection-string"},
+		},
+	}
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, Autoscaling: &appsodyv1beta1.AppsodyApplicationAutoScaling{MaxReplicas: 3}}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	kedaGV := schema.GroupVersion{Group: "keda.sh", Version: "v1alpha1"}
+	addUnstructuredKinds(kedaGV, "ScaledObject", "TriggerAuthentication")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: kedaGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "scaledobjects", Namespaced: true, Kind: "ScaledObject", SingularName: "scaledobject"},
+			{Name: "triggerauthentications", Namespaced: true, Kind: "TriggerAuthentication", SingularName: "triggerauthentication"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, hpa); err != nil {
+		t.Fatalf("Get HPA: (%v)", err)
+	}
+
+	// Switching to event-driven autoscaling replaces the HPA with a ScaledObject
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.Autoscaling.EventDriven = eventDriven
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, &autoscalingv1.HorizontalPodAutoscaler{}); err == nil {
+		t.Fatalf("HPA was not deleted for event-driven autoscaling")
+	}
+	so := &unstructured.Unstructured{}
+	so.SetGroupVersionKind(kedaGV.WithKind("ScaledObject"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, so); err != nil {
+		t.Fatalf("Get ScaledObject: (%v)", err)
+	}
+	ta := &unstructured.Unstructured{}
+	ta.SetGroupVersionKind(kedaGV.WithKind("TriggerAuthentication"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ta); err != nil {
+		t.Fatalf("Get TriggerAuthentication: (%v)", err)
+	}
+	targetKind, _, _ := unstructured.NestedString(so.Object, "spec", "scaleTargetRef", "kind")
+	maxReplicas, _, _ := unstructured.NestedInt64(so.Object, "spec", "maxReplicaCount")
+	triggers, _, _ := unstructured.NestedSlice(so.Object, "spec", "triggers")
+	secretRefs, _, _ := unstructured.NestedSlice(ta.Object, "spec", "secretTargetRef")
+	kedaTests := []Test{
+		{"target kind", "Deployment", targetKind},
+		{"max replicas", int64(3), maxReplicas},
+		{"triggers", 1, len(triggers)},
+		{"secret refs", 1, len(secretRefs)},
+	}
+	verifyTests("event-driven autoscaling", kedaTests, t)
+
+	// Switching back deletes the ScaledObject and TriggerAuthentication
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.Autoscaling.EventDriven = nil
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, &autoscalingv1.HorizontalPodAutoscaler{}); err != nil {
+		t.Fatalf("Get HPA: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, so); err == nil {
+		t.Fatalf("ScaledObject was not deleted")
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ta); err == nil {
+		t.Fatalf("TriggerAuthentication was not deleted")
+	}
+}
+
+func TestEventDrivenAutoscalingKnative(t *testing.T) {
+	eventDriven := &appsodyv1beta1.AppsodyEventDrivenAutoscaling{
+		Triggers: []appsodyv1beta1.AppsodyScaleTrigger{
+			{Type: "rabbitmq", Metadata: map[string]string{"queueName": "orders", "queueLength": "20"}},
+		},
+		Authentication: &appsodyv1beta1.AppsodyTriggerAuthentication{SecretName: "rabbitmq-secret"},
+	}
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, Autoscaling: &appsodyv1beta1.AppsodyApplicationAutoScaling{MaxReplicas: 3, EventDriven: eventDriven}}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	kedaGV := schema.GroupVersion{Group: "keda.sh", Version: "v1alpha1"}
+	addUnstructuredKinds(kedaGV, "ScaledObject", "TriggerAuthentication")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: kedaGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "scaledobjects", Namespaced: true, Kind: "ScaledObject", SingularName: "scaledobject"},
+			{Name: "triggerauthentications", Namespaced: true, Kind: "TriggerAuthentication", SingularName: "triggerauthentication"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	so := &unstructured.Unstructured{}
+	so.SetGroupVersionKind(kedaGV.WithKind("ScaledObject"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, so); err != nil {
+		t.Fatalf("Get ScaledObject: (%v)", err)
+	}
+
+	// Switching to Knative deletes the ScaledObject and TriggerAuthentication once the Knative Service is ready
+	createKnativeService := true
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.CreateKnativeService = &createKnativeService
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	markKnativeRevisionReady(r, req, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	createKnativeRouteService(r, req, t)
+	markKnativeServiceReady(r, req, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	ta := &unstructured.Unstructured{}
+	ta.SetGroupVersionKind(kedaGV.WithKind("TriggerAuthentication"))
+	soErr := r.GetClient().Get(context.TODO(), req.NamespacedName, so)
+	taErr := r.GetClient().Get(context.TODO(), req.NamespacedName, ta)
+	hpaErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &autoscalingv1.HorizontalPodAutoscaler{})
+	knativeTests := []Test{
+		{"scaled object deleted", true, kerrors.IsNotFound(soErr)},
+		{"trigger authentication deleted", true, kerrors.IsNotFound(taErr)},
+		{"hpa deleted", true, kerrors.IsNotFound(hpaErr)},
+	}
+	verifyTests("knative", knativeTests, t)
+}
@@ -0,0 +1,165 @@
+package appsodyapplication
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileOperatorCertificates issues the certificates of the service and the route of the application with the CA
+// of the operator when cert-manager is not installed, into the secrets cert-manager would use. The certificates are
+// issued again before their renewBefore period, and the secrets that are not requested anymore are deleted. It returns
+// the time until the next renewal.
+func (r *ReconcileAppsodyApplication) reconcileOperatorCertificates(instance *appsodyv1beta1.AppsodyApplication, operatorNamespace string, now time.Time) (time.Duration, error) {
+	if ok, _ := r.IsGroupVersionSupported(certmngrv1alpha2.SchemeGroupVersion.String(), "Certificate"); ok {
+		return 0, nil
+	}
+
+	requests := []*appsodyutils.CertificateRequest{}
+	for _, req := range []*appsodyutils.CertificateRequest{appsodyutils.GetServiceCertificateRequest(instance), appsodyutils.GetRouteCertificateRequest(instance)} {
+		if req != nil {
+			requests = append(requests, req)
+		}
+	}
+
+	var requeueAfter time.Duration
+	desired := map[string]bool{}
+	if len(requests) > 0 {
+		ca, caUpdate, err := r.reconcileCertificateAuthority(appsodyutils.GetCertificateAuthorityNamespace(instance, operatorNamespace), now)
+		if err != nil {
+			return 0, err
+		}
+		requeueAfter = caUpdate.Sub(now)
+
+		for _, req := range requests {
+			if len(req.DNSNames) == 0 && len(req.IPAddresses) == 0 {
+				return 0, errors.Errorf("failed to issue the certificate of secret %s as it has no DNS name or IP address", req.SecretName)
+			}
+			desired[req.SecretName] = true
+			renewal, err := r.reconcileOperatorCertificate(instance, req, ca, now)
+			if err != nil {
+				return 0, err
+			}
+			if d := renewal.Sub(now); d < requeueAfter {
+				requeueAfter = d
+			}
+		}
+	}
+
+	// Delete the secrets of the certificates that are not requested anymore. The secrets are listed as unstructured
+	// objects, as the SecretList type is also registered in the image.openshift.io group.
+	secrets := &unstructured.UnstructuredList{}
+	secrets.SetAPIVersion("v1")
+	secrets.SetKind("SecretList")
+	err := r.GetClient().List(context.TODO(), secrets, client.InNamespace(instance.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/instance": instance.Name,
+		appsodyutils.OperatorCALabel: "true",
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if desired[secret.GetName()] || !metav1.IsControlledBy(secret, instance) {
+			continue
+		}
+		if err = r.DeleteResource(secret); err != nil {
+			return 0, err
+		}
+	}
+	return requeueAfter, nil
+}
+
+// reconcileCertificateAuthority creates the secret of the CA of the operator in the namespace, or renews the CA when
+// it is about to expire. The secret is shared by the applications and is not owned by any of them. It returns the
+// secret and the time the CA must be updated again.
+func (r *ReconcileAppsodyApplication) reconcileCertificateAuthority(namespace string, now time.Time) (*corev1.Secret, time.Time, error) {
+	secret := &corev1.Secret{}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: appsodyutils.CertificateAuthoritySecretName, Namespace: namespace}, secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, time.Time{}, err
+	}
+	exists := err == nil
+
+	data, updateTime, err := appsodyutils.RenewCertificateAuthority(secret.Data, now)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if exists && reflect.DeepEqual(data, secret.Data) {
+		return secret, updateTime, nil
+	}
+	secret.Name = appsodyutils.CertificateAuthoritySecretName
+	secret.Namespace = namespace
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = data
+	if exists {
+		err = r.GetClient().Update(context.TODO(), secret)
+	} else {
+		err = r.GetClient().Create(context.TODO(), secret)
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if exists {
+		log.Info("Renewed the CA of the operator", "Namespace", namespace, "Name", secret.Name)
+	} else {
+		log.Info("Issued the CA of the operator", "Namespace", namespace, "Name", secret.Name)
+	}
+	return secret, updateTime, nil
+}
+
+// reconcileOperatorCertificate issues the requested certificate with the CA into its secret, unless the secret already
+// holds a matching certificate that does not need to be renewed. It returns the time the certificate must be renewed.
+func (r *ReconcileAppsodyApplication) reconcileOperatorCertificate(instance *appsodyv1beta1.AppsodyApplication, req *appsodyutils.CertificateRequest, ca *corev1.Secret, now time.Time) (time.Time, error) {
+	secret := &corev1.Secret{}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: req.SecretName, Namespace: instance.Namespace}, secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return time.Time{}, err
+	}
+	if err == nil {
+		if !metav1.IsControlledBy(secret, instance) {
+			return time.Time{}, errors.Errorf("failed to issue the certificate of secret %s as the secret is not managed by the operator", req.SecretName)
+		}
+		if renewal, ok := appsodyutils.GetCertificateRenewalTime(secret, req, ca.Data[corev1.TLSCertKey]); ok && now.Before(renewal) {
+			if bytes.Equal(secret.Data["ca.crt"], ca.Data["ca.crt"]) {
+				return renewal, nil
+			}
+			// Distribute the CA bundle without issuing the certificate again, so that the renewed CA is trusted
+			// before it issues the certificates
+			secret.Data["ca.crt"] = ca.Data["ca.crt"]
+			return renewal, r.GetClient().Update(context.TODO(), secret)
+		}
+	}
+
+	cert, key, err := appsodyutils.IssueCertificate(req, ca.Data[corev1.TLSCertKey], ca.Data[corev1.TLSPrivateKeyKey], now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: req.SecretName, Namespace: instance.Namespace}}
+	err = r.CreateOrUpdate(secret, instance, func() error {
+		secret.Labels = instance.GetLabels()
+		secret.Labels[appsodyutils.OperatorCALabel] = "true"
+		secret.Type = corev1.SecretTypeTLS
+		secret.Data = map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key, "ca.crt": ca.Data["ca.crt"]}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	r.GetRecorder().Event(instance, "Normal", "CertificateIssued", fmt.Sprintf("Issued the certificate of secret %s with the CA of the operator", req.SecretName))
+	renewal, _ := appsodyutils.GetCertificateRenewalTime(secret, req, ca.Data[corev1.TLSCertKey])
+	return renewal, nil
+}
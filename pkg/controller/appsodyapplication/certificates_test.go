@@ -0,0 +1,172 @@
+package appsodyapplication
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestOperatorCertificates(t *testing.T) {
+	expose, termination := true, routev1.TLSTerminationReencrypt
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:   stack,
+		Expose:  &expose,
+		Service: &appsodyv1beta1.AppsodyApplicationService{Port: 9443, Certificate: &appsodyv1beta1.Certificate{}},
+		Route: &appsodyv1beta1.AppsodyRoute{
+			Host:        "app.example.com",
+			Termination: &termination,
+			Certificate: &appsodyv1beta1.Certificate{},
+		},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+	// cert-manager is not installed
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	resources := []*metav1.APIResourceList{}
+	for _, list := range discoveryClient.Resources {
+		if list.GroupVersion != certmngrv1alpha2.SchemeGroupVersion.String() {
+			resources = append(resources, list)
+		}
+	}
+	discoveryClient.Resources = resources
+	r.SetDiscoveryClient(discoveryClient)
+
+	// The CA of the operator issues the certificates into the secrets cert-manager would use, and the certificates
+	// are renewed before their renewBefore period
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	ca, svcSecret, routeSecret, route := &corev1.Secret{}, &corev1.Secret{}, &corev1.Secret{}, &routev1.Route{}
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: appsodyutils.CertificateAuthoritySecretName, Namespace: namespace}, ca); err != nil {
+		t.Fatalf("Get CA secret: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-svc-tls", Namespace: namespace}, svcSecret); err != nil {
+		t.Fatalf("Get service certificate secret: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-route-tls", Namespace: namespace}, routeSecret); err != nil {
+		t.Fatalf("Get route certificate secret: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("Get Route: (%v)", err)
+	}
+	issuedTests := []Test{
+		{"service CA", string(ca.Data[corev1.TLSCertKey]), string(svcSecret.Data["ca.crt"])},
+		{"route CA", string(ca.Data[corev1.TLSCertKey]), string(routeSecret.Data["ca.crt"])},
+		{"route certificate", string(routeSecret.Data[corev1.TLSCertKey]), route.Spec.TLS.Certificate},
+		{"route destination CA", string(ca.Data[corev1.TLSCertKey]), route.Spec.TLS.DestinationCACertificate},
+		{"requeue before renewal", true, res.RequeueAfter > 333*24*time.Hour && res.RequeueAfter <= 334*24*time.Hour},
+	}
+	verifyTests("issued", issuedTests, t)
+
+	// A certificate due for renewal is issued again
+	expiring, _, err := appsodyutils.IssueCertificate(appsodyutils.GetServiceCertificateRequest(appsody), ca.Data[corev1.TLSCertKey], ca.Data[corev1.TLSPrivateKeyKey], time.Now().Add(-340*24*time.Hour))
+	if err != nil {
+		t.Fatalf("IssueCertificate: (%v)", err)
+	}
+	svcSecret.Data[corev1.TLSCertKey] = expiring
+	if err = r.GetClient().Update(context.TODO(), svcSecret); err != nil {
+		t.Fatalf("Update service certificate secret: (%v)", err)
+	}
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-svc-tls", Namespace: namespace}, svcSecret); err != nil {
+		t.Fatalf("Get service certificate secret: (%v)", err)
+	}
+
+	// The secret of a certificate that is not requested anymore is deleted
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.Route.Certificate = nil
+	updateAppsody(r, appsody, t)
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	routeErr := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-route-tls", Namespace: namespace}, &corev1.Secret{})
+	renewedTests := []Test{
+		{"renewed", false, string(svcSecret.Data[corev1.TLSCertKey]) == string(expiring)},
+		{"route secret deleted", true, kerrors.IsNotFound(routeErr)},
+	}
+	verifyTests("renewed", renewedTests, t)
+
+	// A CA due for renewal keeps issuing the certificates, while the bundle of the current and the renewed CA is
+	// distributed to the certificate secrets
+	oldCACert, oldCAKey, err := appsodyutils.NewCertificateAuthority(time.Now().Add(-(9*365 + 1) * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority: (%v)", err)
+	}
+	oldCert, oldKey, err := appsodyutils.IssueCertificate(appsodyutils.GetServiceCertificateRequest(appsody), oldCACert, oldCAKey, time.Now())
+	if err != nil {
+		t.Fatalf("IssueCertificate: (%v)", err)
+	}
+	ca.Data = map[string][]byte{corev1.TLSCertKey: oldCACert, corev1.TLSPrivateKeyKey: oldCAKey, "ca.crt": oldCACert}
+	if err = r.GetClient().Update(context.TODO(), ca); err != nil {
+		t.Fatalf("Update CA secret: (%v)", err)
+	}
+	svcSecret.Data = map[string][]byte{corev1.TLSCertKey: oldCert, corev1.TLSPrivateKeyKey: oldKey, "ca.crt": oldCACert}
+	if err = r.GetClient().Update(context.TODO(), svcSecret); err != nil {
+		t.Fatalf("Update service certificate secret: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	getSecrets := func() {
+		ca, svcSecret = &corev1.Secret{}, &corev1.Secret{}
+		if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: appsodyutils.CertificateAuthoritySecretName, Namespace: namespace}, ca); err != nil {
+			t.Fatalf("Get CA secret: (%v)", err)
+		}
+		if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-svc-tls", Namespace: namespace}, svcSecret); err != nil {
+			t.Fatalf("Get service certificate secret: (%v)", err)
+		}
+	}
+	getSecrets()
+	stagedTests := []Test{
+		{"issuing CA", string(oldCACert), string(ca.Data[corev1.TLSCertKey])},
+		{"CA bundle", 2, strings.Count(string(ca.Data["ca.crt"]), "BEGIN CERTIFICATE")},
+		{"certificate kept", string(oldCert), string(svcSecret.Data[corev1.TLSCertKey])},
+		{"distributed bundle", string(ca.Data["ca.crt"]), string(svcSecret.Data["ca.crt"])},
+		{"requeue before rollout", true, res.RequeueAfter > 0 && res.RequeueAfter <= 7*24*time.Hour},
+	}
+	verifyTests("staged CA", stagedTests, t)
+
+	// Once the bundle has been distributed for the rollout period, the renewed CA issues the certificates again
+	nextCACert, nextCAKey, err := appsodyutils.NewCertificateAuthority(time.Now().Add(-8 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority: (%v)", err)
+	}
+	ca.Data["next.crt"], ca.Data["next.key"] = nextCACert, nextCAKey
+	ca.Data["ca.crt"] = append(append([]byte{}, oldCACert...), nextCACert...)
+	if err = r.GetClient().Update(context.TODO(), ca); err != nil {
+		t.Fatalf("Update CA secret: (%v)", err)
+	}
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	getSecrets()
+	_, issuedByNextCA := appsodyutils.GetCertificateRenewalTime(svcSecret, appsodyutils.GetServiceCertificateRequest(appsody), nextCACert)
+	promotedTests := []Test{
+		{"issuing CA", string(nextCACert), string(ca.Data[corev1.TLSCertKey])},
+		{"CA bundle", string(nextCACert) + string(oldCACert), string(ca.Data["ca.crt"])},
+		{"certificate issued again", true, issuedByNextCA},
+		{"distributed bundle", string(ca.Data["ca.crt"]), string(svcSecret.Data["ca.crt"])},
+	}
+	verifyTests("promoted CA", promotedTests, t)
+}
@@ -0,0 +1,178 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// configPollInterval is the interval at which the ConfigMaps and Secrets referenced by an application that are not
+// labelled for config rollout are read again
+const configPollInterval = 5 * time.Minute
+
+// configReader reads the ConfigMaps and Secrets referenced by the applications, and returns whether they are watched
+type configReader interface {
+	GetConfigMap(namespace string, name string) (*corev1.ConfigMap, bool, error)
+	GetSecret(namespace string, name string) (*corev1.Secret, bool, error)
+}
+
+// newConfigInformers returns the informers of the ConfigMaps and Secrets labelled for config rollout, so that the
+// operator doesn't cache all the ConfigMaps and Secrets. There is one informer factory per watched namespace, so that a
+// namespaced install only lists the ConfigMaps and Secrets of the namespaces it is allowed to.
+func newConfigInformers(kubeClient kubernetes.Interface, watchNamespaces []string) map[string]informers.SharedInformerFactory {
+	factories := map[string]informers.SharedInformerFactory{}
+	for _, ns := range watchNamespaces {
+		factories[ns] = informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace(ns), informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = appsodyutils.ConfigRolloutLabel + "=true"
+		}))
+	}
+	return factories
+}
+
+// informerConfigReader reads the ConfigMaps and Secrets labelled for config rollout from their informers, and the
+// other ones from the API server
+type informerConfigReader struct {
+	// informers of the watched namespaces, or of all namespaces under the "" key
+	informers map[string]informers.SharedInformerFactory
+	apiReader client.Reader
+}
+
+// getInformers returns the informer factory of the namespace
+func (c *informerConfigReader) getInformers(namespace string) (informers.SharedInformerFactory, error) {
+	if factory, ok := c.informers[namespace]; ok {
+		return factory, nil
+	}
+	if factory, ok := c.informers[""]; ok {
+		return factory, nil
+	}
+	return nil, fmt.Errorf("the ConfigMaps and Secrets of namespace %s are not watched", namespace)
+}
+
+// GetConfigMap returns the ConfigMap, once the ConfigMaps are synced so that missing ConfigMaps don't roll out the pods
+func (c *informerConfigReader) GetConfigMap(namespace string, name string) (*corev1.ConfigMap, bool, error) {
+	factory, err := c.getInformers(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	informer := factory.Core().V1().ConfigMaps()
+	if !informer.Informer().HasSynced() {
+		return nil, false, fmt.Errorf("waiting for the ConfigMaps labelled %s in namespace %s to be synced", appsodyutils.ConfigRolloutLabel, namespace)
+	}
+	cm, err := informer.Lister().ConfigMaps(namespace).Get(name)
+	if !kerrors.IsNotFound(err) {
+		return cm, err == nil, err
+	}
+	cm = &corev1.ConfigMap{}
+	if err = c.apiReader.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, cm); err != nil {
+		return nil, false, err
+	}
+	return cm, false, nil
+}
+
+// GetSecret returns the Secret, once the Secrets are synced so that missing Secrets don't roll out the pods
+func (c *informerConfigReader) GetSecret(namespace string, name string) (*corev1.Secret, bool, error) {
+	factory, err := c.getInformers(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	informer := factory.Core().V1().Secrets()
+	if !informer.Informer().HasSynced() {
+		return nil, false, fmt.Errorf("waiting for the Secrets labelled %s in namespace %s to be synced", appsodyutils.ConfigRolloutLabel, namespace)
+	}
+	secret, err := informer.Lister().Secrets(namespace).Get(name)
+	if !kerrors.IsNotFound(err) {
+		return secret, err == nil, err
+	}
+	secret = &corev1.Secret{}
+	if err = c.apiReader.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return nil, false, err
+	}
+	return secret, false, nil
+}
+
+// getConfigHash returns a hash of the ConfigMaps and Secrets referenced by the application containers, or "" when
+// config rollout is disabled or nothing is referenced. Missing ConfigMaps and Secrets are left out, so that their
+// creation also rolls out the pods. It returns the interval at which the ConfigMaps and Secrets that are not watched
+// must be polled, or zero if they are all watched.
+func (r *ReconcileAppsodyApplication) getConfigHash(instance *appsodyv1beta1.AppsodyApplication) (string, time.Duration, error) {
+	configMapNames, secretNames := appsodyutils.GetConfigRolloutReferences(instance)
+	if len(configMapNames) == 0 && len(secretNames) == 0 {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeConfigWatched)
+		return "", 0, nil
+	}
+
+	configMaps, polledConfigMaps := []corev1.ConfigMap{}, []string{}
+	for _, name := range configMapNames {
+		cm, watched, err := r.configReader.GetConfigMap(instance.Namespace, name)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return "", 0, err
+		}
+		if err == nil {
+			configMaps = append(configMaps, *cm)
+			if !watched {
+				polledConfigMaps = append(polledConfigMaps, name)
+			}
+		}
+	}
+	secrets, polledSecrets := []corev1.Secret{}, []string{}
+	for _, name := range secretNames {
+		secret, watched, err := r.configReader.GetSecret(instance.Namespace, name)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return "", 0, err
+		}
+		if err == nil {
+			secrets = append(secrets, *secret)
+			if !watched {
+				polledSecrets = append(polledSecrets, name)
+			}
+		}
+	}
+
+	setConfigWatchedCondition(instance, polledConfigMaps, polledSecrets)
+	if len(polledConfigMaps) > 0 || len(polledSecrets) > 0 {
+		return appsodyutils.HashConfig(configMaps, secrets), configPollInterval, nil
+	}
+	return appsodyutils.HashConfig(configMaps, secrets), 0, nil
+}
+
+// setConfigWatchedCondition sets the ConfigWatched condition to false when ConfigMaps or Secrets referenced by the
+// application are not labelled for config rollout, and are polled instead of watched
+func setConfigWatchedCondition(instance *appsodyv1beta1.AppsodyApplication, configMaps []string, secrets []string) {
+	condition := &appsodyv1beta1.StatusCondition{Type: appsodyv1beta1.StatusConditionTypeConfigWatched, Status: corev1.ConditionTrue}
+	if len(configMaps) > 0 || len(secrets) > 0 {
+		polled := []string{}
+		if len(configMaps) > 0 {
+			polled = append(polled, "ConfigMaps "+strings.Join(configMaps, ", "))
+		}
+		if len(secrets) > 0 {
+			polled = append(polled, "Secrets "+strings.Join(secrets, ", "))
+		}
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "ConfigNotLabelled"
+		condition.Message = fmt.Sprintf("%s are not labelled %s=true, their changes roll out the pods within %v", strings.Join(polled, " and "), appsodyutils.ConfigRolloutLabel, configPollInterval)
+	}
+	instance.Status.SetCondition(condition)
+}
+
+// setConfigHashAnnotation sets the config hash annotation of the pod template, or removes it when hash is empty
+func setConfigHashAnnotation(meta *metav1.ObjectMeta, hash string) {
+	if hash == "" {
+		delete(meta.Annotations, appsodyutils.ConfigHashAnnotation)
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[appsodyutils.ConfigHashAnnotation] = hash
+}
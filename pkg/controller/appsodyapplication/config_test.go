@@ -0,0 +1,205 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestConfigRollout(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:            stack,
+		ApplicationImage: appImage,
+		EnvFrom:          []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}}}},
+		Env: []corev1.EnvVar{{Name: "FEATURES", ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "feature-flags"}, Key: "features"},
+		}}},
+		Volumes: []corev1.Volume{{Name: "logging", VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "logging-config"}},
+		}}},
+		ConfigRollout: &appsodyv1beta1.AppsodyConfigRollout{ExcludeConfigMaps: []string{"logging-config"}},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+	appConfig := createConfigMap("app-config", namespace, map[string]string{"LOG_LEVEL": "info"})
+	loggingConfig := createConfigMap("logging-config", namespace, map[string]string{"format": "json"})
+	// The ConfigMaps that are not labelled for config rollout are polled instead of watched
+	featureFlags := createConfigMap("feature-flags", namespace, map[string]string{"features": "a"})
+	for _, cm := range []*corev1.ConfigMap{appConfig, loggingConfig} {
+		cm.Labels = map[string]string{appsodyutils.ConfigRolloutLabel: "true"}
+	}
+
+	r := newTestReconciler(t, appsody, appConfig, loggingConfig, featureFlags)
+
+	req := createReconcileRequest(name, namespace)
+	var res reconcile.Result
+	getConfigHash := func() string {
+		var err error
+		if res, err = r.Reconcile(req); err != nil {
+			t.Fatalf("Reconcile: (%v)", err)
+		}
+		dep := &appsv1.Deployment{}
+		if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+			t.Fatalf("Get Deployment: (%v)", err)
+		}
+		return dep.Spec.Template.Annotations[appsodyutils.ConfigHashAnnotation]
+	}
+
+	hash := getConfigHash()
+	if hash == "" {
+		t.Fatalf("Config hash annotation is not set on the pod template")
+	}
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeConfigWatched))
+	pollTests := []Test{
+		{"config watched", corev1.ConditionFalse, condition.GetStatus()},
+		{"config watched reason", "ConfigNotLabelled", condition.GetReason()},
+		{"unlabelled ConfigMap reported", true, strings.Contains(condition.GetMessage(), "ConfigMaps feature-flags ")},
+		{"requeue after", configPollInterval, res.RequeueAfter},
+	}
+	verifyTests("unlabelled ConfigMap", pollTests, t)
+
+	loggingConfig.Data["format"] = "text"
+	if err := r.GetClient().Update(context.TODO(), loggingConfig); err != nil {
+		t.Fatalf("Update ConfigMap: (%v)", err)
+	}
+	verifyTests("excluded ConfigMap", []Test{{"config hash", hash, getConfigHash()}}, t)
+
+	featureFlags.Data["features"] = "b"
+	if err := r.GetClient().Update(context.TODO(), featureFlags); err != nil {
+		t.Fatalf("Update ConfigMap: (%v)", err)
+	}
+	newHash := getConfigHash()
+	if newHash == hash || newHash == "" {
+		t.Errorf("Config hash annotation expected to change with the unlabelled ConfigMap, actual: (%v)", newHash)
+	}
+	hash = newHash
+
+	appConfig.Data["LOG_LEVEL"] = "debug"
+	if err := r.GetClient().Update(context.TODO(), appConfig); err != nil {
+		t.Fatalf("Update ConfigMap: (%v)", err)
+	}
+	if newHash := getConfigHash(); newHash == hash || newHash == "" {
+		t.Errorf("Config hash annotation expected to change, actual: (%v)", newHash)
+	}
+
+	// The condition is true once all the ConfigMaps are labelled
+	featureFlags.Labels = map[string]string{appsodyutils.ConfigRolloutLabel: "true"}
+	if err := r.GetClient().Update(context.TODO(), featureFlags); err != nil {
+		t.Fatalf("Update ConfigMap: (%v)", err)
+	}
+	getConfigHash()
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeConfigWatched))
+	watchTests := []Test{
+		{"config watched", corev1.ConditionTrue, condition.GetStatus()},
+		{"requeue after", time.Duration(0), res.RequeueAfter},
+	}
+	verifyTests("labelled ConfigMaps", watchTests, t)
+
+	// Config rollout is enabled by default
+	appsody.Spec.ConfigRollout = nil
+	if err := r.GetClient().Update(context.TODO(), appsody); err != nil {
+		t.Fatalf("Update appsody: (%v)", err)
+	}
+	if hash = getConfigHash(); hash == "" {
+		t.Errorf("Config hash annotation expected to be set by default")
+	}
+
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	enabled := false
+	appsody.Spec.ConfigRollout = &appsodyv1beta1.AppsodyConfigRollout{Enabled: &enabled}
+	if err := r.GetClient().Update(context.TODO(), appsody); err != nil {
+		t.Fatalf("Update appsody: (%v)", err)
+	}
+	verifyTests("disabled config rollout", []Test{{"config hash", "", getConfigHash()}}, t)
+}
+
+func TestConfigInformersNamespaced(t *testing.T) {
+	// The API server only allows listing the ConfigMaps and Secrets of the watched namespaces, like the Role of a
+	// namespaced install
+	watchNamespaces := []string{namespace, "other"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		query := r.URL.Query()
+		switch {
+		case query.Get("labelSelector") != appsodyutils.ConfigRolloutLabel+"=true":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.URL.Path == "/api/v1/namespaces/"+namespace+"/configmaps" && query.Get("watch") != "true":
+			fmt.Fprintf(w, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"resourceVersion":"1"},"items":[{"metadata":{"name":"app-config","namespace":"%s","resourceVersion":"1","labels":{"%s":"true"}},"data":{"LOG_LEVEL":"info"}}]}`, namespace, appsodyutils.ConfigRolloutLabel)
+		case r.URL.Path == "/api/v1/namespaces/other/configmaps" && query.Get("watch") != "true":
+			fmt.Fprint(w, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"resourceVersion":"1"},"items":[]}`)
+		case (r.URL.Path == "/api/v1/namespaces/"+namespace+"/secrets" || r.URL.Path == "/api/v1/namespaces/other/secrets") && query.Get("watch") != "true":
+			fmt.Fprint(w, `{"kind":"SecretList","apiVersion":"v1","metadata":{"resourceVersion":"1"},"items":[]}`)
+		case strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/"+namespace+"/") || strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/other/"):
+			// Watches stay open until the informers are stopped
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+		}
+	}))
+	defer server.Close()
+
+	unlabelled := createConfigMap("feature-flags", namespace, map[string]string{"features": "a"})
+	factories := newConfigInformers(kubernetes.NewForConfigOrDie(&rest.Config{Host: server.URL}), watchNamespaces)
+	reader := &informerConfigReader{informers: factories, apiReader: fakeclient.NewFakeClient(unlabelled)}
+	stop := make(chan struct{})
+	defer close(stop)
+	for _, factory := range factories {
+		factory.Core().V1().ConfigMaps().Informer()
+		factory.Core().V1().Secrets().Informer()
+		factory.Start(stop)
+	}
+	timeout := make(chan struct{})
+	timer := time.AfterFunc(10*time.Second, func() { close(timeout) })
+	defer timer.Stop()
+	for ns, factory := range factories {
+		for informer, synced := range factory.WaitForCacheSync(timeout) {
+			if !synced {
+				t.Fatalf("Informer %v of namespace %s expected to be synced", informer, ns)
+			}
+		}
+	}
+
+	cm, watched, err := reader.GetConfigMap(namespace, "app-config")
+	if err != nil {
+		t.Fatalf("Get labelled ConfigMap: (%v)", err)
+	}
+	polled, polledWatched, err := reader.GetConfigMap(namespace, "feature-flags")
+	if err != nil {
+		t.Fatalf("Get unlabelled ConfigMap: (%v)", err)
+	}
+	_, _, missingErr := reader.GetSecret("other", "app-secret")
+	_, _, unwatchedErr := reader.GetConfigMap("unwatched", "app-config")
+	informerTests := []Test{
+		{"labelled ConfigMap", "info", cm.Data["LOG_LEVEL"]},
+		{"labelled ConfigMap watched", true, watched},
+		{"unlabelled ConfigMap", "a", polled.Data["features"]},
+		{"unlabelled ConfigMap watched", false, polledWatched},
+		{"missing Secret", true, kerrors.IsNotFound(missingErr)},
+		{"unwatched namespace", true, unwatchedErr != nil},
+	}
+	verifyTests("namespaced config informers", informerTests, t)
+}
@@ -23,6 +23,8 @@ var _ handler.EventHandler = &EnqueueRequestsForCustomIndexField{}
 const (
 	indexFieldImageStreamName     = "spec.applicationImage"
 	indexFieldBindingsResourceRef = "spec.bindings.resourceRef"
+	indexFieldConfigMaps          = "spec.configMaps"
+	indexFieldSecrets             = "spec.secrets"
 	bindingSecretSuffix           = "-binding"
 )
 
@@ -136,3 +138,22 @@ func (b *BindingSecretMatcher) Match(secret metav1.Object) ([]appsodyv1beta1.App
 
 	return apps, nil
 }
+
+// ConfigMatcher implements CustomMatcher for the ConfigMaps and Secrets referenced by the application containers
+type ConfigMatcher struct {
+	klient     client.Client
+	indexField string
+}
+
+// Match returns all applications whose pods roll out when the ConfigMap or Secret changes
+func (c *ConfigMatcher) Match(obj metav1.Object) ([]appsodyv1beta1.AppsodyApplication, error) {
+	appList := &appsodyv1beta1.AppsodyApplicationList{}
+	err := c.klient.List(context.Background(),
+		appList,
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{c.indexField: obj.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	return appList.Items, nil
+}
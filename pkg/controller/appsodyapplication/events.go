@@ -0,0 +1,138 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// eventKinds lists the Knative Eventing kinds generated for the events of the applications, with their API group
+// and versions from the newest
+var eventKinds = []struct {
+	kind     string
+	group    string
+	versions []string
+}{
+	{"Trigger", appsodyutils.EventingGroup, []string{"v1", "v1beta1"}},
+	{"PingSource", appsodyutils.SourcesGroup, []string{"v1", "v1beta2"}},
+	{"ApiServerSource", appsodyutils.SourcesGroup, []string{"v1", "v1alpha2"}},
+}
+
+// getEventAPIVersion returns the newest API version of the Knative Eventing kind supported on the cluster, or an
+// empty string
+func (r *ReconcileAppsodyApplication) getEventAPIVersion(kind string) string {
+	for _, k := range eventKinds {
+		if k.kind != kind {
+			continue
+		}
+		for _, version := range k.versions {
+			apiVersion := k.group + "/" + version
+			if ok, _ := r.IsGroupVersionSupported(apiVersion, kind); ok {
+				return apiVersion
+			}
+		}
+	}
+	return ""
+}
+
+// reconcileEvents creates or updates the Triggers and the sources of the events of the application, with its
+// Knative Service or its service as the sink, deletes the ones that are not requested anymore, and sets the
+// EventsReady condition from their readiness
+func (r *ReconcileAppsodyApplication) reconcileEvents(instance *appsodyv1beta1.AppsodyApplication, knativeAPIVersion string) error {
+	desired := map[string]map[string]func(*unstructured.Unstructured){}
+	for _, k := range eventKinds {
+		desired[k.kind] = map[string]func(*unstructured.Unstructured){}
+	}
+	if events := instance.Spec.Events; events != nil {
+		sink := appsodyutils.GetEventSink(instance, knativeAPIVersion)
+		for i := range events.Triggers {
+			trigger := events.Triggers[i]
+			desired["Trigger"][appsodyutils.GetEventTriggerName(instance, trigger)] = func(obj *unstructured.Unstructured) {
+				appsodyutils.CustomizeEventTrigger(obj, instance, trigger, sink)
+			}
+		}
+		for i := range events.Sources {
+			source := events.Sources[i]
+			kind := appsodyutils.GetEventSourceKind(source)
+			if kind == "" {
+				return fmt.Errorf("event source %s of the application must set ping or apiServer", source.Name)
+			}
+			desired[kind][appsodyutils.GetEventSourceName(instance, source)] = func(obj *unstructured.Unstructured) {
+				appsodyutils.CustomizeEventSource(obj, instance, source, sink)
+			}
+		}
+	}
+
+	notReady := []string{}
+	for _, k := range eventKinds {
+		apiVersion := r.getEventAPIVersion(k.kind)
+		if apiVersion == "" {
+			if len(desired[k.kind]) > 0 {
+				return fmt.Errorf("failed to reconcile the events of the application as the operator could not find the Knative Eventing %s CRD", k.kind)
+			}
+			continue
+		}
+
+		for name, customize := range desired[k.kind] {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(apiVersion)
+			obj.SetKind(k.kind)
+			obj.SetName(name)
+			obj.SetNamespace(instance.Namespace)
+			customize := customize
+			err := r.CreateOrUpdate(obj, instance, func() error {
+				customize(obj)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if ready, message := appsodyutils.IsEventResourceReady(obj); !ready {
+				if message != "" {
+					name = fmt.Sprintf("%s (%s)", name, message)
+				}
+				notReady = append(notReady, k.kind+" "+name)
+			}
+		}
+
+		// Delete the Triggers and the sources of the application that are not requested anymore
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion(apiVersion)
+		list.SetKind(k.kind + "List")
+		err := r.GetClient().List(context.TODO(), list, client.InNamespace(instance.Namespace), client.MatchingLabels{"app.kubernetes.io/instance": instance.Name})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if _, ok := desired[k.kind][obj.GetName()]; ok || !metav1.IsControlledBy(obj, instance) {
+				continue
+			}
+			if err = r.DeleteResource(obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	if instance.Spec.Events == nil {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeEventsReady)
+		return nil
+	}
+	condition := &appsodyv1beta1.StatusCondition{Type: appsodyv1beta1.StatusConditionTypeEventsReady, Status: corev1.ConditionTrue}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "NotReady"
+		condition.Message = "Waiting for " + strings.Join(notReady, ", ")
+	}
+	instance.Status.SetCondition(condition)
+	return nil
+}
@@ -0,0 +1,109 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestEvents(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack: stack,
+		Events: &appsodyv1beta1.AppsodyEvents{
+			Triggers: []appsodyv1beta1.AppsodyEventTrigger{{Name: "created", Filter: map[string]string{"type": "order.created"}}},
+			Sources:  []appsodyv1beta1.AppsodyEventSource{{Name: "tick", Ping: &appsodyv1beta1.AppsodyPingSource{Schedule: "* * * * *"}}},
+		},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	eventingGV := schema.GroupVersion{Group: appsodyutils.EventingGroup, Version: "v1"}
+	sourcesGV := schema.GroupVersion{Group: appsodyutils.SourcesGroup, Version: "v1"}
+	addUnstructuredKinds(eventingGV, "Trigger")
+	addUnstructuredKinds(sourcesGV, "PingSource")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources,
+		&metav1.APIResourceList{
+			GroupVersion: eventingGV.String(),
+			APIResources: []metav1.APIResource{{Name: "triggers", Namespaced: true, Kind: "Trigger", SingularName: "trigger"}},
+		},
+		&metav1.APIResourceList{
+			GroupVersion: sourcesGV.String(),
+			APIResources: []metav1.APIResource{{Name: "pingsources", Namespaced: true, Kind: "PingSource", SingularName: "pingsource"}},
+		},
+	)
+	r.SetDiscoveryClient(discoveryClient)
+
+	// The Trigger and the PingSource deliver the events to the service of the application
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	triggerName := types.NamespacedName{Name: name + "-created", Namespace: namespace}
+	trigger := &unstructured.Unstructured{}
+	trigger.SetGroupVersionKind(eventingGV.WithKind("Trigger"))
+	if err = r.GetClient().Get(context.TODO(), triggerName, trigger); err != nil {
+		t.Fatalf("Get Trigger: (%v)", err)
+	}
+	ping := &unstructured.Unstructured{}
+	ping.SetGroupVersionKind(sourcesGV.WithKind("PingSource"))
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-tick", Namespace: namespace}, ping); err != nil {
+		t.Fatalf("Get PingSource: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	subscriber, _, _ := unstructured.NestedString(trigger.Object, "spec", "subscriber", "uri")
+	sink, _, _ := unstructured.NestedString(ping.Object, "spec", "sink", "uri")
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeEventsReady))
+	if condition == nil {
+		t.Fatalf("EventsReady condition expected to be set")
+	}
+	eventsTests := []Test{
+		{"subscriber", fmt.Sprintf("http://%s.%s.svc.cluster.local:%d/", name, namespace, service.Port), subscriber},
+		{"sink", subscriber, sink},
+		{"events not ready", corev1.ConditionFalse, condition.GetStatus()},
+	}
+	verifyTests("events", eventsTests, t)
+
+	// The EventsReady condition is true once the Trigger and the PingSource are ready
+	for _, obj := range []*unstructured.Unstructured{trigger, ping} {
+		obj.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		}
+		if err = r.GetClient().Update(context.TODO(), obj); err != nil {
+			t.Fatalf("Update %s status: (%v)", obj.GetKind(), err)
+		}
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeEventsReady))
+	if condition == nil || condition.GetStatus() != corev1.ConditionTrue {
+		t.Fatalf("EventsReady condition expected to be true, actual: (%v)", condition)
+	}
+
+	// The Trigger is deleted when it is removed from the events of the application
+	appsody.Spec.Events.Triggers = nil
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), triggerName, trigger); !kerrors.IsNotFound(err) {
+		t.Fatalf("Trigger expected to be deleted, actual error: (%v)", err)
+	}
+}
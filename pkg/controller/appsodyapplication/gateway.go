@@ -0,0 +1,106 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// getHTTPRouteAPIVersion returns the newest HTTPRoute API version supported on the cluster, or an empty string
+func (r *ReconcileAppsodyApplication) getHTTPRouteAPIVersion() string {
+	for _, version := range []string{"v1", "v1beta1"} {
+		apiVersion := appsodyutils.GatewayGroup + "/" + version
+		if ok, _ := r.IsGroupVersionSupported(apiVersion, "HTTPRoute"); ok {
+			return apiVersion
+		}
+	}
+	return ""
+}
+
+// reconcileHTTPRoute creates or updates the HTTPRoute of the application when it is exposed through a Gateway, and
+// deletes it otherwise. It returns true when the application is exposed with the HTTPRoute, in which case its Route
+// or Ingress must be deleted.
+func (r *ReconcileAppsodyApplication) reconcileHTTPRoute(instance *appsodyv1beta1.AppsodyApplication) (bool, error) {
+	apiVersion := r.getHTTPRouteAPIVersion()
+	expose := instance.Spec.Expose != nil && *instance.Spec.Expose && (instance.Spec.CreateKnativeService == nil || !*instance.Spec.CreateKnativeService)
+	gateway, err := appsodyutils.GetGatewayReference(instance)
+	if err != nil {
+		return false, err
+	}
+
+	if apiVersion == "" {
+		if expose && instance.Spec.Route != nil && instance.Spec.Route.Gateway != nil {
+			return false, errors.New("failed to reconcile HTTPRoute as the operator could not find Gateway API CRDs")
+		}
+		return false, nil
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetAPIVersion(apiVersion)
+	route.SetKind("HTTPRoute")
+	route.SetName(instance.Name)
+	route.SetNamespace(instance.Namespace)
+
+	if !expose || gateway == nil {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeGatewayTLSReady)
+		return false, r.DeleteResource(route)
+	}
+	if err := r.resolveGatewayTLSListener(instance, apiVersion, gateway); err != nil {
+		return false, err
+	}
+	err = r.CreateOrUpdate(route, instance, func() error {
+		appsodyutils.CustomizeHTTPRoute(route, instance, gateway)
+		return nil
+	})
+	return err == nil, err
+}
+
+// resolveGatewayTLSListener attaches the HTTPRoute to the HTTPS listener of the Gateway that serves the TLS secret of
+// the application, and sets the GatewayTLSReady condition to false, recording a warning event, when there is none.
+// The condition is removed when the application has no TLS secret.
+func (r *ReconcileAppsodyApplication) resolveGatewayTLSListener(instance *appsodyv1beta1.AppsodyApplication, apiVersion string, gateway *appsodyv1beta1.AppsodyGatewayReference) error {
+	secretName := appsodyutils.GetRouteTLSSecretName(instance)
+	if secretName == "" {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeGatewayTLSReady)
+		return nil
+	}
+
+	gw := &unstructured.Unstructured{}
+	gw.SetAPIVersion(apiVersion)
+	gw.SetKind("Gateway")
+	condition := &appsodyv1beta1.StatusCondition{Type: appsodyv1beta1.StatusConditionTypeGatewayTLSReady, Status: corev1.ConditionTrue}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace}, gw)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	if kerrors.IsNotFound(err) {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "GatewayNotFound"
+		condition.Message = fmt.Sprintf("Gateway %s/%s is not found", gateway.Namespace, gateway.Name)
+	} else if listener, reason := appsodyutils.GetGatewayTLSListener(gw, gateway.SectionName, instance.Namespace, secretName); listener != "" {
+		gateway.SectionName = listener
+	} else {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = reason
+		condition.Message = fmt.Sprintf("Gateway %s/%s has no HTTPS listener serving TLS secret %s/%s", gateway.Namespace, gateway.Name, instance.Namespace, secretName)
+		if gateway.SectionName != "" {
+			condition.Message = fmt.Sprintf("Listener %s of Gateway %s/%s is not an HTTPS listener serving TLS secret %s/%s", gateway.SectionName, gateway.Namespace, gateway.Name, instance.Namespace, secretName)
+		}
+	}
+	if condition.Status == corev1.ConditionFalse {
+		old := instance.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeGatewayTLSReady))
+		if old == nil || old.GetStatus() != corev1.ConditionFalse || old.GetMessage() != condition.Message {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+	}
+	instance.Status.SetCondition(condition)
+	return nil
+}
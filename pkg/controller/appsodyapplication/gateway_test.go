@@ -0,0 +1,166 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestHTTPRoute(t *testing.T) {
+	expose := true
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:  stack,
+		Expose: &expose,
+		Route: &appsodyv1beta1.AppsodyRoute{
+			Host:    "app.example.com",
+			Gateway: &appsodyv1beta1.AppsodyGatewayReference{Name: "gateway", SectionName: "https"},
+		},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	gatewayGV := schema.GroupVersion{Group: appsodyutils.GatewayGroup, Version: "v1"}
+	addUnstructuredKinds(gatewayGV, "HTTPRoute")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: gatewayGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "httproutes", Namespaced: true, Kind: "HTTPRoute", SingularName: "httproute"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	// The application is exposed with an HTTPRoute instead of a Route
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(gatewayGV.WithKind("HTTPRoute"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("Get HTTPRoute: (%v)", err)
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	parentRef, _ := parentRefs[0].(map[string]interface{})
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	err = r.GetClient().Get(context.TODO(), req.NamespacedName, &routev1.Route{})
+	httpRouteTests := []Test{
+		{"gateway", namespace + "/gateway/https", fmt.Sprintf("%v/%v/%v", parentRef["namespace"], parentRef["name"], parentRef["sectionName"])},
+		{"hostnames", "app.example.com", strings.Join(hostnames, ",")},
+		{"route", true, kerrors.IsNotFound(err)},
+	}
+	verifyTests("http route", httpRouteTests, t)
+
+	// The HTTPRoute is deleted when the application is not exposed anymore
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	expose = false
+	appsody.Spec.Expose = &expose
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, route); !kerrors.IsNotFound(err) {
+		t.Fatalf("HTTPRoute of an application that is not exposed expected to be deleted, actual error: (%v)", err)
+	}
+}
+
+func TestHTTPRouteTLS(t *testing.T) {
+	expose := true
+	secretName := "app-tls"
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:  stack,
+		Expose: &expose,
+		Route: &appsodyv1beta1.AppsodyRoute{
+			Host:                 "app.example.com",
+			CertificateSecretRef: &secretName,
+			Gateway:              &appsodyv1beta1.AppsodyGatewayReference{Name: "gateway"},
+		},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	gatewayGV := schema.GroupVersion{Group: appsodyutils.GatewayGroup, Version: "v1"}
+	addUnstructuredKinds(gatewayGV, "HTTPRoute", "Gateway")
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)},
+			},
+		},
+	}}
+	gateway.SetGroupVersionKind(gatewayGV.WithKind("Gateway"))
+	gateway.SetName("gateway")
+	gateway.SetNamespace(namespace)
+	r := newTestReconciler(t, appsody, gateway)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: gatewayGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "httproutes", Namespaced: true, Kind: "HTTPRoute", SingularName: "httproute"},
+			{Name: "gateways", Namespaced: true, Kind: "Gateway", SingularName: "gateway"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	// The Gateway has no listener to terminate the TLS of the application
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeGatewayTLSReady))
+	if condition == nil || condition.GetStatus() != corev1.ConditionFalse || condition.GetReason() != appsodyutils.GatewayListenerNotTLS {
+		t.Fatalf("GatewayTLSReady condition expected to be false with reason %s, actual: (%v)", appsodyutils.GatewayListenerNotTLS, condition)
+	}
+
+	// The HTTPRoute is attached to the HTTPS listener serving the TLS secret of the application
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: "gateway", Namespace: namespace}, gateway); err != nil {
+		t.Fatalf("Get Gateway: (%v)", err)
+	}
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	listeners = append(listeners, map[string]interface{}{
+		"name": "https", "protocol": "HTTPS", "port": int64(443),
+		"tls": map[string]interface{}{
+			"certificateRefs": []interface{}{map[string]interface{}{"name": secretName}},
+		},
+	})
+	unstructured.SetNestedSlice(gateway.Object, listeners, "spec", "listeners")
+	if err = r.GetClient().Update(context.TODO(), gateway); err != nil {
+		t.Fatalf("Update Gateway: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(gatewayGV.WithKind("HTTPRoute"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("Get HTTPRoute: (%v)", err)
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	parentRef, _ := parentRefs[0].(map[string]interface{})
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeGatewayTLSReady))
+	tlsTests := []Test{
+		{"section name", "https", parentRef["sectionName"]},
+		{"condition", corev1.ConditionTrue, condition.GetStatus()},
+	}
+	verifyTests("http route tls", tlsTests, t)
+}
@@ -0,0 +1,327 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// registryClient is used to resolve image digests and list image tags from image registries
+var registryClient = &http.Client{Timeout: 10 * time.Second}
+
+// reconcileImageUpdate polls the registry of the application image for the newest tag allowed by the image update
+// policy, and deploys it through `status.imageReference` in Auto mode. The registry is queried through the image
+// rewrite rules. It returns the time until the next poll.
+func (r *ReconcileAppsodyApplication) reconcileImageUpdate(instance *appsodyv1beta1.AppsodyApplication, now time.Time, rewriteRules []appsodyutils.ImageRewriteRule) time.Duration {
+	policy := instance.Spec.ImageUpdatePolicy
+	// Images referenced by digest and images resolved from an ImageStream are not polled
+	if policy == nil || strings.Contains(instance.Spec.ApplicationImage, "@") || instance.Status.ImageReference != instance.Spec.ApplicationImage {
+		instance.Status.ImageUpdate = nil
+		return 0
+	}
+
+	status := instance.Status.ImageUpdate
+	if status == nil {
+		status = &appsodyv1beta1.StatusImageUpdate{}
+		instance.Status.ImageUpdate = status
+	}
+	// Poll again right away when the application image or the policy no longer allow the latest image
+	if status.LatestImage != "" && !appsodyutils.IsImageUpdateAllowed(status.LatestImage, instance.Spec.ApplicationImage, policy) {
+		status.LatestImage = ""
+		status.LastPollTime = nil
+	}
+
+	if status.LastPollTime == nil || !now.Before(status.LastPollTime.Add(policy.GetPollInterval())) {
+		status.LastPollTime = &metav1.Time{Time: now}
+		latestImage, err := r.pollImageUpdate(instance, rewriteRules)
+		if err != nil {
+			log.Error(err, "Failed to poll the registry for image updates", "Request.Namespace", instance.Namespace, "Request.Name", instance.Name)
+			r.GetRecorder().Event(instance, "Warning", "ImageUpdateFailed", fmt.Sprintf("Failed to poll the registry for image updates: %v", err))
+		} else if latestImage != status.LatestImage {
+			if latestImage != "" && latestImage != instance.Spec.ApplicationImage {
+				if policy.GetMode() == appsodyv1beta1.ImageUpdateModeAuto {
+					r.GetRecorder().Event(instance, "Normal", "ImageUpdated", fmt.Sprintf("Updating the application image to %s", latestImage))
+				} else {
+					r.GetRecorder().Event(instance, "Normal", "ImageUpdateAvailable", fmt.Sprintf("Image %s is available", latestImage))
+				}
+			}
+			status.LatestImage = latestImage
+		}
+	}
+
+	if status.LatestImage != "" && policy.GetMode() == appsodyv1beta1.ImageUpdateModeAuto {
+		instance.Status.ImageReference = status.LatestImage
+	}
+	return status.LastPollTime.Add(policy.GetPollInterval()).Sub(now)
+}
+
+func (r *ReconcileAppsodyApplication) pollImageUpdate(instance *appsodyv1beta1.AppsodyApplication, rewriteRules []appsodyutils.ImageRewriteRule) (string, error) {
+	pullSecret, err := r.getPullSecret(instance)
+	if err != nil {
+		return "", err
+	}
+	tags, err := appsodyutils.ListImageTags(registryClient, appsodyutils.RewriteImage(instance.Spec.ApplicationImage, rewriteRules), pullSecret)
+	if err != nil {
+		return "", err
+	}
+	return appsodyutils.SelectImageUpdate(instance.Spec.ApplicationImage, tags, instance.Spec.ImageUpdatePolicy)
+}
+
+// rewriteImages applies the image rewrite rules to `status.imageReference`, and records the original images of the
+// application, init and sidecar containers whose image is rewritten
+func rewriteImages(instance *appsodyv1beta1.AppsodyApplication, rewriteRules []appsodyutils.ImageRewriteRule) {
+	instance.Status.RewrittenImages = nil
+	rewrite := func(container string, image string) string {
+		rewritten := appsodyutils.RewriteImage(image, rewriteRules)
+		if rewritten != image {
+			instance.Status.RewrittenImages = append(instance.Status.RewrittenImages, appsodyv1beta1.StatusRewrittenImage{
+				Container:     container,
+				OriginalImage: image,
+				Image:         rewritten,
+			})
+		}
+		return rewritten
+	}
+	instance.Status.ImageReference = rewrite("app", instance.Status.ImageReference)
+	for _, c := range instance.Spec.InitContainers {
+		rewrite(c.Name, c.Image)
+	}
+	for _, c := range instance.Spec.SidecarContainers {
+		rewrite(c.Name, c.Image)
+	}
+}
+
+// pinImageDigest sets `status.imageReference` to the digest its tag resolves to in the registry, when `spec.imagePinning`
+// is Digest or force is true. The digest is resolved once per image, so that pods keep running the same image when
+// the tag moves.
+func (r *ReconcileAppsodyApplication) pinImageDigest(instance *appsodyv1beta1.AppsodyApplication, imageReferenceOld string, force bool) error {
+	image := instance.Status.ImageReference
+	// Images referenced by digest, such as images resolved from an ImageStream, are already pinned
+	if (instance.Spec.ImagePinning != appsodyv1beta1.ImagePinningDigest && !force) || strings.Contains(image, "@") {
+		instance.Status.PinnedImage = ""
+		return nil
+	}
+	if instance.Status.PinnedImage == image && strings.Contains(imageReferenceOld, "@") {
+		instance.Status.ImageReference = imageReferenceOld
+		return nil
+	}
+
+	pullSecret, err := r.getPullSecret(instance)
+	if err != nil {
+		return err
+	}
+	pinned, err := appsodyutils.ResolveImageDigest(registryClient, image, pullSecret)
+	if err != nil {
+		return err
+	}
+	instance.Status.ImageReference = pinned
+	instance.Status.PinnedImage = image
+	return nil
+}
+
+// getImagePolicies returns the image policies that apply to the application, sorted by name
+func (r *ReconcileAppsodyApplication) getImagePolicies(instance *appsodyv1beta1.AppsodyApplication) []*appsodyutils.ImagePolicy {
+	names := []string{}
+	for name, policy := range r.ImagePolicies {
+		if policy.AppliesTo(instance.Namespace, instance.GetStack()) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	policies := []*appsodyutils.ImagePolicy{}
+	for _, name := range names {
+		policies = append(policies, r.ImagePolicies[name])
+	}
+	return policies
+}
+
+// verifyImageSignature checks that the image in `status.imageReference` is signed with a public key of each of the
+// image policies. The ImageVerified condition reports the result. An image that is already deployed is checked again
+// only when the image policies change. An invalid policy fails the verification.
+func (r *ReconcileAppsodyApplication) verifyImageSignature(instance *appsodyv1beta1.AppsodyApplication, policies []*appsodyutils.ImagePolicy, imageReferenceOld string) error {
+	if len(policies) == 0 {
+		instance.Status.VerifiedImagePolicy = ""
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeImageVerified)
+		return nil
+	}
+	old := instance.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeImageVerified))
+	if instance.Status.ImageReference == imageReferenceOld && old != nil && old.GetStatus() == corev1.ConditionTrue &&
+		instance.Status.VerifiedImagePolicy == r.lastImagePolicyRV {
+		return nil
+	}
+
+	reason := "VerificationFailed"
+	var err error
+	for _, policy := range policies {
+		if err = policy.Err(); err != nil {
+			reason = "InvalidPolicy"
+			break
+		}
+	}
+	if err == nil {
+		var pullSecret *corev1.Secret
+		pullSecret, err = r.getPullSecret(instance)
+		for _, policy := range policies {
+			if err != nil {
+				break
+			}
+			err = appsodyutils.VerifyImageSignature(registryClient, instance.Status.ImageReference, pullSecret, policy)
+		}
+	}
+	if err != nil {
+		condition := &appsodyv1beta1.StatusCondition{
+			Type:    appsodyv1beta1.StatusConditionTypeImageVerified,
+			Status:  corev1.ConditionFalse,
+			Reason:  reason,
+			Message: err.Error(),
+		}
+		if old == nil || old.GetStatus() != corev1.ConditionFalse || old.GetMessage() != condition.Message {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+		instance.Status.SetCondition(condition)
+		return err
+	}
+	instance.Status.VerifiedImagePolicy = r.lastImagePolicyRV
+	instance.Status.SetCondition(&appsodyv1beta1.StatusCondition{
+		Type:   appsodyv1beta1.StatusConditionTypeImageVerified,
+		Status: corev1.ConditionTrue,
+	})
+	return nil
+}
+
+// reconcileImageArchitectures reads the architectures of the application image from the registry when architecture
+// detection is enabled. The architectures are read once per image. The ArchitectureSupported condition reports whether
+// the image provides the architectures requested in the spec.
+func (r *ReconcileAppsodyApplication) reconcileImageArchitectures(instance *appsodyv1beta1.AppsodyApplication) {
+	if !instance.IsArchitectureDetectionEnabled() {
+		instance.Status.ImageArchitectures = nil
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeArchitectureSupported)
+		return
+	}
+
+	image := instance.Status.ImageReference
+	if instance.Status.ImageArchitectures == nil || instance.Status.ImageArchitectures.Image != image {
+		instance.Status.ImageArchitectures = nil
+		pullSecret, err := r.getPullSecret(instance)
+		var archs []string
+		if err == nil {
+			archs, err = appsodyutils.GetImageArchitectures(registryClient, image, pullSecret)
+		}
+		if err != nil {
+			// Pods are scheduled without the detected architectures until the registry can be queried
+			log.Error(err, "Failed to read the architectures of the application image", "Request.Namespace", instance.Namespace, "Request.Name", instance.Name)
+			r.GetRecorder().Event(instance, "Warning", "ArchitectureDetectionFailed", fmt.Sprintf("Failed to read the architectures of image %s: %v", image, err))
+			removeCondition(instance, appsodyv1beta1.StatusConditionTypeArchitectureSupported)
+			return
+		}
+		instance.Status.ImageArchitectures = &appsodyv1beta1.StatusImageArchitectures{Image: image, Architectures: archs}
+	}
+
+	requested := instance.GetRequestedArchitectures()
+	if len(requested) == 0 {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeArchitectureSupported)
+		return
+	}
+	unsupported := appsodyutils.GetUnsupportedArchitectures(requested, instance.Status.ImageArchitectures.Architectures)
+	if len(unsupported) > 0 {
+		old := instance.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeArchitectureSupported))
+		condition := &appsodyv1beta1.StatusCondition{
+			Type:    appsodyv1beta1.StatusConditionTypeArchitectureSupported,
+			Status:  corev1.ConditionFalse,
+			Reason:  "ArchitectureNotProvided",
+			Message: fmt.Sprintf("Image %s doesn't provide architectures %s", image, strings.Join(unsupported, ", ")),
+		}
+		if old == nil || old.GetStatus() != corev1.ConditionFalse || old.GetMessage() != condition.Message {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+		instance.Status.SetCondition(condition)
+		return
+	}
+	instance.Status.SetCondition(&appsodyv1beta1.StatusCondition{
+		Type:   appsodyv1beta1.StatusConditionTypeArchitectureSupported,
+		Status: corev1.ConditionTrue,
+	})
+}
+
+// reconcileDetectedStack reads the Appsody stack from the labels of the application image when stack detection is
+// enabled, and saves it in the status. The labels are read once per image. The StackMatched condition reports whether
+// the stack of the image is the stack set in `stack`.
+func (r *ReconcileAppsodyApplication) reconcileDetectedStack(instance *appsodyv1beta1.AppsodyApplication) error {
+	old := instance.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeStackMatched))
+	if !instance.IsStackDetectionEnabled() {
+		if instance.Status.DetectedStack == nil && old == nil {
+			return nil
+		}
+		instance.Status.DetectedStack = nil
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeStackMatched)
+		return r.UpdateStatus(instance)
+	}
+
+	detected := instance.Status.DetectedStack
+	if detected == nil || detected.Image != instance.Spec.ApplicationImage {
+		pullSecret, err := r.getPullSecret(instance)
+		var labels map[string]string
+		if err == nil {
+			// Invalid rewrite rules are reported later in the reconcile loop
+			rewriteRules, _ := appsodyutils.GetImageRewriteRules()
+			labels, err = appsodyutils.GetImageLabels(registryClient, appsodyutils.RewriteImage(instance.Spec.ApplicationImage, rewriteRules), pullSecret)
+		}
+		if err != nil {
+			// The previously detected stack is kept until the registry can be queried
+			log.Error(err, "Failed to read the labels of the application image", "Request.Namespace", instance.Namespace, "Request.Name", instance.Name)
+			r.GetRecorder().Event(instance, "Warning", "StackDetectionFailed", fmt.Sprintf("Failed to read the labels of image %s: %v", instance.Spec.ApplicationImage, err))
+			return nil
+		}
+		id, version := appsodyutils.GetImageStack(labels)
+		detected = &appsodyv1beta1.StatusDetectedStack{Image: instance.Spec.ApplicationImage, ID: id, Version: version}
+	}
+
+	var condition *appsodyv1beta1.StatusCondition
+	if instance.Spec.Stack != "" && detected.ID != "" {
+		condition = &appsodyv1beta1.StatusCondition{Type: appsodyv1beta1.StatusConditionTypeStackMatched, Status: corev1.ConditionTrue}
+		if instance.Spec.Stack != detected.ID {
+			condition.Status = corev1.ConditionFalse
+			condition.Reason = "StackMismatch"
+			condition.Message = fmt.Sprintf("Stack %s doesn't match stack %s of image %s", instance.Spec.Stack, detected.ID, detected.Image)
+		}
+	}
+	conditionChanged := (old == nil) != (condition == nil) || (condition != nil && (old.GetStatus() != condition.Status || old.GetMessage() != condition.Message))
+	if !conditionChanged && reflect.DeepEqual(detected, instance.Status.DetectedStack) {
+		return nil
+	}
+
+	instance.Status.DetectedStack = detected
+	if condition == nil {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeStackMatched)
+	} else {
+		if conditionChanged && condition.Status == corev1.ConditionFalse {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+		instance.Status.SetCondition(condition)
+	}
+	return r.UpdateStatus(instance)
+}
+
+// getPullSecret returns the secret set in `spec.pullSecret`, or nil
+func (r *ReconcileAppsodyApplication) getPullSecret(instance *appsodyv1beta1.AppsodyApplication) (*corev1.Secret, error) {
+	if instance.Spec.PullSecret == nil {
+		return nil, nil
+	}
+	pullSecret := &corev1.Secret{}
+	err := r.GetClient().Get(context.Background(), types.NamespacedName{Name: *instance.Spec.PullSecret, Namespace: instance.Namespace}, pullSecret)
+	if err != nil {
+		return nil, err
+	}
+	return pullSecret, nil
+}
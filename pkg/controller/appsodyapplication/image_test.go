@@ -0,0 +1,410 @@
+package appsodyapplication
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestImageUpdatePolicy(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ns/app/tags/list":
+			fmt.Fprint(w, `{"name":"ns/app","tags":["1.0.0","1.1.0","1.2.0-rc1","2.0.0","latest"]}`)
+		case "/v2/ns/app/manifests/1.1.0":
+			w.Header().Set("Docker-Content-Digest", digest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defaultRegistryClient := registryClient
+	registryClient = server.Client()
+	defer func() { registryClient = defaultRegistryClient }()
+
+	image := strings.TrimPrefix(server.URL, "https://") + "/ns/app"
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:             stack,
+		ApplicationImage:  image + ":1.0.0",
+		ImageUpdatePolicy: &appsodyv1beta1.AppsodyImageUpdatePolicy{Mode: appsodyv1beta1.ImageUpdateModeManual, SemverRange: ">=1.0.0 <2.0.0"},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+	recorder := r.GetRecorder().(*record.FakeRecorder)
+
+	// Manual mode only reports the newest release in the range
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if appsody.Status.ImageUpdate == nil {
+		t.Fatalf("status.imageUpdate was not set")
+	}
+	manualTests := []Test{
+		{"latest image", image + ":1.1.0", appsody.Status.ImageUpdate.LatestImage},
+		{"image reference", image + ":1.0.0", appsody.Status.ImageReference},
+		{"event", "Normal ImageUpdateAvailable Image " + image + ":1.1.0 is available", <-recorder.Events},
+		{"requeue after", 5 * time.Minute, res.RequeueAfter},
+	}
+	verifyTests("manual image update", manualTests, t)
+
+	// Auto mode deploys the newest image, pinned to its digest
+	appsody.Spec.ImageUpdatePolicy.Mode = appsodyv1beta1.ImageUpdateModeAuto
+	appsody.Spec.ImagePinning = appsodyv1beta1.ImagePinningDigest
+	updateAppsody(r, appsody, t)
+
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	autoTests := []Test{
+		{"image reference", image + "@" + digest, appsody.Status.ImageReference},
+		{"pinned image", image + ":1.1.0", appsody.Status.PinnedImage},
+		{"deployment image", image + "@" + digest, dep.Spec.Template.Spec.Containers[0].Image},
+	}
+	verifyTests("auto image update", autoTests, t)
+
+	// A latest image the policy no longer allows is discarded and the registry is polled again
+	appsody.Spec.ImageUpdatePolicy.SemverRange = "<1.1.0"
+	appsody.Spec.ImagePinning = ""
+	updateAppsody(r, appsody, t)
+
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	verifyTests("policy change", []Test{{"image reference", image + ":1.0.0", appsody.Status.ImageReference}}, t)
+}
+
+func TestImageSignatureVerification(t *testing.T) {
+	signed := "sha256:" + strings.Repeat("a", 64)
+	unsigned := "sha256:" + strings.Repeat("b", 64)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: (%v)", err)
+	}
+	payload := []byte(fmt.Sprintf(`{"critical":{"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"}}`, signed))
+	hash := sha256.Sum256(payload)
+	sigR, sigS, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("Sign: (%v)", err)
+	}
+	signature, _ := asn1.Marshal(struct{ R, S *big.Int }{sigR, sigS})
+	payloadDigest := "sha256:" + hex.EncodeToString(hash[:])
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ns/app/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", signed)
+		case "/v2/ns/app/manifests/2.0":
+			w.Header().Set("Docker-Content-Digest", unsigned)
+		case "/v2/ns/app/manifests/" + strings.Replace(signed, ":", "-", 1) + ".sig":
+			fmt.Fprintf(w, `{"layers":[{"digest":"%s","annotations":{"dev.cosignproject.cosign/signature":"%s"}}]}`, payloadDigest, base64.StdEncoding.EncodeToString(signature))
+		case "/v2/ns/app/blobs/" + payloadDigest:
+			w.Write(payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defaultRegistryClient := registryClient
+	registryClient = server.Client()
+	defer func() { registryClient = defaultRegistryClient }()
+
+	image := strings.TrimPrefix(server.URL, "https://") + "/ns/app"
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, ApplicationImage: image + ":1.0"}
+	appsody := createAppsodyApp(name, namespace, spec)
+	policy := "stacks: [" + stack + "]\npublicKeys:\n- |\n  " + strings.Replace(strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))), "\n", "\n  ", -1)
+	imagePolicy := createConfigMap("appsody-operator-image-policy", namespace, map[string]string{"ci": policy})
+
+	r := newTestReconciler(t, appsody, imagePolicy)
+
+	// A signed image is deployed by digest
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeImageVerified))
+	signedTests := []Test{
+		{"image reference", image + "@" + signed, appsody.Status.ImageReference},
+		{"deployment image", image + "@" + signed, dep.Spec.Template.Spec.Containers[0].Image},
+		{"image verified", corev1.ConditionTrue, condition.GetStatus()},
+	}
+	verifyTests("signed image", signedTests, t)
+
+	// An unsigned image is not rolled out and the signed image keeps running
+	appsody.Spec.ApplicationImage = image + ":2.0"
+	updateAppsody(r, appsody, t)
+
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	dep = &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeImageVerified))
+	unsignedTests := []Test{
+		{"image reference", image + "@" + signed, appsody.Status.ImageReference},
+		{"deployment image", image + "@" + signed, dep.Spec.Template.Spec.Containers[0].Image},
+		{"image verified", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", "VerificationFailed", condition.GetReason()},
+	}
+	verifyTests("unsigned image", unsignedTests, t)
+
+	// A deployed image is verified again when the image policies change and an invalid policy fails the verification
+	appsody.Spec.ApplicationImage = image + ":1.0"
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	imagePolicy.Data["ci"] = "stacks: [" + stack + "]\npublicKeys: [not a key]"
+	if err = r.GetClient().Update(context.TODO(), imagePolicy); err != nil {
+		t.Fatalf("Update image policy: (%v)", err)
+	}
+	if _, err = r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeImageVerified))
+	invalidTests := []Test{
+		{"image verified", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", "InvalidPolicy", condition.GetReason()},
+		{"message", true, strings.Contains(condition.GetMessage(), "image policy ci is invalid")},
+	}
+	verifyTests("invalid image policy", invalidTests, t)
+}
+
+func TestImageRewriteRules(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:             stack,
+		ApplicationImage:  "quay.io/my-repo/my-app:1.0",
+		InitContainers:    []corev1.Container{{Name: "init", Image: "busybox"}},
+		SidecarContainers: []corev1.Container{{Name: "proxy", Image: "registry.corp/proxy:1.0"}},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+	opConfig := createConfigMap("appsody-operator", namespace, map[string]string{
+		appsodyutils.OpConfigImageRewriteRules: "docker.io/ -> mirror.corp/dockerhub/\nquay.io/ -> mirror.corp/quay/",
+	})
+	defer func() { common.Config = common.DefaultOpConfig() }()
+
+	r := newTestReconciler(t, appsody, opConfig)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	podSpec := dep.Spec.Template.Spec
+	rewriteTests := []Test{
+		{"image reference", "mirror.corp/quay/my-repo/my-app:1.0", appsody.Status.ImageReference},
+		{"app image", "mirror.corp/quay/my-repo/my-app:1.0", podSpec.Containers[0].Image},
+		{"init image", "mirror.corp/dockerhub/library/busybox", podSpec.InitContainers[0].Image},
+		{"sidecar image", "registry.corp/proxy:1.0", podSpec.Containers[1].Image},
+		{"spec init image", "busybox", appsody.Spec.InitContainers[0].Image},
+		{"rewritten images", 2, len(appsody.Status.RewrittenImages)},
+	}
+	verifyTests("image rewrite", rewriteTests, t)
+
+	original := map[string]string{}
+	for _, i := range appsody.Status.RewrittenImages {
+		original[i.Container] = i.OriginalImage
+	}
+	verifyTests("original images", []Test{{"app", "quay.io/my-repo/my-app:1.0", original["app"]}, {"init", "busybox", original["init"]}}, t)
+}
+
+func TestKnativeImageRewriteRules(t *testing.T) {
+	createKnativeService := true
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:                stack,
+		ApplicationImage:     "quay.io/my-repo/my-app:1.0",
+		CreateKnativeService: &createKnativeService,
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+	opConfig := createConfigMap("appsody-operator", namespace, map[string]string{
+		appsodyutils.OpConfigImageRewriteRules: "docker.io/ -> mirror.corp/dockerhub/",
+	})
+	defer func() { common.Config = common.DefaultOpConfig() }()
+
+	// The Knative Service already has init and sidecar containers
+	servingGV := schema.GroupVersion{Group: appsodyutils.KnativeServingGroup, Version: "v1"}
+	addUnstructuredKinds(servingGV, "Service")
+	ksvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"initContainers": []interface{}{map[string]interface{}{"name": "init", "image": "busybox"}},
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "quay.io/my-repo/my-app:1.0"},
+						map[string]interface{}{"name": "proxy", "image": "docker.io/envoyproxy/envoy:v1.14"},
+					},
+				},
+			},
+		},
+	}}
+	ksvc.SetGroupVersionKind(servingGV.WithKind("Service"))
+	ksvc.SetName(name)
+	ksvc.SetNamespace(namespace)
+	r := newTestReconciler(t, appsody, opConfig, ksvc)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: servingGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "services", Namespaced: true, Kind: "Service", SingularName: "service"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ksvc); err != nil {
+		t.Fatalf("Get Knative Service: (%v)", err)
+	}
+	initContainers, _, _ := unstructured.NestedSlice(ksvc.Object, "spec", "template", "spec", "initContainers")
+	containers, _, _ := unstructured.NestedSlice(ksvc.Object, "spec", "template", "spec", "containers")
+	initContainer, _ := initContainers[0].(map[string]interface{})
+	sidecar, _ := containers[1].(map[string]interface{})
+	rewriteTests := []Test{
+		{"init image", "mirror.corp/dockerhub/library/busybox", initContainer["image"]},
+		{"sidecar image", "mirror.corp/dockerhub/envoyproxy/envoy:v1.14", sidecar["image"]},
+	}
+	verifyTests("knative image rewrite", rewriteTests, t)
+}
+
+func TestArchitectureDetection(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ns/app/manifests/1.0":
+			fmt.Fprint(w, `{"schemaVersion":2,"manifests":[{"platform":{"architecture":"arm64","os":"linux"}},{"platform":{"architecture":"amd64","os":"linux"}}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defaultRegistryClient := registryClient
+	registryClient = server.Client()
+	defer func() { registryClient = defaultRegistryClient }()
+
+	detect := true
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:              stack,
+		ApplicationImage:   strings.TrimPrefix(server.URL, "https://") + "/ns/app:1.0",
+		DetectArchitecture: &detect,
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+	recorder := r.GetRecorder().(*record.FakeRecorder)
+
+	// Pods are scheduled on the architectures of the image when none are requested
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	if appsody.Status.ImageArchitectures == nil || dep.Spec.Template.Spec.Affinity == nil {
+		t.Fatalf("Architectures of the image were not detected")
+	}
+	requirement := dep.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+	detectTests := []Test{
+		{"image architectures", "amd64,arm64", strings.Join(appsody.Status.ImageArchitectures.Architectures, ",")},
+		{"affinity key", appsodyutils.ArchitectureLabel, requirement.Key},
+		{"affinity architectures", "amd64,arm64", strings.Join(requirement.Values, ",")},
+		{"architecture condition", true, appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeArchitectureSupported)) == nil},
+	}
+	verifyTests("architecture detection", detectTests, t)
+
+	// Requesting an architecture the image doesn't provide raises a warning
+	appsody.Spec.Architecture = []string{"amd64", "ppc64le"}
+	if err = r.GetClient().Update(context.TODO(), appsody); err != nil {
+		t.Fatalf("Update appsody: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeArchitectureSupported))
+	if condition == nil || condition.GetStatus() != corev1.ConditionFalse || !strings.Contains(condition.GetMessage(), "ppc64le") {
+		t.Fatalf("ArchitectureSupported condition expected to be false for ppc64le, actual: (%v)", condition)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "ArchitectureNotProvided") {
+			t.Errorf("Unexpected event: %s", event)
+		}
+	default:
+		t.Errorf("Expected an ArchitectureNotProvided event")
+	}
+}
@@ -0,0 +1,110 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// getIngressAPIVersion returns the newest Ingress API version supported on the cluster, or an empty string
+func (r *ReconcileAppsodyApplication) getIngressAPIVersion() string {
+	for _, version := range []string{"v1", "v1beta1"} {
+		apiVersion := appsodyutils.IngressGroup + "/" + version
+		if ok, _ := r.IsGroupVersionSupported(apiVersion, "Ingress"); ok {
+			return apiVersion
+		}
+	}
+	return ""
+}
+
+// reconcileIngress creates or updates the Ingress of the application when it is exposed, along with the certificate of
+// the Ingress host when the route requests TLS without a certificate, and deletes them otherwise. The Ingress and the
+// readiness of its TLS secret are reported in the status.
+func (r *ReconcileAppsodyApplication) reconcileIngress(instance *appsodyv1beta1.AppsodyApplication, expose bool) error {
+	apiVersion := r.getIngressAPIVersion()
+	if apiVersion == "" {
+		instance.Status.Ingress = nil
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeIngressTLSReady)
+		return nil
+	}
+
+	ing := &unstructured.Unstructured{}
+	ing.SetAPIVersion(apiVersion)
+	ing.SetKind("Ingress")
+	ing.SetName(instance.Name)
+	ing.SetNamespace(instance.Namespace)
+
+	host := appsodyutils.GetRouteHost(instance)
+	tlsSecretName := appsodyutils.GetRouteTLSSecretName(instance)
+	crt := &certmngrv1alpha2.Certificate{ObjectMeta: metav1.ObjectMeta{Name: instance.Name + "-ingress-crt", Namespace: instance.Namespace}}
+	issueCertificate := false
+	if ok, _ := r.IsGroupVersionSupported(certmngrv1alpha2.SchemeGroupVersion.String(), "Certificate"); ok {
+		issueCertificate = expose && host != "" && appsodyutils.IsIngressCertificateNeeded(instance)
+		if issueCertificate {
+			tlsSecretName = instance.Name + "-ingress-tls"
+			err := r.CreateOrUpdate(crt, instance, func() error {
+				appsodyutils.CustomizeIngressCertificate(crt, instance, host, tlsSecretName)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		} else if err := r.DeleteResource(crt); err != nil {
+			return err
+		}
+	}
+
+	if !expose {
+		instance.Status.Ingress = nil
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeIngressTLSReady)
+		return r.DeleteResource(ing)
+	}
+	err := r.CreateOrUpdate(ing, instance, func() error {
+		appsodyutils.CustomizeIngress(ing, instance, host, tlsSecretName)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if host == "" {
+		tlsSecretName = ""
+	}
+	instance.Status.Ingress = &appsodyv1beta1.StatusIngress{APIVersion: apiVersion, Host: host, TLSSecretName: tlsSecretName}
+	if tlsSecretName == "" {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeIngressTLSReady)
+		return nil
+	}
+	return r.setIngressTLSReadyCondition(instance, tlsSecretName, issueCertificate)
+}
+
+// setIngressTLSReadyCondition sets the IngressTLSReady condition from the presence of a certificate in the TLS secret
+// of the Ingress
+func (r *ReconcileAppsodyApplication) setIngressTLSReadyCondition(instance *appsodyv1beta1.AppsodyApplication, secretName string, issued bool) error {
+	condition := &appsodyv1beta1.StatusCondition{Type: appsodyv1beta1.StatusConditionTypeIngressTLSReady, Status: corev1.ConditionTrue}
+	secret := &corev1.Secret{}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: instance.Namespace}, secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	if err != nil || len(secret.Data[corev1.TLSCertKey]) == 0 {
+		condition.Status = corev1.ConditionFalse
+		if issued {
+			condition.Reason = "CertificateNotReady"
+			condition.Message = fmt.Sprintf("Waiting for the certificate of the Ingress to be issued in secret %s", secretName)
+		} else {
+			condition.Reason = "SecretNotFound"
+			condition.Message = fmt.Sprintf("TLS secret %s of the Ingress was not found", secretName)
+		}
+	}
+	instance.Status.SetCondition(condition)
+	return nil
+}
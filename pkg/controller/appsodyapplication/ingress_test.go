@@ -0,0 +1,97 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestIngress(t *testing.T) {
+	expose, edge := true, routev1.TLSTerminationEdge
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:  stack,
+		Expose: &expose,
+		Route:  &appsodyv1beta1.AppsodyRoute{Host: "app.example.com", Termination: &edge, IngressClassName: "nginx"},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	ingressGV := schema.GroupVersion{Group: appsodyutils.IngressGroup, Version: "v1"}
+	addUnstructuredKinds(ingressGV, "Ingress")
+	r := newTestReconciler(t, appsody)
+	// Ingresses are only used on clusters without Routes
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	for _, list := range discoveryClient.Resources {
+		if list.GroupVersion == routev1.SchemeGroupVersion.String() {
+			list.APIResources = nil
+		}
+	}
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: ingressGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "ingresses", Namespaced: true, Kind: "Ingress", SingularName: "ingress"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	// A networking.k8s.io/v1 Ingress is created with a certificate issued for its host
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(ingressGV.WithKind("Ingress"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ing); err != nil {
+		t.Fatalf("Get Ingress: (%v)", err)
+	}
+	crt := &certmngrv1alpha2.Certificate{}
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-ingress-crt", Namespace: namespace}, crt); err != nil {
+		t.Fatalf("Get Certificate: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	className, _, _ := unstructured.NestedString(ing.Object, "spec", "ingressClassName")
+	tls, _, _ := unstructured.NestedSlice(ing.Object, "spec", "tls")
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeIngressTLSReady))
+	if condition == nil || appsody.Status.Ingress == nil {
+		t.Fatalf("Ingress status was not set")
+	}
+	ingressTests := []Test{
+		{"class", "nginx", className},
+		{"tls secret", name + "-ingress-tls", fmt.Sprint(tls[0].(map[string]interface{})["secretName"])},
+		{"certificate host", "app.example.com", crt.Spec.CommonName},
+		{"status host", "app.example.com", appsody.Status.Ingress.Host},
+		{"tls ready", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", "CertificateNotReady", condition.GetReason()},
+	}
+	verifyTests("ingress", ingressTests, t)
+
+	// The TLS is ready once the certificate is issued
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-ingress-tls", Namespace: namespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	if err = r.GetClient().Create(context.TODO(), secret); err != nil {
+		t.Fatalf("Create Secret: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeIngressTLSReady))
+	verifyTests("issued certificate", []Test{{"tls ready", corev1.ConditionTrue, condition.GetStatus()}}, t)
+}
@@ -0,0 +1,81 @@
+package appsodyapplication
+
+import (
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// getKnativeAPIVersion returns the newest Knative Serving API version supported on the cluster, or an empty string
+func (r *ReconcileAppsodyApplication) getKnativeAPIVersion() string {
+	for _, version := range []string{"v1", "v1alpha1"} {
+		apiVersion := appsodyutils.KnativeServingGroup + "/" + version
+		if ok, _ := r.IsGroupVersionSupported(apiVersion, "Service"); ok {
+			return apiVersion
+		}
+	}
+	return ""
+}
+
+// reconcileKnativeService creates or updates the Knative Service of the application with the API version, and
+// reports its revisions in the status. The service is customized as a v1alpha1 Service, whose revision template,
+// traffic and status have the same schema as in v1.
+func (r *ReconcileAppsodyApplication) reconcileKnativeService(instance *appsodyv1beta1.AppsodyApplication, apiVersion string, customize func(*servingv1alpha1.Service)) error {
+	meta := metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}
+	if apiVersion == servingv1alpha1.SchemeGroupVersion.String() {
+		ksvc := &servingv1alpha1.Service{ObjectMeta: meta}
+		err := r.CreateOrUpdate(ksvc, instance, func() error {
+			customize(ksvc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		instance.Status.Knative = appsodyutils.GetKnativeStatus(ksvc, apiVersion)
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind("Service")
+	obj.SetName(instance.Name)
+	obj.SetNamespace(instance.Namespace)
+	err := r.CreateOrUpdate(obj, instance, func() error {
+		ksvc := &servingv1alpha1.Service{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ksvc); err != nil {
+			return err
+		}
+		customize(ksvc)
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ksvc)
+		if err != nil {
+			return err
+		}
+		// The status is owned by Knative
+		obj.Object["metadata"] = content["metadata"]
+		obj.Object["spec"] = content["spec"]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	ksvc := &servingv1alpha1.Service{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ksvc); err != nil {
+		return err
+	}
+	instance.Status.Knative = appsodyutils.GetKnativeStatus(ksvc, apiVersion)
+	return nil
+}
+
+// deleteKnativeService deletes the Knative Service of the application
+func (r *ReconcileAppsodyApplication) deleteKnativeService(instance *appsodyv1beta1.AppsodyApplication, apiVersion string) error {
+	instance.Status.Knative = nil
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind("Service")
+	obj.SetName(instance.Name)
+	obj.SetNamespace(instance.Namespace)
+	return r.DeleteResource(obj)
+}
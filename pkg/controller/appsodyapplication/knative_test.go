@@ -0,0 +1,120 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestKnativeServiceV1(t *testing.T) {
+	createKnativeService, concurrency, maxScale, percent := true, int64(20), int32(3), int32(100)
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:                stack,
+		ApplicationImage:     appImage,
+		CreateKnativeService: &createKnativeService,
+		Knative: &appsodyv1beta1.AppsodyKnative{
+			ContainerConcurrency: &concurrency,
+			MaxScale:             &maxScale,
+			Traffic:              []appsodyv1beta1.AppsodyKnativeTraffic{{Percent: &percent, Tag: "current"}},
+		},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	servingGV := schema.GroupVersion{Group: appsodyutils.KnativeServingGroup, Version: "v1"}
+	addUnstructuredKinds(servingGV, "Service")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: servingGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "services", Namespaced: true, Kind: "Service", SingularName: "service"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	// serving.knative.dev/v1 is preferred over v1alpha1
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	ksvc := &unstructured.Unstructured{}
+	ksvc.SetGroupVersionKind(servingGV.WithKind("Service"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ksvc); err != nil {
+		t.Fatalf("Get Knative Service v1: (%v)", err)
+	}
+	containers, _, _ := unstructured.NestedSlice(ksvc.Object, "spec", "template", "spec", "containers")
+	container, _ := containers[0].(map[string]interface{})
+	containerConcurrency, _, _ := unstructured.NestedInt64(ksvc.Object, "spec", "template", "spec", "containerConcurrency")
+	annotations, _, _ := unstructured.NestedStringMap(ksvc.Object, "spec", "template", "metadata", "annotations")
+	traffic, _, _ := unstructured.NestedSlice(ksvc.Object, "spec", "traffic")
+	target, _ := traffic[0].(map[string]interface{})
+	err = r.GetClient().Get(context.TODO(), req.NamespacedName, &servingv1alpha1.Service{})
+	knativeTests := []Test{
+		{"image", appImage, container["image"]},
+		{"container concurrency", int64(20), containerConcurrency},
+		{"max scale", "3", annotations["autoscaling.knative.dev/maxScale"]},
+		{"traffic", "current/true/100", fmt.Sprintf("%v/%v/%v", target["tag"], target["latestRevision"], target["percent"])},
+		{"owner", name, ksvc.GetOwnerReferences()[0].Name},
+		{"v1alpha1 service", true, kerrors.IsNotFound(err)},
+	}
+	verifyTests("knative v1", knativeTests, t)
+
+	// The revisions and the traffic of the Knative Service are reported in the status
+	ksvc.Object["status"] = map[string]interface{}{
+		"url":                     "http://" + name + "." + namespace + ".example.com",
+		"latestReadyRevisionName": name + "-00001",
+		"traffic": []interface{}{map[string]interface{}{
+			"tag":            "current",
+			"revisionName":   name + "-00001",
+			"latestRevision": true,
+			"percent":        int64(100),
+			"url":            "http://current-" + name + "." + namespace + ".example.com",
+		}},
+	}
+	if err = r.GetClient().Update(context.TODO(), ksvc); err != nil {
+		t.Fatalf("Update Knative Service status: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if appsody.Status.Knative == nil || len(appsody.Status.Knative.Traffic) != 1 {
+		t.Fatalf("Knative status expected to report the traffic, actual: (%v)", appsody.Status.Knative)
+	}
+	statusTests := []Test{
+		{"api version", servingGV.String(), appsody.Status.Knative.APIVersion},
+		{"latest ready revision", name + "-00001", appsody.Status.Knative.LatestReadyRevisionName},
+		{"revision", name + "-00001", appsody.Status.Knative.Traffic[0].RevisionName},
+		{"percent", "100", fmt.Sprint(appsody.Status.Knative.Traffic[0].Percent)},
+		{"tag url", "http://current-" + name + "." + namespace + ".example.com", appsody.Status.Knative.Traffic[0].URL},
+	}
+	verifyTests("knative status", statusTests, t)
+
+	// The Knative Service is deleted when it is not requested anymore
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	createKnativeService = false
+	appsody.Spec.CreateKnativeService = &createKnativeService
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+	markWorkloadReady(r, req, appsodyv1beta1.ApplicationModeDeployment, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, ksvc); !kerrors.IsNotFound(err) {
+		t.Fatalf("Knative Service v1 expected to be deleted, actual error: (%v)", err)
+	}
+}
@@ -0,0 +1,197 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// modeTransitionPollInterval is the delay between two checks of the readiness of the new mode of the application
+	modeTransitionPollInterval = 10 * time.Second
+)
+
+// getKnativeService returns the Knative Service of the application with the API version, or nil if it does not exist
+func (r *ReconcileAppsodyApplication) getKnativeService(instance *appsodyv1beta1.AppsodyApplication, apiVersion string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind("Service")
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, obj)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// releaseService deletes the Service with the name of the application if the application controls it, so that the
+// Route of the Knative Service can create its own Service with this name
+func (r *ReconcileAppsodyApplication) releaseService(instance *appsodyv1beta1.AppsodyApplication) error {
+	svc := &corev1.Service{}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, svc)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(svc, instance) {
+		return nil
+	}
+	return r.DeleteResource(svc)
+}
+
+// isServiceHeldByKnative returns true if the Service with the name of the application is controlled by the Route of
+// a Knative Service. The Route releases it once the Knative Service is deleted.
+func (r *ReconcileAppsodyApplication) isServiceHeldByKnative(instance *appsodyv1beta1.AppsodyApplication) (bool, error) {
+	svc := &corev1.Service{}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, svc)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	ref := metav1.GetControllerOf(svc)
+	return ref != nil && ref.Kind == "Route" && strings.HasPrefix(ref.APIVersion, appsodyutils.KnativeServingGroup+"/"), nil
+}
+
+// getWorkloadMode returns the mode of the application when it is not run with a Knative Service
+func getWorkloadMode(instance *appsodyv1beta1.AppsodyApplication) appsodyv1beta1.ApplicationMode {
+	if instance.Spec.Storage != nil && !instance.Spec.Storage.IsShared() {
+		return appsodyv1beta1.ApplicationModeStatefulSet
+	}
+	return appsodyv1beta1.ApplicationModeDeployment
+}
+
+// getExistingWorkloadMode returns the mode of the Deployment or the StatefulSet of the application, or an empty
+// string if neither exists
+func (r *ReconcileAppsodyApplication) getExistingWorkloadMode(instance *appsodyv1beta1.AppsodyApplication) (appsodyv1beta1.ApplicationMode, error) {
+	for _, mode := range []appsodyv1beta1.ApplicationMode{appsodyv1beta1.ApplicationModeDeployment, appsodyv1beta1.ApplicationModeStatefulSet} {
+		exists, _, err := r.getWorkloadReadiness(instance, mode)
+		if err != nil || exists {
+			return mode, err
+		}
+	}
+	return "", nil
+}
+
+// getWorkloadReadiness returns whether the Deployment or the StatefulSet of the mode exists, and whether all its
+// desired replicas of the latest generation are ready
+func (r *ReconcileAppsodyApplication) getWorkloadReadiness(instance *appsodyv1beta1.AppsodyApplication, mode appsodyv1beta1.ApplicationMode) (bool, bool, error) {
+	key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+	if mode == appsodyv1beta1.ApplicationModeStatefulSet {
+		statefulSet := &appsv1.StatefulSet{}
+		err := r.GetClient().Get(context.TODO(), key, statefulSet)
+		if err != nil {
+			return false, false, client.IgnoreNotFound(err)
+		}
+		desired := getDesiredReplicas(statefulSet.Spec.Replicas)
+		return true, statefulSet.Status.ObservedGeneration >= statefulSet.Generation && statefulSet.Status.ReadyReplicas >= desired, nil
+	}
+
+	deploy := &appsv1.Deployment{}
+	err := r.GetClient().Get(context.TODO(), key, deploy)
+	if err != nil {
+		return false, false, client.IgnoreNotFound(err)
+	}
+	desired := getDesiredReplicas(deploy.Spec.Replicas)
+	return true, deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas >= desired && deploy.Status.ReadyReplicas >= desired, nil
+}
+
+// getPerPodClaims returns the names of the volume claims created from the volume claim templates of the
+// StatefulSet of the application
+func (r *ReconcileAppsodyApplication) getPerPodClaims(instance *appsodyv1beta1.AppsodyApplication) ([]string, error) {
+	pvcs, err := r.getStatefulSetClaims(instance)
+	if err != nil {
+		return nil, err
+	}
+	claims := []string{}
+	for _, pvc := range pvcs {
+		claims = append(claims, pvc.Name)
+	}
+	return claims, nil
+}
+
+// getDesiredReplicas returns the number of replicas requested by a Deployment or a StatefulSet
+func getDesiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// setModeTransition records the phase of the switch of the application between two modes, starting a new
+// transition if the application was not already switching between these modes. When the application leaves its
+// StatefulSet, a warning lists the per-pod volume claims that are kept but no longer used, before the StatefulSet
+// is deleted.
+func (r *ReconcileAppsodyApplication) setModeTransition(instance *appsodyv1beta1.AppsodyApplication, from, to appsodyv1beta1.ApplicationMode, phase appsodyv1beta1.ModeTransitionPhase) error {
+	transition := instance.Status.ModeTransition
+	if transition == nil || transition.From != from || transition.To != to {
+		now := metav1.Now()
+		transition = &appsodyv1beta1.StatusModeTransition{From: from, To: to, StartTime: &now}
+		if from == appsodyv1beta1.ApplicationModeStatefulSet {
+			claims, err := r.getPerPodClaims(instance)
+			if err != nil {
+				return err
+			}
+			if len(claims) > 0 {
+				transition.AbandonedClaims = claims
+				r.GetRecorder().Event(instance, "Warning", "PersistentVolumeClaimsAbandoned",
+					fmt.Sprintf("The data of persistent volume claim(s) %s is no longer used by the application once the StatefulSet is deleted. The claims are kept.", strings.Join(claims, ", ")))
+			}
+		}
+		instance.Status.ModeTransition = transition
+		r.GetRecorder().Event(instance, "Normal", "ModeTransitionStarted", fmt.Sprintf("Switching the application from %s to %s", from, to))
+	}
+	transition.Phase = phase
+	return nil
+}
+
+// getModeTransitionPhase returns the phase of a switch between modes from the readiness of the new mode
+func getModeTransitionPhase(ready bool) appsodyv1beta1.ModeTransitionPhase {
+	if ready {
+		return appsodyv1beta1.ModeTransitionPhaseCleaningUp
+	}
+	return appsodyv1beta1.ModeTransitionPhaseProvisioning
+}
+
+// manageModeTransition reports the progress of the switch between modes and checks the readiness of the new mode
+// again after the poll interval
+func (r *ReconcileAppsodyApplication) manageModeTransition(instance *appsodyv1beta1.AppsodyApplication) (reconcile.Result, error) {
+	result, err := r.ManageSuccess(common.StatusConditionTypeReconciled, instance)
+	if err == nil && result == (reconcile.Result{}) {
+		result.RequeueAfter = modeTransitionPollInterval
+	}
+	return result, err
+}
+
+// clearModeTransition completes the switch of the application between two modes, if any
+func (r *ReconcileAppsodyApplication) clearModeTransition(instance *appsodyv1beta1.AppsodyApplication) {
+	transition := instance.Status.ModeTransition
+	if transition == nil {
+		return
+	}
+	instance.Status.ModeTransition = nil
+	r.GetRecorder().Event(instance, "Normal", "ModeTransitionCompleted", fmt.Sprintf("Switched the application from %s to %s", transition.From, transition.To))
+}
+
+// getKnativeServiceReadiness returns whether the latest revision of the Knative Service of the application with the
+// API version is ready, and whether the Knative Service is ready
+func (r *ReconcileAppsodyApplication) getKnativeServiceReadiness(instance *appsodyv1beta1.AppsodyApplication, apiVersion string) (bool, bool, error) {
+	ksvc, err := r.getKnativeService(instance, apiVersion)
+	if err != nil || ksvc == nil {
+		return false, false, err
+	}
+	return appsodyutils.IsKnativeConfigurationReady(ksvc), appsodyutils.IsKnativeServiceReady(ksvc), nil
+}
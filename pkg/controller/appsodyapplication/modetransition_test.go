@@ -0,0 +1,252 @@
+package appsodyapplication
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestModeTransition(t *testing.T) {
+	expose, createKnativeService := true, true
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, ApplicationImage: appImage, Expose: &expose}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	// The Deployment and the Route keep serving until the Knative Service is ready
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.CreateKnativeService = &createKnativeService
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	deployErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{})
+	routeErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &routev1.Route{})
+	ksvcErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &servingv1alpha1.Service{})
+	svcErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &corev1.Service{})
+	toKnativeTests := []Test{
+		{"transition", "Deployment/Knative/Provisioning", getModeTransition(appsody)},
+		{"deployment kept", nil, deployErr},
+		{"route kept", nil, routeErr},
+		{"knative service created", nil, ksvcErr},
+		{"service kept until the revision is ready", nil, svcErr},
+	}
+	verifyTests("to knative", toKnativeTests, t)
+
+	// Once the latest revision is ready, the Service is handed over to the Route of the Knative Service
+	markKnativeRevisionReady(r, req, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	deployErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{})
+	svcErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &corev1.Service{})
+	revisionReadyTests := []Test{
+		{"deployment kept", nil, deployErr},
+		{"service released", true, kerrors.IsNotFound(svcErr)},
+	}
+	verifyTests("revision ready", revisionReadyTests, t)
+
+	// Once the Knative Service is ready, the exposure moves to Knative and the non-Knative resources are deleted
+	createKnativeRouteService(r, req, t)
+	markKnativeServiceReady(r, req, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	deployErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{})
+	routeErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &routev1.Route{})
+	svcErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &corev1.Service{})
+	knativeTests := []Test{
+		{"transition completed", "", getModeTransition(appsody)},
+		{"deployment deleted", true, kerrors.IsNotFound(deployErr)},
+		{"route deleted", true, kerrors.IsNotFound(routeErr)},
+		{"knative route service kept", nil, svcErr},
+	}
+	verifyTests("knative", knativeTests, t)
+
+	// The Knative Service keeps serving until the Deployment is ready, and the Route is only created then
+	createKnativeService = false
+	appsody.Spec.CreateKnativeService = &createKnativeService
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	routeErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &routev1.Route{})
+	ksvcErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &servingv1alpha1.Service{})
+	toDeploymentTests := []Test{
+		{"transition", "Knative/Deployment/Provisioning", getModeTransition(appsody)},
+		{"knative service kept", nil, ksvcErr},
+		{"route not created", true, kerrors.IsNotFound(routeErr)},
+	}
+	verifyTests("to deployment", toDeploymentTests, t)
+
+	// Once the Deployment is ready, the Knative Service is deleted and the Service is created when its Route
+	// releases it
+	markWorkloadReady(r, req, appsodyv1beta1.ApplicationModeDeployment, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	svc := &corev1.Service{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("Get Service: (%v)", err)
+	}
+	routeErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &routev1.Route{})
+	ksvcErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &servingv1alpha1.Service{})
+	releaseTests := []Test{
+		{"transition", "Knative/Deployment/CleaningUp", getModeTransition(appsody)},
+		{"knative service deleted", true, kerrors.IsNotFound(ksvcErr)},
+		{"service held", false, metav1.IsControlledBy(svc, appsody)},
+		{"route not created", true, kerrors.IsNotFound(routeErr)},
+	}
+	verifyTests("release", releaseTests, t)
+
+	// Kubernetes garbage collects the Service of the deleted Knative Route
+	if err = r.GetClient().Delete(context.TODO(), svc); err != nil {
+		t.Fatalf("Delete Service: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	svc = &corev1.Service{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("Get Service: (%v)", err)
+	}
+	routeErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &routev1.Route{})
+	deploymentTests := []Test{
+		{"transition completed", "", getModeTransition(appsody)},
+		{"service created", true, metav1.IsControlledBy(svc, appsody)},
+		{"route created", nil, routeErr},
+	}
+	verifyTests("deployment", deploymentTests, t)
+}
+
+func TestStorageTransition(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, ApplicationImage: appImage}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+	recorder := r.GetRecorder().(*record.FakeRecorder)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	// The Deployment keeps serving until the StatefulSet is ready
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	claimTemplate := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data"}}
+	appsody.Spec.Storage = &appsodyv1beta1.AppsodyApplicationStorage{Size: "1Gi", MountPath: "/data", VolumeClaimTemplate: claimTemplate}
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	deployErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{})
+	statefulSetErr := r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.StatefulSet{})
+	toStatefulSetTests := []Test{
+		{"transition", "Deployment/StatefulSet/Provisioning", getModeTransition(appsody)},
+		{"deployment kept", nil, deployErr},
+		{"statefulset created", nil, statefulSetErr},
+	}
+	verifyTests("to statefulset", toStatefulSetTests, t)
+
+	markWorkloadReady(r, req, appsodyv1beta1.ApplicationModeStatefulSet, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	deployErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{})
+	statefulSetTests := []Test{
+		{"transition completed", "", getModeTransition(appsody)},
+		{"deployment deleted", true, kerrors.IsNotFound(deployErr)},
+	}
+	verifyTests("statefulset", statefulSetTests, t)
+
+	// Leaving the StatefulSet warns that the data of its per-pod volume claims is no longer used. The claims
+	// created from the volume claim template of the user don't have the labels of the application.
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-" + name + "-0", Namespace: namespace}}
+	if err = r.GetClient().Create(context.TODO(), pvc); err != nil {
+		t.Fatalf("Create PersistentVolumeClaim: (%v)", err)
+	}
+	appsody.Spec.Storage = nil
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	warning := ""
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, "Warning PersistentVolumeClaimsAbandoned") {
+			warning = event
+		}
+	}
+	statefulSetErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.StatefulSet{})
+	toDeploymentTests := []Test{
+		{"transition", "StatefulSet/Deployment/Provisioning", getModeTransition(appsody)},
+		{"abandoned claims", pvc.Name, strings.Join(appsody.Status.ModeTransition.AbandonedClaims, ",")},
+		{"warning", true, strings.Contains(warning, pvc.Name)},
+		{"statefulset kept", nil, statefulSetErr},
+	}
+	verifyTests("to deployment", toDeploymentTests, t)
+
+	markWorkloadReady(r, req, appsodyv1beta1.ApplicationModeDeployment, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	statefulSetErr = r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.StatefulSet{})
+	headlessErr := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: name + "-headless", Namespace: namespace}, &corev1.Service{})
+	pvcErr := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: namespace}, &corev1.PersistentVolumeClaim{})
+	deploymentTests := []Test{
+		{"statefulset deleted", true, kerrors.IsNotFound(statefulSetErr)},
+		{"headless service deleted", true, kerrors.IsNotFound(headlessErr)},
+		{"claim kept", nil, pvcErr},
+	}
+	verifyTests("deployment", deploymentTests, t)
+}
@@ -0,0 +1,103 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileNetworkPolicy creates or updates the NetworkPolicy of the application when it is requested, and deletes
+// it otherwise
+func (r *ReconcileAppsodyApplication) reconcileNetworkPolicy(instance *appsodyv1beta1.AppsodyApplication) error {
+	np := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}}
+	if instance.Spec.NetworkPolicy == nil {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeNetworkPolicyComplete)
+		return r.DeleteResource(np)
+	}
+
+	consumers, err := r.getConsumers(instance)
+	if err != nil {
+		return err
+	}
+	ingressNamespaces, err := appsodyutils.GetNetworkPolicyNamespaceSelector(appsodyutils.OpConfigNetworkPolicyIngressNamespaceSelector, "ingress", r.IsOpenShift())
+	if err != nil {
+		return err
+	}
+	monitoringNamespaces, err := appsodyutils.GetNetworkPolicyNamespaceSelector(appsodyutils.OpConfigNetworkPolicyMonitoringNamespaceSelector, "monitoring", r.IsOpenShift())
+	if err != nil {
+		return err
+	}
+	r.setNetworkPolicyCompleteCondition(instance, ingressNamespaces, monitoringNamespaces)
+	return r.CreateOrUpdate(np, instance, func() error {
+		appsodyutils.CustomizeNetworkPolicy(np, instance, consumers, ingressNamespaces, monitoringNamespaces)
+		return nil
+	})
+}
+
+// setNetworkPolicyCompleteCondition sets the NetworkPolicyComplete condition to false, and records a warning event,
+// when the application is exposed or monitored but the NetworkPolicy can't allow the router or Prometheus
+func (r *ReconcileAppsodyApplication) setNetworkPolicyCompleteCondition(instance *appsodyv1beta1.AppsodyApplication, ingressNamespaces *metav1.LabelSelector, monitoringNamespaces *metav1.LabelSelector) {
+	missing := []string{}
+	if instance.Spec.Expose != nil && *instance.Spec.Expose && ingressNamespaces == nil {
+		missing = append(missing, appsodyutils.OpConfigNetworkPolicyIngressNamespaceSelector)
+	}
+	if instance.Spec.Monitoring != nil && monitoringNamespaces == nil {
+		missing = append(missing, appsodyutils.OpConfigNetworkPolicyMonitoringNamespaceSelector)
+	}
+	condition := &appsodyv1beta1.StatusCondition{Type: appsodyv1beta1.StatusConditionTypeNetworkPolicyComplete, Status: corev1.ConditionTrue}
+	if len(missing) > 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "NamespaceSelectorNotConfigured"
+		condition.Message = fmt.Sprintf("The NetworkPolicy denies the traffic from the router and Prometheus until %s is set in the operator configuration", strings.Join(missing, " and "))
+		old := instance.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeNetworkPolicyComplete))
+		if old == nil || old.GetStatus() != corev1.ConditionFalse || old.GetMessage() != condition.Message {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+	}
+	instance.Status.SetCondition(condition)
+}
+
+// getConsumers returns the applications, in the namespaces watched by the operator, that consume the service of the
+// application
+func (r *ReconcileAppsodyApplication) getConsumers(instance *appsodyv1beta1.AppsodyApplication) ([]appsodyv1beta1.AppsodyApplication, error) {
+	appList := &appsodyv1beta1.AppsodyApplicationList{}
+	if err := r.GetClient().List(context.TODO(), appList, client.InNamespace("")); err != nil {
+		return nil, err
+	}
+	consumers := []appsodyv1beta1.AppsodyApplication{}
+	for i := range appList.Items {
+		if appsodyutils.IsConsumerOf(&appList.Items[i], instance) {
+			consumers = append(consumers, appList.Items[i])
+		}
+	}
+	return consumers, nil
+}
+
+// consumedServicesMapper enqueues the applications consumed by an application, so that their NetworkPolicies allow
+// the traffic from the consumer
+var consumedServicesMapper = handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+	app, ok := obj.Object.(*appsodyv1beta1.AppsodyApplication)
+	if !ok || app.Spec.Service == nil {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, c := range app.Spec.Service.Consumes {
+		namespace := c.Namespace
+		if namespace == "" {
+			namespace = app.Namespace
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.Name, Namespace: namespace}})
+	}
+	return requests
+})
@@ -0,0 +1,97 @@
+package appsodyapplication
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, NetworkPolicy: &appsodyv1beta1.AppsodyNetworkPolicy{}}
+	appsody := createAppsodyApp(name, namespace, spec)
+	consumer := createAppsodyApp("consumer", namespace, appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:   stack,
+		Service: &appsodyv1beta1.AppsodyApplicationService{Consumes: []appsodyv1beta1.ServiceBindingConsumes{{Name: name}}},
+	})
+
+	r := newTestReconciler(t, appsody, consumer)
+	recorder := r.GetRecorder().(*record.FakeRecorder)
+
+	// The consumers of the application are allowed to reach it
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	np := &networkingv1.NetworkPolicy{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, np); err != nil {
+		t.Fatalf("Get NetworkPolicy: (%v)", err)
+	}
+	if len(np.Spec.Ingress) != 1 || len(np.Spec.Ingress[0].From) != 1 {
+		t.Fatalf("NetworkPolicy expected to allow the consumer only, actual ingress rules: (%v)", np.Spec.Ingress)
+	}
+	networkPolicyTests := []Test{
+		{"pod selector", name, np.Spec.PodSelector.MatchLabels["app.kubernetes.io/instance"]},
+		{"consumer", "consumer", np.Spec.Ingress[0].From[0].PodSelector.MatchLabels["app.kubernetes.io/instance"]},
+		{"port", int(service.Port), np.Spec.Ingress[0].Ports[0].Port.IntValue()},
+	}
+	verifyTests("network policy", networkPolicyTests, t)
+
+	// Off OpenShift, the router is not allowed until the selector of its namespaces is configured
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	for _, list := range discoveryClient.Resources {
+		if list.GroupVersion == routev1.SchemeGroupVersion.String() {
+			list.APIResources = nil
+		}
+	}
+	r.SetDiscoveryClient(discoveryClient)
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	expose := true
+	appsody.Spec.Expose = &expose
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	np = &networkingv1.NetworkPolicy{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, np); err != nil {
+		t.Fatalf("Get NetworkPolicy: (%v)", err)
+	}
+	warned := false
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, "Warning NamespaceSelectorNotConfigured") {
+			warned = true
+		}
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeNetworkPolicyComplete))
+	unconfiguredTests := []Test{
+		{"ingress rules", 1, len(np.Spec.Ingress)},
+		{"network policy complete", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", "NamespaceSelectorNotConfigured", condition.GetReason()},
+		{"warning event", true, warned},
+	}
+	verifyTests("unconfigured namespace selector", unconfiguredTests, t)
+
+	// The NetworkPolicy is deleted when it is not requested anymore
+	appsody.Spec.NetworkPolicy = nil
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, np); !kerrors.IsNotFound(err) {
+		t.Fatalf("NetworkPolicy expected to be deleted, actual error: (%v)", err)
+	}
+}
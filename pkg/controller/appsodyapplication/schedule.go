@@ -0,0 +1,134 @@
+package appsodyapplication
+
+import (
+	"fmt"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// knativeScalingNotSupported is the reason of the Reconciled condition when the application is suspended, or scaled to
+// zero by a schedule window, in Knative mode
+const knativeScalingNotSupported = "KnativeScalingNotSupported"
+
+// scaling is the replica count or autoscaling bounds of the application in effect at a point in time
+type scaling struct {
+	// replicas of the workload when autoscaling is nil
+	replicas *int32
+	// autoscaling is nil when the HorizontalPodAutoscaler must be removed
+	autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling
+	suspended   bool
+	// window is the name of the schedule window in effect, if any
+	window string
+	// scheduled is the schedule window in effect, if any
+	scheduled *appsodyv1beta1.AppsodyScheduleWindow
+	// next is the start of the next schedule window, or zero time if there is none
+	next time.Time
+}
+
+// getScaling returns the scaling of the application at the given time, applying `spec.suspend` and the schedule window
+// that started last
+func getScaling(instance *appsodyv1beta1.AppsodyApplication, now time.Time) (*scaling, error) {
+	s := &scaling{replicas: instance.Spec.Replicas, autoscaling: instance.Spec.Autoscaling}
+
+	var windowStart time.Time
+	var window *appsodyv1beta1.AppsodyScheduleWindow
+	for i := range instance.Spec.Schedule {
+		w := &instance.Spec.Schedule[i]
+		schedule, err := appsodyutils.ParseCron(w.Schedule, w.TimeZone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule of window %q", w.Name)
+		}
+		if start := schedule.Prev(now); !start.IsZero() && start.After(windowStart) {
+			windowStart, window = start, w
+		}
+		if next := schedule.Next(now); !next.IsZero() && (s.next.IsZero() || next.Before(s.next)) {
+			s.next = next
+		}
+	}
+
+	if window != nil {
+		s.window, s.scheduled = window.Name, window
+		if window.Replicas != nil {
+			s.replicas = window.Replicas
+		}
+		if s.autoscaling != nil && (window.MinReplicas != nil || window.MaxReplicas != nil) {
+			autoscaling := s.autoscaling.DeepCopy()
+			if window.MinReplicas != nil {
+				autoscaling.MinReplicas = window.MinReplicas
+			}
+			if window.MaxReplicas != nil {
+				autoscaling.MaxReplicas = *window.MaxReplicas
+			}
+			s.autoscaling = autoscaling
+		}
+	}
+
+	if instance.IsSuspended() {
+		var zero int32
+		s.replicas = &zero
+		s.autoscaling = nil
+		s.suspended = true
+	}
+	return s, nil
+}
+
+// customizeReplicas sets the replicas of a Deployment or StatefulSet. When autoscaling is enabled, the replicas are
+// left to the autoscaler, except when the workload was scaled to zero, as a HorizontalPodAutoscaler does not scale
+// up from zero.
+func (s *scaling) customizeReplicas(replicas **int32) {
+	if s.autoscaling == nil {
+		*replicas = s.replicas
+		return
+	}
+	if s.autoscaling.IsEventDriven() {
+		return
+	}
+	if *replicas != nil && **replicas == 0 {
+		minReplicas := int32(1)
+		if s.autoscaling.MinReplicas != nil {
+			minReplicas = *s.autoscaling.MinReplicas
+		}
+		*replicas = &minReplicas
+	}
+}
+
+// requeueAfter returns the time until the next schedule window starts, or zero if there is none
+func (s *scaling) requeueAfter(now time.Time) time.Duration {
+	if s.next.IsZero() {
+		return 0
+	}
+	return s.next.Sub(now)
+}
+
+// knative returns the knative configuration of the application with the scale bounds of the schedule window in
+// effect. Knative scales a revision up as soon as it receives requests, so suspending the application or a window of
+// zero replicas can't be applied to the Knative Service and returns an error.
+func (s *scaling) knative(knative *appsodyv1beta1.AppsodyKnative) (*appsodyv1beta1.AppsodyKnative, error) {
+	if s.suspended {
+		return nil, appsodyutils.NewReconcileError(knativeScalingNotSupported, "suspend is not supported with createKnativeService, as Knative scales the application up on requests")
+	}
+	if knative == nil {
+		knative = &appsodyv1beta1.AppsodyKnative{}
+	}
+	if s.scheduled == nil {
+		return knative, nil
+	}
+	w := s.scheduled
+	knative = knative.DeepCopy()
+	if w.Replicas != nil {
+		if *w.Replicas == 0 {
+			return nil, appsodyutils.NewReconcileError(knativeScalingNotSupported, fmt.Sprintf("schedule window %s scales to zero replicas, which is not supported with createKnativeService", w.Name))
+		}
+		knative.MinScale, knative.MaxScale = w.Replicas, w.Replicas
+	}
+	if w.MinReplicas != nil {
+		knative.MinScale = w.MinReplicas
+	}
+	if w.MaxReplicas != nil {
+		knative.MaxScale = w.MaxReplicas
+	}
+	return knative, nil
+}
@@ -0,0 +1,157 @@
+package appsodyapplication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestSuspendAndSchedule(t *testing.T) {
+	var minReplicas int32 = 2
+	suspend := true
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:       stack,
+		Autoscaling: &appsodyv1beta1.AppsodyApplicationAutoScaling{MinReplicas: &minReplicas, MaxReplicas: 3},
+		Suspend:     &suspend,
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	// Suspending scales the Deployment to zero and removes the HPA
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, hpa); err == nil {
+		t.Fatalf("HPA was not removed while suspended")
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	suspendTests := []Test{
+		{"replicas", int32(0), *dep.Spec.Replicas},
+		{"status suspended", true, appsody.Status.Suspended},
+	}
+	verifyTests("suspend", suspendTests, t)
+
+	// Resuming scales the Deployment back up to the minimum replicas of the HPA
+	appsody.Spec.Suspend = nil
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	dep = &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, hpa); err != nil {
+		t.Fatalf("Get HPA: (%v)", err)
+	}
+	verifyTests("resume", []Test{{"replicas", minReplicas, *dep.Spec.Replicas}}, t)
+
+	// The window that started last is in effect
+	var nightReplicas, dayMax int32 = 0, 5
+	appsody.Spec.Autoscaling = nil
+	appsody.Spec.Replicas = &replicas
+	appsody.Spec.Schedule = []appsodyv1beta1.AppsodyScheduleWindow{
+		{Name: "night", Schedule: "0 20 * * 1-5", Replicas: &nightReplicas},
+		{Name: "day", Schedule: "0 8 * * 1-5", TimeZone: "UTC", MaxReplicas: &dayMax},
+	}
+	now := time.Date(2020, time.March, 4, 22, 0, 0, 0, time.UTC)
+	sc, err := getScaling(appsody, now)
+	if err != nil {
+		t.Fatalf("getScaling: (%v)", err)
+	}
+	scheduleTests := []Test{
+		{"window", "night", sc.window},
+		{"replicas", nightReplicas, *sc.replicas},
+		{"requeue after", 10 * time.Hour, sc.requeueAfter(now)},
+	}
+	verifyTests("schedule", scheduleTests, t)
+
+	sc, err = getScaling(appsody, now.Add(12*time.Hour))
+	if err != nil {
+		t.Fatalf("getScaling: (%v)", err)
+	}
+	verifyTests("schedule", []Test{{"window", "day", sc.window}, {"replicas", replicas, *sc.replicas}}, t)
+
+	appsody.Spec.Schedule[0].Schedule = "0 25 * * *"
+	if _, err = getScaling(appsody, now); err == nil {
+		t.Errorf("getScaling with an invalid schedule expected an error")
+	}
+}
+
+func TestKnativeSuspendAndSchedule(t *testing.T) {
+	createKnativeService, suspend, maxScale := true, true, int32(3)
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:                stack,
+		CreateKnativeService: &createKnativeService,
+		Knative:              &appsodyv1beta1.AppsodyKnative{MaxScale: &maxScale},
+		Suspend:              &suspend,
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	servingGV := schema.GroupVersion{Group: appsodyutils.KnativeServingGroup, Version: "v1"}
+	addUnstructuredKinds(servingGV, "Service")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: servingGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "services", Namespaced: true, Kind: "Service", SingularName: "service"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	// Knative can't keep the application suspended, which is reported on the Reconciled condition
+	req := createReconcileRequest(name, namespace)
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("Reconcile: (%v)", err)
+	}
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionTypeReconciled)
+	suspendTests := []Test{
+		{"reconciled", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", knativeScalingNotSupported, condition.GetReason()},
+		{"status suspended", false, appsody.Status.Suspended},
+	}
+	verifyTests("knative suspend", suspendTests, t)
+
+	// The schedule window in effect sets the scale bounds of the Knative Service
+	var windowMin, windowMax, nightReplicas int32 = 2, 6, 0
+	sc := &scaling{scheduled: &appsodyv1beta1.AppsodyScheduleWindow{Name: "day", MinReplicas: &windowMin, MaxReplicas: &windowMax}}
+	knative, err := sc.knative(appsody.Spec.Knative)
+	if err != nil {
+		t.Fatalf("knative: (%v)", err)
+	}
+	scheduleTests := []Test{
+		{"min scale", windowMin, *knative.MinScale},
+		{"max scale", windowMax, *knative.MaxScale},
+		{"knative configuration unchanged", maxScale, *appsody.Spec.Knative.MaxScale},
+	}
+	verifyTests("knative schedule", scheduleTests, t)
+
+	sc.scheduled = &appsodyv1beta1.AppsodyScheduleWindow{Name: "night", Replicas: &nightReplicas}
+	if _, err = sc.knative(appsody.Spec.Knative); err == nil {
+		t.Errorf("knative with a window of zero replicas expected an error")
+	}
+}
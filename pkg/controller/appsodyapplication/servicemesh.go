@@ -0,0 +1,59 @@
+package appsodyapplication
+
+import (
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// getIstioAPIVersion returns the newest Istio networking API version of the kind supported on the cluster, or an
+// empty string
+func (r *ReconcileAppsodyApplication) getIstioAPIVersion(kind string) string {
+	for _, version := range []string{"v1beta1", "v1alpha3"} {
+		apiVersion := appsodyutils.IstioNetworkingGroup + "/" + version
+		if ok, _ := r.IsGroupVersionSupported(apiVersion, kind); ok {
+			return apiVersion
+		}
+	}
+	return ""
+}
+
+// reconcileServiceMesh creates or updates the VirtualService and the DestinationRule of the application when it is in
+// the service mesh, and deletes them otherwise
+func (r *ReconcileAppsodyApplication) reconcileServiceMesh(instance *appsodyv1beta1.AppsodyApplication, enabled bool) error {
+	customizers := map[string]func(*unstructured.Unstructured, *appsodyv1beta1.AppsodyApplication){
+		"VirtualService":  appsodyutils.CustomizeVirtualService,
+		"DestinationRule": appsodyutils.CustomizeDestinationRule,
+	}
+	for _, kind := range []string{"VirtualService", "DestinationRule"} {
+		apiVersion := r.getIstioAPIVersion(kind)
+		if apiVersion == "" {
+			if enabled {
+				return errors.New("failed to reconcile the service mesh as the operator could not find Istio CRDs")
+			}
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(instance.Name)
+		obj.SetNamespace(instance.Namespace)
+		if !enabled {
+			if err := r.DeleteResource(obj); err != nil {
+				return err
+			}
+			continue
+		}
+		customize := customizers[kind]
+		err := r.CreateOrUpdate(obj, instance, func() error {
+			customize(obj, instance)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestServiceMesh(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:       stack,
+		ServiceMesh: &appsodyv1beta1.AppsodyServiceMesh{Timeout: "5s"},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	istioGV := schema.GroupVersion{Group: appsodyutils.IstioNetworkingGroup, Version: "v1beta1"}
+	addUnstructuredKinds(istioGV, "VirtualService", "DestinationRule")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: istioGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "virtualservices", Namespaced: true, Kind: "VirtualService", SingularName: "virtualservice"},
+			{Name: "destinationrules", Namespaced: true, Kind: "DestinationRule", SingularName: "destinationrule"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	// The application joins the mesh with a VirtualService and a DestinationRule
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(istioGV.WithKind("VirtualService"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, vs); err != nil {
+		t.Fatalf("Get VirtualService: (%v)", err)
+	}
+	dr := &unstructured.Unstructured{}
+	dr.SetGroupVersionKind(istioGV.WithKind("DestinationRule"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dr); err != nil {
+		t.Fatalf("Get DestinationRule: (%v)", err)
+	}
+	svc := &corev1.Service{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("Get Service: (%v)", err)
+	}
+	deploy := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, deploy); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	httpRoutes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	httpRoute, _ := httpRoutes[0].(map[string]interface{})
+	host, _, _ := unstructured.NestedString(dr.Object, "spec", "host")
+	serviceMeshTests := []Test{
+		{"timeout", "5s", httpRoute["timeout"]},
+		{"destination host", name + "." + namespace + ".svc.cluster.local", host},
+		{"port name", fmt.Sprintf("http-%d", svc.Spec.Ports[0].Port), svc.Spec.Ports[0].Name},
+		{"sidecar injection", "true", deploy.Spec.Template.Annotations[appsodyutils.SidecarInjectAnnotation]},
+	}
+	verifyTests("service mesh", serviceMeshTests, t)
+
+	// The VirtualService and the DestinationRule are deleted when the application leaves the mesh
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.ServiceMesh = nil
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, vs); !kerrors.IsNotFound(err) {
+		t.Fatalf("VirtualService expected to be deleted, actual error: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dr); !kerrors.IsNotFound(err) {
+		t.Fatalf("DestinationRule expected to be deleted, actual error: (%v)", err)
+	}
+}
@@ -0,0 +1,283 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// podTemplateHashAnnotation records on the workload the hash of the pod template that was last rolled out
+	podTemplateHashAnnotation = "backup.appsody.dev/pod-template-hash"
+
+	// snapshotPollInterval is used to requeue while waiting for snapshots or restored claims
+	snapshotPollInterval = 5 * time.Second
+)
+
+// getVolumeSnapshotAPIVersion returns the newest VolumeSnapshot API version supported on the cluster, or an empty string
+func (r *ReconcileAppsodyApplication) getVolumeSnapshotAPIVersion() string {
+	for _, version := range []string{"v1", "v1beta1"} {
+		apiVersion := appsodyutils.VolumeSnapshotGroup + "/" + version
+		if ok, _ := r.IsGroupVersionSupported(apiVersion, "VolumeSnapshot"); ok {
+			return apiVersion
+		}
+	}
+	return ""
+}
+
+func (r *ReconcileAppsodyApplication) listVolumeSnapshots(instance *appsodyv1beta1.AppsodyApplication, apiVersion string, labels map[string]string) ([]unstructured.Unstructured, error) {
+	snapshots := &unstructured.UnstructuredList{}
+	snapshots.SetAPIVersion(apiVersion)
+	snapshots.SetKind("VolumeSnapshotList")
+	matchingLabels := client.MatchingLabels{"app.kubernetes.io/instance": instance.Name}
+	for k, v := range labels {
+		matchingLabels[k] = v
+	}
+	err := r.GetClient().List(context.TODO(), snapshots, client.InNamespace(instance.Namespace), matchingLabels)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots.Items, nil
+}
+
+// createBackup takes a snapshot of every claim of the application. It fails if the application has no claim.
+func (r *ReconcileAppsodyApplication) createBackup(instance *appsodyv1beta1.AppsodyApplication, apiVersion string, trigger string, labels map[string]string) error {
+	claims, err := r.getStorageClaims(instance)
+	if err != nil {
+		return err
+	}
+
+	backupID := appsodyutils.GetBackupID(time.Now())
+	count := 0
+	for i := range claims {
+		pvc := &claims[i]
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetAPIVersion(apiVersion)
+		snapshot.SetKind("VolumeSnapshot")
+		snapshot.SetName(appsodyutils.GetVolumeSnapshotName(pvc.Name, backupID))
+		snapshot.SetNamespace(instance.Namespace)
+		snapshot.SetLabels(labels)
+		err = r.CreateOrUpdate(snapshot, instance, func() error {
+			return appsodyutils.CustomizeVolumeSnapshot(snapshot, pvc, backupID, trigger, instance)
+		})
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("failed to take %s backup as no persistent volume claims of the application were found", trigger)
+	}
+	r.GetRecorder().Event(instance, "Normal", "BackupCreated", fmt.Sprintf("Created %s backup %s of %d persistent volume claim(s)", trigger, backupID, count))
+	return nil
+}
+
+// reconcileRolloutBackup takes snapshots of the application's claims before the pod template of the workload changes.
+// It returns false while the rollout must wait for the snapshots to be taken.
+func (r *ReconcileAppsodyApplication) reconcileRolloutBackup(instance *appsodyv1beta1.AppsodyApplication, workload runtime.Object, templateHash string) (bool, error) {
+	backup := instance.Spec.Storage.Backup
+	if backup == nil || !backup.IsBeforeRolloutEnabled() {
+		return true, nil
+	}
+
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, workload)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	lastHash, ok := workload.(metav1.Object).GetAnnotations()[podTemplateHashAnnotation]
+	if !ok || lastHash == templateHash {
+		return true, nil
+	}
+
+	apiVersion := r.getVolumeSnapshotAPIVersion()
+	if apiVersion == "" {
+		return false, errors.New("failed to take snapshots before rollout as the operator could not find VolumeSnapshot CRDs")
+	}
+	snapshots, err := r.listVolumeSnapshots(instance, apiVersion, map[string]string{appsodyutils.BackupTemplateHashLabel: templateHash})
+	if err != nil {
+		return false, err
+	}
+	if len(snapshots) == 0 {
+		return false, r.createBackup(instance, apiVersion, appsodyutils.BackupTriggerRollout, map[string]string{appsodyutils.BackupTemplateHashLabel: templateHash})
+	}
+	for i := range snapshots {
+		// The point in time of a snapshot is fixed once its creation time is set
+		if appsodyutils.GetSnapshotStatus(&snapshots[i]).CreationTime == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reconcileBackups takes scheduled snapshots, prunes backups beyond the retention count and lists snapshots in the status.
+// It returns the time until the next scheduled backup.
+func (r *ReconcileAppsodyApplication) reconcileBackups(instance *appsodyv1beta1.AppsodyApplication) (time.Duration, error) {
+	if instance.Spec.Storage == nil || instance.Spec.Storage.Backup == nil {
+		instance.Status.Snapshots = nil
+		return 0, nil
+	}
+	backup := instance.Spec.Storage.Backup
+
+	apiVersion := r.getVolumeSnapshotAPIVersion()
+	if apiVersion == "" {
+		return 0, errors.New("failed to reconcile backups as the operator could not find VolumeSnapshot CRDs")
+	}
+
+	var requeueAfter time.Duration
+	if backup.Schedule != "" {
+		schedule, err := appsodyutils.ParseCron(backup.Schedule, "")
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid storage.backup.schedule")
+		}
+		now := time.Now()
+		scheduled, err := r.listVolumeSnapshots(instance, apiVersion, map[string]string{appsodyutils.BackupTriggerLabel: appsodyutils.BackupTriggerScheduled})
+		if err != nil {
+			return 0, err
+		}
+		last := schedule.Prev(now)
+		taken := false
+		for i := range scheduled {
+			t, err := appsodyutils.ParseBackupID(scheduled[i].GetLabels()[appsodyutils.BackupIDLabel])
+			if err == nil && !t.Before(last) {
+				taken = true
+			}
+		}
+		if !last.IsZero() && !taken {
+			if err = r.createBackup(instance, apiVersion, appsodyutils.BackupTriggerScheduled, nil); err != nil {
+				return 0, err
+			}
+		}
+		if next := schedule.Next(now); !next.IsZero() {
+			requeueAfter = next.Sub(now)
+		}
+	}
+
+	snapshots, err := r.listVolumeSnapshots(instance, apiVersion, nil)
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		idI, idJ := snapshots[i].GetLabels()[appsodyutils.BackupIDLabel], snapshots[j].GetLabels()[appsodyutils.BackupIDLabel]
+		if idI != idJ {
+			return idI > idJ
+		}
+		return snapshots[i].GetName() < snapshots[j].GetName()
+	})
+
+	statuses := []appsodyv1beta1.StatusSnapshot{}
+	backupIDs := []string{}
+	for i := range snapshots {
+		id := snapshots[i].GetLabels()[appsodyutils.BackupIDLabel]
+		if len(backupIDs) == 0 || backupIDs[len(backupIDs)-1] != id {
+			backupIDs = append(backupIDs, id)
+		}
+		// Never delete the backup that is being restored
+		if backup.Retention != nil && len(backupIDs) > int(*backup.Retention) && id != backup.RestoreFrom {
+			if err = r.DeleteResource(&snapshots[i]); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		statuses = append(statuses, appsodyutils.GetSnapshotStatus(&snapshots[i]))
+	}
+	instance.Status.Snapshots = statuses
+	return requeueAfter, nil
+}
+
+// reconcileRestore recreates the application's claims from the snapshots of the backup set in `storage.backup.restoreFrom`.
+// The workload is deleted first so that the claims are released. It returns false while the restore is in progress.
+func (r *ReconcileAppsodyApplication) reconcileRestore(instance *appsodyv1beta1.AppsodyApplication) (bool, error) {
+	if instance.Spec.Storage == nil || instance.Spec.Storage.Backup == nil {
+		return true, nil
+	}
+	restoreFrom := instance.Spec.Storage.Backup.RestoreFrom
+	if restoreFrom == "" || restoreFrom == instance.Status.RestoredFrom {
+		return true, nil
+	}
+
+	apiVersion := r.getVolumeSnapshotAPIVersion()
+	if apiVersion == "" {
+		return false, errors.New("failed to restore backup as the operator could not find VolumeSnapshot CRDs")
+	}
+	snapshots, err := r.listVolumeSnapshots(instance, apiVersion, map[string]string{appsodyutils.BackupIDLabel: restoreFrom})
+	if err != nil {
+		return false, err
+	}
+	if len(snapshots) == 0 {
+		return false, fmt.Errorf("failed to restore backup %q as no volume snapshots were found for it", restoreFrom)
+	}
+	for i := range snapshots {
+		if !appsodyutils.GetSnapshotStatus(&snapshots[i]).ReadyToUse {
+			return false, nil
+		}
+	}
+
+	defaultMeta := metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}
+	err = r.DeleteResources([]runtime.Object{
+		&appsv1.StatefulSet{ObjectMeta: defaultMeta},
+		&appsv1.Deployment{ObjectMeta: defaultMeta},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	done := true
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		pvc, err := appsodyutils.NewClaimFromSnapshot(snapshot)
+		if err != nil {
+			return false, err
+		}
+
+		existing := &corev1.PersistentVolumeClaim{}
+		err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, existing)
+		if err == nil {
+			if existing.Spec.DataSource != nil && existing.Spec.DataSource.Name == snapshot.GetName() {
+				continue
+			}
+			// The claim is released once the pods of the deleted workload are gone
+			if existing.DeletionTimestamp == nil {
+				if err = r.DeleteResource(existing); err != nil {
+					return false, err
+				}
+			}
+			done = false
+			continue
+		} else if !kerrors.IsNotFound(err) {
+			return false, err
+		}
+
+		if err = r.GetClient().Create(context.TODO(), pvc); err != nil {
+			return false, err
+		}
+	}
+	if !done {
+		return false, nil
+	}
+
+	instance.Status.RestoredFrom = restoreFrom
+	if err = r.UpdateStatus(instance); err != nil {
+		return false, err
+	}
+	r.GetRecorder().Event(instance, "Normal", "BackupRestored", fmt.Sprintf("Restored persistent volume claims from backup %s", restoreFrom))
+	return true, nil
+}
@@ -0,0 +1,175 @@
+package appsodyapplication
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+func TestStorageBackup(t *testing.T) {
+	var retention int32 = 1
+	// The claims created from a volume claim template of the user don't have the labels of the application
+	claimTemplate := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data"},
+		Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+	}
+	backupStorage := &appsodyv1beta1.AppsodyApplicationStorage{
+		Size:                "10Mi",
+		MountPath:           "/mnt/data",
+		VolumeClaimTemplate: claimTemplate,
+		Backup:              &appsodyv1beta1.AppsodyStorageBackup{Retention: &retention},
+	}
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, ApplicationImage: appImage, Storage: backupStorage}
+	appsody := createAppsodyApp(name, namespace, spec)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-" + name + "-0", Namespace: namespace},
+		Spec:       claimTemplate.Spec,
+	}
+	// Claims of other StatefulSets are not backed up
+	otherPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-" + name + "-db-0", Namespace: namespace},
+		Spec:       claimTemplate.Spec,
+	}
+
+	snapshotGV := schema.GroupVersion{Group: appsodyutils.VolumeSnapshotGroup, Version: "v1beta1"}
+	addUnstructuredKinds(snapshotGV, "VolumeSnapshot")
+	r := newTestReconciler(t, appsody, pvc, otherPVC)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: snapshotGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "volumesnapshots", Namespaced: true, Kind: "VolumeSnapshot", SingularName: "volumesnapshot"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	// Changing the pod template waits for a snapshot of the claims before the rollout
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.ApplicationImage = ksvcAppImage
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	verifyTests("rollout wait", []Test{{"requeue after", snapshotPollInterval, res.RequeueAfter}}, t)
+
+	snapshots, err := r.listVolumeSnapshots(appsody, snapshotGV.String(), nil)
+	if err != nil {
+		t.Fatalf("List VolumeSnapshots: (%v)", err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("expected 1 VolumeSnapshot, found %d", len(snapshots))
+	}
+	snapshot := &snapshots[0]
+	backupID := snapshot.GetLabels()[appsodyutils.BackupIDLabel]
+	claimName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	statefulSet := &appsv1.StatefulSet{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, statefulSet); err != nil {
+		t.Fatalf("Get StatefulSet: (%v)", err)
+	}
+
+	rolloutTests := []Test{
+		{"snapshot claim", pvc.Name, claimName},
+		{"snapshot trigger", appsodyutils.BackupTriggerRollout, snapshot.GetLabels()[appsodyutils.BackupTriggerLabel]},
+		{"previous image", appImage, statefulSet.Spec.Template.Spec.Containers[0].Image},
+	}
+	verifyTests("rollout backup", rolloutTests, t)
+
+	// The rollout continues once the snapshot is taken
+	unstructured.SetNestedField(snapshot.Object, "2020-03-04T10:30:00Z", "status", "creationTime")
+	unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse")
+	if err = r.GetClient().Update(context.TODO(), snapshot); err != nil {
+		t.Fatalf("Update VolumeSnapshot: (%v)", err)
+	}
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	statefulSet = &appsv1.StatefulSet{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, statefulSet); err != nil {
+		t.Fatalf("Get StatefulSet: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	statusTests := []Test{
+		{"rolled out image", ksvcAppImage, statefulSet.Spec.Template.Spec.Containers[0].Image},
+		{"status snapshots", 1, len(appsody.Status.Snapshots)},
+	}
+	verifyTests("backup status", statusTests, t)
+
+	// Restoring recreates the claims from the snapshots
+	appsody.Spec.Storage.Backup.RestoreFrom = backupID
+	updateAppsody(r, appsody, t)
+
+	for i := 0; i < 2; i++ {
+		res, err = r.Reconcile(req)
+		if err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+	}
+	verifyReconcile(res, err, t)
+
+	restored := &corev1.PersistentVolumeClaim{}
+	if err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: namespace}, restored); err != nil {
+		t.Fatalf("Get PersistentVolumeClaim: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if restored.Spec.DataSource == nil {
+		t.Fatalf("PersistentVolumeClaim was not restored from a snapshot")
+	}
+	restoreTests := []Test{
+		{"data source", snapshot.GetName(), restored.Spec.DataSource.Name},
+		{"restored from", backupID, appsody.Status.RestoredFrom},
+	}
+	verifyTests("restore", restoreTests, t)
+
+	// The rollout fails when the backup finds no claim of the application
+	if err = r.GetClient().Delete(context.TODO(), restored); err != nil {
+		t.Fatalf("Delete PersistentVolumeClaim: (%v)", err)
+	}
+	appsody.Spec.ApplicationImage = appImage
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	statefulSet = &appsv1.StatefulSet{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, statefulSet); err != nil {
+		t.Fatalf("Get StatefulSet: (%v)", err)
+	}
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionTypeReconciled)
+	noClaimTests := []Test{
+		{"reconciled", corev1.ConditionFalse, condition.GetStatus()},
+		{"message", true, strings.Contains(condition.GetMessage(), "no persistent volume claims")},
+		{"image", ksvcAppImage, statefulSet.Spec.Template.Spec.Containers[0].Image},
+	}
+	verifyTests("no claim", noClaimTests, t)
+}
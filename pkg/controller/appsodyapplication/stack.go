@@ -0,0 +1,111 @@
+package appsodyapplication
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// getStackPolicies returns the names of the stack policies that apply to the namespace of the application, sorted
+func (r *ReconcileAppsodyApplication) getStackPolicies(instance *appsodyv1beta1.AppsodyApplication) []string {
+	if len(r.StackPolicies) == 0 {
+		return nil
+	}
+	// Namespace selectors don't match when the operator is not allowed to read namespaces
+	namespace := &corev1.Namespace{}
+	if err := r.GetClient().Get(context.Background(), types.NamespacedName{Name: instance.Namespace}, namespace); err != nil {
+		log.V(1).Info("Failed to get the labels of namespace "+instance.Namespace, "error", err.Error())
+	}
+	names := []string{}
+	for name, policy := range r.StackPolicies {
+		if policy.AppliesTo(instance.Namespace, namespace.Labels) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reconcileStackCompliance checks the stack set in `stack` and the stack detected from the application image against the
+// stack policies that apply to the application. The StackCompliant condition reports the result. It returns an error
+// when a policy blocking the application is violated, and the time until a stack of the application is deprecated.
+func (r *ReconcileAppsodyApplication) reconcileStackCompliance(instance *appsodyv1beta1.AppsodyApplication, now time.Time) (time.Duration, error) {
+	policies := r.getStackPolicies(instance)
+	if len(policies) == 0 {
+		removeCondition(instance, appsodyv1beta1.StatusConditionTypeStackCompliant)
+		return 0, nil
+	}
+
+	// Applications without a stack use the generic stack defaults
+	stacks := map[string]string{"generic": ""}
+	if stack := instance.GetStack(); stack != "" {
+		stacks = map[string]string{stack: ""}
+	}
+	if detected := instance.Status.DetectedStack; detected != nil && detected.ID != "" {
+		stacks[detected.ID] = detected.Version
+	}
+	ids := []string{}
+	for id := range stacks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	violations, block := []string{}, false
+	reason := ""
+	var deprecation time.Time
+	for _, name := range policies {
+		policy := r.StackPolicies[name]
+		for _, id := range ids {
+			compliance := policy.Check(id, stacks[id], now)
+			if compliance.Reason != "" {
+				if reason == "" {
+					reason = compliance.Reason
+				}
+				violations = append(violations, fmt.Sprintf("%s (stack policy %s)", compliance.Message, name))
+				block = block || policy.Enforcement == appsodyutils.StackPolicyEnforcementBlock
+			} else if !compliance.Deprecation.IsZero() && (deprecation.IsZero() || compliance.Deprecation.Before(deprecation)) {
+				deprecation = compliance.Deprecation
+			}
+		}
+	}
+
+	old := instance.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeStackCompliant))
+	if len(violations) > 0 {
+		condition := &appsodyv1beta1.StatusCondition{
+			Type:    appsodyv1beta1.StatusConditionTypeStackCompliant,
+			Status:  corev1.ConditionFalse,
+			Reason:  reason,
+			Message: strings.Join(violations, "; "),
+		}
+		if old == nil || old.GetStatus() != corev1.ConditionFalse || old.GetMessage() != condition.Message {
+			r.GetRecorder().Event(instance, "Warning", condition.Reason, condition.Message)
+		}
+		instance.Status.SetCondition(condition)
+		if block {
+			return 0, fmt.Errorf("stack is not allowed by the stack policies: %s", condition.Message)
+		}
+		return 0, nil
+	}
+
+	condition := &appsodyv1beta1.StatusCondition{
+		Type:   appsodyv1beta1.StatusConditionTypeStackCompliant,
+		Status: corev1.ConditionTrue,
+	}
+	if deprecation.IsZero() {
+		instance.Status.SetCondition(condition)
+		return 0, nil
+	}
+	condition.Reason = "DeprecationScheduled"
+	condition.Message = fmt.Sprintf("A stack of the application is deprecated on %s", deprecation.Format("2006-01-02"))
+	instance.Status.SetCondition(condition)
+	// Reconcile again when the stack becomes deprecated
+	return deprecation.Sub(now), nil
+}
@@ -0,0 +1,158 @@
+package appsodyapplication
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStackDetection(t *testing.T) {
+	config := []byte(`{"architecture":"amd64","os":"linux","config":{"Labels":{"dev.appsody.stack.id":"nodejs-express","dev.appsody.stack.version":"0.4.2"}}}`)
+	sum := sha256.Sum256(config)
+	configDigest := "sha256:" + hex.EncodeToString(sum[:])
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ns/app/manifests/1.0":
+			fmt.Fprintf(w, `{"schemaVersion":2,"config":{"digest":"%s"},"layers":[]}`, configDigest)
+		case "/v2/ns/app/blobs/" + configDigest:
+			w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defaultRegistryClient := registryClient
+	registryClient = server.Client()
+	defer func() { registryClient = defaultRegistryClient }()
+
+	detect := true
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		ApplicationImage: strings.TrimPrefix(server.URL, "https://") + "/ns/app:1.0",
+		DetectStack:      &detect,
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	nodeService := &appsodyv1beta1.AppsodyApplicationService{Type: &serviceType, Port: 3000}
+	r := newTestReconciler(t, appsody)
+	recorder := r.GetRecorder().(*record.FakeRecorder)
+	r.StackDefaults = map[string]appsodyv1beta1.AppsodyApplicationSpec{genStack: {Service: genService}, "nodejs-express": {Service: nodeService}}
+
+	// The defaults of the detected stack are used when no stack is set
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if appsody.Status.DetectedStack == nil {
+		t.Fatalf("status.detectedStack was not set")
+	}
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	detectTests := []Test{
+		{"detected stack", "nodejs-express", appsody.Status.DetectedStack.ID},
+		{"detected version", "0.4.2", appsody.Status.DetectedStack.Version},
+		{"stack defaults", int32(3000), appsody.Spec.Service.Port},
+		{"stack label", "nodejs-express", dep.Labels["stack.appsody.dev/id"]},
+		{"stack version label", "0.4.2", dep.Labels["stack.appsody.dev/version"]},
+		{"spec stack", "", appsody.Spec.Stack},
+	}
+	verifyTests("stack detection", detectTests, t)
+
+	// A declared stack that differs from the image labels is flagged
+	appsody.Spec.Stack = "java-microprofile"
+	if err = r.GetClient().Update(context.TODO(), appsody); err != nil {
+		t.Fatalf("Update appsody: (%v)", err)
+	}
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeStackMatched))
+	if condition == nil || condition.GetStatus() != corev1.ConditionFalse {
+		t.Fatalf("StackMatched condition expected to be false, actual: (%v)", condition)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "StackMismatch") {
+			t.Errorf("Unexpected event: %s", event)
+		}
+	default:
+		t.Errorf("Expected a StackMismatch event")
+	}
+}
+
+func TestStackPolicy(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, ApplicationImage: appImage}
+	appsody := createAppsodyApp(name, namespace, spec)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"env": "prod"}}}
+	deprecation := time.Now().AddDate(0, 1, 0).UTC().Format("2006-01-02")
+	stackPolicy := createConfigMap("appsody-operator-stack-policy", namespace, map[string]string{
+		"prod": "namespaceSelector:\n  matchLabels:\n    env: prod\nenforcement: Block\nstacks:\n- id: nodejs-express\n  deprecationDate: \"" + deprecation + "\"",
+	})
+
+	r := newTestReconciler(t, appsody, ns, stackPolicy)
+	r.StackDefaults = map[string]appsodyv1beta1.AppsodyApplicationSpec{stack: {Service: service}, "nodejs-express": {Service: service}}
+
+	// A stack that is not allowed by a blocking policy is not deployed
+	req := createReconcileRequest(name, namespace)
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if err := r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{}); !kerrors.IsNotFound(err) {
+		t.Fatalf("Deployment of a stack that is not allowed expected to be missing, actual error: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeStackCompliant))
+	if condition == nil {
+		t.Fatalf("StackCompliant condition was not set")
+	}
+	blockedTests := []Test{
+		{"stack compliant", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", "StackNotAllowed", condition.GetReason()},
+	}
+	verifyTests("blocked stack", blockedTests, t)
+
+	// An allowed stack is deployed and reconciled again when it is deprecated
+	appsody.Spec.Stack = "nodejs-express"
+	updateAppsody(r, appsody, t)
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{}); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	condition = appsody.Status.GetCondition(common.StatusConditionType(appsodyv1beta1.StatusConditionTypeStackCompliant))
+	allowedTests := []Test{
+		{"stack compliant", corev1.ConditionTrue, condition.GetStatus()},
+		{"reason", "DeprecationScheduled", condition.GetReason()},
+		{"requeue", true, res.RequeueAfter > 0 && res.RequeueAfter <= 32*24*time.Hour},
+	}
+	verifyTests("allowed stack", allowedTests, t)
+}
@@ -0,0 +1,123 @@
+package appsodyapplication
+
+import (
+	"context"
+	"sort"
+
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileSharedStorage creates or updates the volume claim shared by all pods of the Deployment.
+// The claim is owned by the CR only when its reclaim policy is Delete, so that it is garbage collected
+// along with the CR. Claims that are no longer used are deleted only if they are owned by the CR.
+func (r *ReconcileAppsodyApplication) reconcileSharedStorage(instance *appsodyv1beta1.AppsodyApplication) error {
+	claimName := ""
+	if instance.Spec.Storage != nil && instance.Spec.Storage.IsShared() {
+		claimName = appsodyutils.GetSharedStorageClaimName(instance)
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: claimName, Namespace: instance.Namespace}}
+		err := r.CreateOrUpdate(pvc, nil, func() error {
+			appsodyutils.CustomizeSharedPersistentVolumeClaim(pvc, instance)
+			if instance.Spec.Storage.ReclaimPolicy == appsodyv1beta1.StorageReclaimPolicyDelete {
+				ownerRef, err := r.AsOwner(instance, true)
+				if err != nil {
+					return err
+				}
+				oputils.EnsureOwnerRef(pvc, ownerRef)
+			} else {
+				pvc.OwnerReferences = removeOwnerRef(pvc.OwnerReferences, instance)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	err := r.GetClient().List(context.TODO(), pvcList, client.InNamespace(instance.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/instance":    instance.Name,
+		appsodyutils.SharedStorageLabel: "true",
+	})
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if pvc.Name == claimName || !isOwnedBy(pvc, instance) {
+			continue
+		}
+		if err = r.DeleteResource(pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getStatefulSetClaims returns the volume claims created from the volume claim templates of the StatefulSet of the
+// application, sorted by name. The claims are matched by name, as the claims created from a volume claim template of
+// the user don't have the labels of the application.
+func (r *ReconcileAppsodyApplication) getStatefulSetClaims(instance *appsodyv1beta1.AppsodyApplication) ([]corev1.PersistentVolumeClaim, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	err := r.GetClient().Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, statefulSet)
+	if err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err = r.GetClient().List(context.TODO(), pvcList, client.InNamespace(instance.Namespace)); err != nil {
+		return nil, err
+	}
+	claims := []corev1.PersistentVolumeClaim{}
+	for _, pvc := range pvcList.Items {
+		if appsodyutils.IsStatefulSetClaim(pvc.Name, statefulSet) {
+			claims = append(claims, pvc)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Name < claims[j].Name })
+	return claims, nil
+}
+
+// getStorageClaims returns the volume claims holding the data of the application: the claims of the pods of its
+// StatefulSet, and its shared claim
+func (r *ReconcileAppsodyApplication) getStorageClaims(instance *appsodyv1beta1.AppsodyApplication) ([]corev1.PersistentVolumeClaim, error) {
+	claims, err := r.getStatefulSetClaims(instance)
+	if err != nil {
+		return nil, err
+	}
+	if instance.Spec.Storage != nil && instance.Spec.Storage.IsShared() {
+		pvc := corev1.PersistentVolumeClaim{}
+		err = r.GetClient().Get(context.TODO(), types.NamespacedName{Name: appsodyutils.GetSharedStorageClaimName(instance), Namespace: instance.Namespace}, &pvc)
+		if err == nil {
+			claims = append(claims, pvc)
+		} else if !kerrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+func isOwnedBy(obj metav1.Object, owner metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+func removeOwnerRef(refs []metav1.OwnerReference, owner metav1.Object) []metav1.OwnerReference {
+	result := []metav1.OwnerReference{}
+	for _, ref := range refs {
+		if ref.UID != owner.GetUID() {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package appsodyapplication
+
+import (
+	"context"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSharedStorage(t *testing.T) {
+	sharedStorage := &appsodyv1beta1.AppsodyApplicationStorage{
+		Size:          "10Mi",
+		MountPath:     "/mnt/data",
+		Mode:          appsodyv1beta1.StorageModeShared,
+		ReclaimPolicy: appsodyv1beta1.StorageReclaimPolicyDelete,
+	}
+	spec := appsodyv1beta1.AppsodyApplicationSpec{Stack: stack, Storage: sharedStorage, Autoscaling: autoscaling}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	r := newTestReconciler(t, appsody)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	// Shared storage is mounted into a Deployment instead of a StatefulSet
+	dep := &appsv1.Deployment{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("Get Deployment: (%v)", err)
+	}
+	statefulSet := &appsv1.StatefulSet{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, statefulSet); err == nil {
+		t.Fatalf("StatefulSet was created for shared storage")
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvcName := types.NamespacedName{Name: "pvc-" + name, Namespace: namespace}
+	if err = r.GetClient().Get(context.TODO(), pvcName, pvc); err != nil {
+		t.Fatalf("Get PersistentVolumeClaim: (%v)", err)
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, hpa); err != nil {
+		t.Fatalf("Get HPA: (%v)", err)
+	}
+
+	sharedTests := []Test{
+		{"access mode", corev1.ReadWriteMany, pvc.Spec.AccessModes[0]},
+		{"owner references", 1, len(pvc.OwnerReferences)},
+		{"volume claim", pvc.Name, dep.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName},
+		{"mount path", sharedStorage.MountPath, dep.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath},
+		{"hpa target kind", "Deployment", hpa.Spec.ScaleTargetRef.Kind},
+	}
+	verifyTests("shared storage", sharedTests, t)
+
+	// Retain policy removes the owner reference so the claim outlives the CR
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.Storage.ReclaimPolicy = appsodyv1beta1.StorageReclaimPolicyRetain
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	pvc = &corev1.PersistentVolumeClaim{}
+	if err = r.GetClient().Get(context.TODO(), pvcName, pvc); err != nil {
+		t.Fatalf("Get PersistentVolumeClaim: (%v)", err)
+	}
+	verifyTests("retained storage", []Test{{"owner references", 0, len(pvc.OwnerReferences)}}, t)
+
+	// Removing storage keeps the retained claim
+	appsody.Spec.Storage = nil
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), pvcName, pvc); err != nil {
+		t.Fatalf("Retained PersistentVolumeClaim was deleted: (%v)", err)
+	}
+}
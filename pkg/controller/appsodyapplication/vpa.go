@@ -0,0 +1,52 @@
+package appsodyapplication
+
+import (
+	"fmt"
+	"strings"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// reconcileVerticalPodAutoscaler creates or updates the VerticalPodAutoscaler of the application and reports its
+// recommendation in the status. A warning event is emitted when a new recommendation differs a lot from the requested resources.
+func (r *ReconcileAppsodyApplication) reconcileVerticalPodAutoscaler(instance *appsodyv1beta1.AppsodyApplication) error {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetAPIVersion(appsodyutils.VerticalPodAutoscalerAPIVersion)
+	vpa.SetKind("VerticalPodAutoscaler")
+	vpa.SetName(instance.Name)
+	vpa.SetNamespace(instance.Namespace)
+
+	ok, _ := r.IsGroupVersionSupported(appsodyutils.VerticalPodAutoscalerAPIVersion, "VerticalPodAutoscaler")
+	if instance.Spec.ResourceRecommendation == nil {
+		instance.Status.ResourceRecommendation = nil
+		if ok {
+			return r.DeleteResource(vpa)
+		}
+		return nil
+	}
+	if !ok {
+		return errors.New("failed to reconcile resource recommendation as the operator could not find VerticalPodAutoscaler CRDs")
+	}
+
+	err := r.CreateOrUpdate(vpa, instance, func() error {
+		appsodyutils.CustomizeVerticalPodAutoscaler(vpa, instance)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	recommendation := appsodyutils.GetResourceRecommendation(vpa, instance.Spec.ResourceConstraints)
+	if recommendation != nil && !equality.Semantic.DeepEqual(recommendation, instance.Status.ResourceRecommendation) {
+		differences := appsodyutils.GetRecommendationDifferences(recommendation, instance.Spec.ResourceConstraints, instance.Spec.ResourceRecommendation.GetTolerancePercentage())
+		if len(differences) > 0 {
+			r.GetRecorder().Event(instance, "Warning", "ResourceRecommendationDiffers", fmt.Sprintf("Requested resources differ from the recommendation: %s", strings.Join(differences, ", ")))
+		}
+	}
+	instance.Status.ResourceRecommendation = recommendation
+	return nil
+}
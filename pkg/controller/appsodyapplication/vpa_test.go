@@ -0,0 +1,171 @@
+package appsodyapplication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsodyutils "github.com/appsody/appsody-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestResourceRecommendation(t *testing.T) {
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+	}
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:                  stack,
+		ResourceConstraints:    resources,
+		ResourceRecommendation: &appsodyv1beta1.AppsodyResourceRecommendation{},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	vpaGV := schema.GroupVersion{Group: "autoscaling.k8s.io", Version: "v1"}
+	addUnstructuredKinds(vpaGV, "VerticalPodAutoscaler")
+	r := newTestReconciler(t, appsody)
+	recorder := r.GetRecorder().(*record.FakeRecorder)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: vpaGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "verticalpodautoscalers", Namespaced: true, Kind: "VerticalPodAutoscaler", SingularName: "verticalpodautoscaler"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGV.WithKind("VerticalPodAutoscaler"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, vpa); err != nil {
+		t.Fatalf("Get VerticalPodAutoscaler: (%v)", err)
+	}
+	updateMode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+	verifyTests("vpa", []Test{{"update mode", "Off", updateMode}}, t)
+
+	// The recommendation is reported in the status with limits in proportion to the requests
+	recommendations := []interface{}{
+		map[string]interface{}{"containerName": "app", "target": map[string]interface{}{"cpu": "300m"}},
+	}
+	unstructured.SetNestedSlice(vpa.Object, recommendations, "status", "recommendation", "containerRecommendations")
+	if err = r.GetClient().Update(context.TODO(), vpa); err != nil {
+		t.Fatalf("Update VerticalPodAutoscaler: (%v)", err)
+	}
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	if appsody.Status.ResourceRecommendation == nil {
+		t.Fatalf("status.resourceRecommendation was not set")
+	}
+	cpuRequest := appsody.Status.ResourceRecommendation.Requests[corev1.ResourceCPU]
+	cpuLimit := appsody.Status.ResourceRecommendation.Limits[corev1.ResourceCPU]
+	recommendationTests := []Test{
+		{"cpu request", "300m", cpuRequest.String()},
+		{"cpu limit", "600m", cpuLimit.String()},
+		{"warning", true, len(recorder.Events) > 0},
+	}
+	verifyTests("resource recommendation", recommendationTests, t)
+
+	// Auto mode can't be combined with CPU-based autoscaling
+	appsody.Spec.ResourceRecommendation.UpdateMode = appsodyv1beta1.ResourceRecommendationUpdateModeAuto
+	appsody.Spec.Autoscaling = &appsodyv1beta1.AppsodyApplicationAutoScaling{MaxReplicas: 3}
+	updateAppsody(r, appsody, t)
+
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionTypeReconciled)
+	conflictTests := []Test{
+		{"reconciled", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", appsodyutils.ResourceRecommendationConflict, condition.GetReason()},
+	}
+	verifyTests("auto mode with cpu autoscaling", conflictTests, t)
+}
+
+func TestResourceRecommendationKnative(t *testing.T) {
+	spec := appsodyv1beta1.AppsodyApplicationSpec{
+		Stack:                  stack,
+		ResourceRecommendation: &appsodyv1beta1.AppsodyResourceRecommendation{},
+	}
+	appsody := createAppsodyApp(name, namespace, spec)
+
+	vpaGV := schema.GroupVersion{Group: "autoscaling.k8s.io", Version: "v1"}
+	addUnstructuredKinds(vpaGV, "VerticalPodAutoscaler")
+	r := newTestReconciler(t, appsody)
+	discoveryClient := createFakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: vpaGV.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "verticalpodautoscalers", Namespaced: true, Kind: "VerticalPodAutoscaler", SingularName: "verticalpodautoscaler"},
+		},
+	})
+	r.SetDiscoveryClient(discoveryClient)
+
+	req := createReconcileRequest(name, namespace)
+	res, err := r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(vpaGV.WithKind("VerticalPodAutoscaler"))
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, vpa); err != nil {
+		t.Fatalf("Get VerticalPodAutoscaler: (%v)", err)
+	}
+
+	// Resource recommendation can't be combined with a Knative Service
+	createKnativeService := true
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	appsody.Spec.CreateKnativeService = &createKnativeService
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	appsody = &appsodyv1beta1.AppsodyApplication{}
+	if err = r.GetClient().Get(context.TODO(), req.NamespacedName, appsody); err != nil {
+		t.Fatalf("Get appsody: (%v)", err)
+	}
+	condition := appsody.Status.GetCondition(common.StatusConditionTypeReconciled)
+	notSupportedTests := []Test{
+		{"reconciled", corev1.ConditionFalse, condition.GetStatus()},
+		{"reason", appsodyutils.ResourceRecommendationNotSupported, condition.GetReason()},
+	}
+	verifyTests("resource recommendation with knative", notSupportedTests, t)
+
+	// Without resource recommendation, the VerticalPodAutoscaler is deleted once the Knative Service is ready
+	appsody.Spec.ResourceRecommendation = nil
+	updateAppsody(r, appsody, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	markKnativeRevisionReady(r, req, t)
+	res, err = r.Reconcile(req)
+	verifyModeTransition(res, err, t)
+
+	createKnativeRouteService(r, req, t)
+	markKnativeServiceReady(r, req, t)
+	res, err = r.Reconcile(req)
+	verifyReconcile(res, err, t)
+
+	vpaErr := r.GetClient().Get(context.TODO(), req.NamespacedName, vpa)
+	verifyTests("knative", []Test{{"vpa deleted", true, kerrors.IsNotFound(vpaErr)}}, t)
+}
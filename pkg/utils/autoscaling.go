@@ -0,0 +1,80 @@
+package utils
+
+import (
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// HorizontalPodAutoscalerV2beta2 is the API version of HorizontalPodAutoscalers with memory and custom metrics
+const HorizontalPodAutoscalerV2beta2 = "autoscaling/v2beta2"
+
+// GetScaleTargetKind returns the kind of the workload scaled by the HorizontalPodAutoscaler
+func GetScaleTargetKind(cr *appsodyv1beta1.AppsodyApplication) string {
+	if cr.Spec.Storage != nil && !cr.Spec.Storage.IsShared() {
+		return "StatefulSet"
+	}
+	return "Deployment"
+}
+
+// GetMetrics returns the metrics of an autoscaling/v2beta2 HorizontalPodAutoscaler
+func GetMetrics(autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling) []autoscalingv2beta2.MetricSpec {
+	targets := []struct {
+		name   corev1.ResourceName
+		target *int32
+	}{
+		{corev1.ResourceCPU, autoscaling.TargetCPUUtilizationPercentage},
+		{corev1.ResourceMemory, autoscaling.TargetMemoryUtilizationPercentage},
+	}
+	metrics := []autoscalingv2beta2.MetricSpec{}
+	for _, t := range targets {
+		if t.target == nil {
+			continue
+		}
+		metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: t.name,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: t.target,
+				},
+			},
+		})
+	}
+	return append(metrics, autoscaling.Metrics...)
+}
+
+// CustomizeHorizontalPodAutoscaler sets up an autoscaling/v2beta2 HorizontalPodAutoscaler. The HPA is unstructured
+// so that `spec.behavior` is kept on clusters that support it.
+func CustomizeHorizontalPodAutoscaler(hpa *unstructured.Unstructured, autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling, cr *appsodyv1beta1.AppsodyApplication) error {
+	hpa.SetLabels(cr.GetLabels())
+	hpa.SetAnnotations(oputils.MergeMaps(hpa.GetAnnotations(), cr.GetAnnotations()))
+
+	hpaSpec := autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       GetScaleTargetKind(cr),
+			Name:       cr.Name,
+		},
+		MinReplicas: autoscaling.GetMinReplicas(),
+		MaxReplicas: autoscaling.GetMaxReplicas(),
+	}
+	if metrics := GetMetrics(autoscaling); len(metrics) > 0 {
+		hpaSpec.Metrics = metrics
+	}
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&hpaSpec)
+	if err != nil {
+		return err
+	}
+	if autoscaling.Behavior != nil {
+		if spec["behavior"], err = runtime.DefaultUnstructuredConverter.ToUnstructured(autoscaling.Behavior); err != nil {
+			return err
+		}
+	}
+	hpa.Object["spec"] = spec
+	return nil
+}
@@ -0,0 +1,377 @@
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// OpConfigCertificateAuthority is the operator configuration selecting the CA that issues the certificates of the
+	// applications when cert-manager is not installed: `namespace` (default) for a CA per namespace, or `cluster` for
+	// a single CA in the namespace of the operator
+	OpConfigCertificateAuthority = "certificateAuthority"
+
+	// CertificateAuthorityCluster is the value of the certificateAuthority operator configuration for a single CA
+	CertificateAuthorityCluster = "cluster"
+
+	// CertificateAuthoritySecretName is the name of the secret of the CA of the operator
+	CertificateAuthoritySecretName = "appsody-operator-ca"
+
+	// OperatorCALabel marks the secrets of the certificates issued by the CA of the operator
+	OperatorCALabel = "certificate.appsody.dev/operator-ca"
+
+	// Validity of the CA of the operator. The CA is renewed a year before it expires, and the certificates it issued
+	// are issued again by the new CA once the new CA has been trusted for caRolloutPeriod.
+	caDuration      = 10 * 365 * 24 * time.Hour
+	caRenewBefore   = 365 * 24 * time.Hour
+	caRolloutPeriod = 7 * 24 * time.Hour
+
+	// Keys of the secret of the CA holding the renewed CA during its rollout period
+	caNextCertKey = "next.crt"
+	caNextKeyKey  = "next.key"
+
+	// Default validity of the certificates, as set by ReconcileCertificate for cert-manager
+	defaultCertificateDuration    = 365 * 24 * time.Hour
+	defaultCertificateRenewBefore = 31 * 24 * time.Hour
+)
+
+// CertificateRequest describes a certificate of the application issued by the CA of the operator
+type CertificateRequest struct {
+	SecretName   string
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	IPAddresses  []string
+	URISANs      []string
+	Duration     time.Duration
+	RenewBefore  time.Duration
+}
+
+// GetCertificateAuthorityNamespace returns the namespace of the secret of the CA issuing the certificates of the
+// application
+func GetCertificateAuthorityNamespace(cr *appsodyv1beta1.AppsodyApplication, operatorNamespace string) string {
+	if strings.TrimSpace(common.Config[OpConfigCertificateAuthority]) == CertificateAuthorityCluster {
+		return operatorNamespace
+	}
+	return cr.Namespace
+}
+
+// GetServiceCertificateRequest returns the certificate of the service of the application, with the same defaults as
+// the cert-manager Certificate created by ReconcileCertificate, or nil if the service does not request a certificate
+func GetServiceCertificateRequest(cr *appsodyv1beta1.AppsodyApplication) *CertificateRequest {
+	if cr.Spec.Service == nil || cr.Spec.Service.Certificate == nil {
+		return nil
+	}
+	req := newCertificateRequest(cr.Spec.Service.Certificate, cr.Name+"-svc-tls")
+	req.CommonName = cr.Name + "." + cr.Namespace + ".svc"
+	if len(req.DNSNames) == 0 {
+		req.DNSNames = []string{req.CommonName}
+	}
+	return req
+}
+
+// GetRouteCertificateRequest returns the certificate of the route of the application, with the same defaults as the
+// cert-manager Certificate created by ReconcileCertificate, or nil if the application is not exposed with a
+// certificate
+func GetRouteCertificateRequest(cr *appsodyv1beta1.AppsodyApplication) *CertificateRequest {
+	if cr.Spec.Expose == nil || !*cr.Spec.Expose || cr.Spec.Route == nil || cr.Spec.Route.Certificate == nil {
+		return nil
+	}
+	req := newCertificateRequest(cr.Spec.Route.Certificate, cr.Name+"-route-tls")
+	if req.CommonName == "" {
+		req.CommonName = GetRouteHost(cr)
+	}
+	if len(req.DNSNames) == 0 && req.CommonName != "" {
+		req.DNSNames = []string{req.CommonName}
+	}
+	return req
+}
+
+func newCertificateRequest(crt *appsodyv1beta1.Certificate, defaultSecretName string) *CertificateRequest {
+	req := &CertificateRequest{
+		SecretName:   crt.SecretName,
+		CommonName:   crt.CommonName,
+		Organization: crt.Organization,
+		DNSNames:     crt.DNSNames,
+		IPAddresses:  crt.IPAddresses,
+		URISANs:      crt.URISANs,
+		Duration:     defaultCertificateDuration,
+		RenewBefore:  defaultCertificateRenewBefore,
+	}
+	if req.SecretName == "" {
+		req.SecretName = defaultSecretName
+	}
+	if crt.Duration != nil {
+		req.Duration = crt.Duration.Duration
+	}
+	if crt.RenewBefore != nil {
+		req.RenewBefore = crt.RenewBefore.Duration
+	}
+	if req.RenewBefore >= req.Duration {
+		req.RenewBefore = req.Duration / 3
+	}
+	return req
+}
+
+// NewCertificateAuthority creates the self-signed CA of the operator, returning its certificate and its private key
+// in PEM format
+func NewCertificateAuthority(now time.Time) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := newCertificateTemplate(now, caDuration)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.Subject = pkix.Name{CommonName: "Appsody Operator CA", Organization: []string{"appsody.dev"}}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertificate(der, key)
+}
+
+// IssueCertificate issues the requested certificate with the CA, returning the certificate and its private key in PEM
+// format
+func IssueCertificate(req *CertificateRequest, caCertPEM, caKeyPEM []byte, now time.Time) ([]byte, []byte, error) {
+	caCert, err := parseCertificate(caCertPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	caKey, err := parsePrivateKey(caKeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template, err := newCertificateTemplate(now, req.Duration)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.Subject = pkix.Name{CommonName: req.CommonName, Organization: req.Organization}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	template.DNSNames = req.DNSNames
+	for _, address := range req.IPAddresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return nil, nil, errors.New("invalid IP address " + address)
+		}
+		template.IPAddresses = append(template.IPAddresses, ip)
+	}
+	for _, uri := range req.URISANs {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, nil, err
+		}
+		template.URIs = append(template.URIs, u)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertificate(der, key)
+}
+
+// GetCertificateAuthorityRenewalTime returns the time the CA in the secret must be renewed, or false if the secret
+// does not hold a valid CA
+func GetCertificateAuthorityRenewalTime(secret *corev1.Secret) (time.Time, bool) {
+	cert, ok := parseCertificateAuthority(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if !ok {
+		return time.Time{}, false
+	}
+	return cert.NotAfter.Add(-caRenewBefore), true
+}
+
+// RenewCertificateAuthority returns the data of the secret of the CA of the operator at the given time, and the time
+// the data must be updated again. A CA is created when the data doesn't hold a valid CA. When the CA is due for
+// renewal, a new CA is added to the bundle in `ca.crt` while the current CA keeps issuing the certificates, so that
+// the bundle can be distributed to the certificate secrets. The new CA replaces the current CA after caRolloutPeriod.
+// The previous CAs are kept in the bundle until they expire.
+func RenewCertificateAuthority(data map[string][]byte, now time.Time) (map[string][]byte, time.Time, error) {
+	renewed := map[string][]byte{}
+	for key, value := range data {
+		renewed[key] = value
+	}
+	current, ok := parseCertificateAuthority(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	if !ok {
+		cert, key, err := NewCertificateAuthority(now)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		renewed = map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key}
+		current, _ = parseCertificate(cert)
+	}
+
+	next, staged := parseCertificateAuthority(renewed[caNextCertKey], renewed[caNextKeyKey])
+	if !staged && !now.Before(current.NotAfter.Add(-caRenewBefore)) {
+		cert, key, err := NewCertificateAuthority(now)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		renewed[caNextCertKey], renewed[caNextKeyKey] = cert, key
+		next, staged = parseCertificateAuthority(cert, key)
+	}
+	if staged && !now.Before(next.NotBefore.Add(caRolloutPeriod)) {
+		renewed[corev1.TLSCertKey], renewed[corev1.TLSPrivateKeyKey] = renewed[caNextCertKey], renewed[caNextKeyKey]
+		current, staged = next, false
+	}
+
+	bundle := [][]byte{renewed[corev1.TLSCertKey]}
+	updateTime := current.NotAfter.Add(-caRenewBefore)
+	if staged {
+		bundle = append(bundle, renewed[caNextCertKey])
+		updateTime = next.NotBefore.Add(caRolloutPeriod)
+	} else {
+		delete(renewed, caNextCertKey)
+		delete(renewed, caNextKeyKey)
+	}
+	for rest := data["ca.crt"]; len(rest) > 0; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || !now.Before(cert.NotAfter) {
+			continue
+		}
+		certPEM := pem.EncodeToMemory(block)
+		if containsCertificate(bundle, certPEM) {
+			continue
+		}
+		bundle = append(bundle, certPEM)
+		if cert.NotAfter.Before(updateTime) {
+			updateTime = cert.NotAfter
+		}
+	}
+	renewed["ca.crt"] = bytes.Join(bundle, nil)
+	return renewed, updateTime, nil
+}
+
+func containsCertificate(bundle [][]byte, certPEM []byte) bool {
+	for _, c := range bundle {
+		if bytes.Equal(c, certPEM) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCertificateRenewalTime returns the time the certificate in the secret must be renewed, or false if the secret
+// does not hold a certificate issued by the CA for the request
+func GetCertificateRenewalTime(secret *corev1.Secret, req *CertificateRequest, caCertPEM []byte) (time.Time, bool) {
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return time.Time{}, false
+	}
+	caCert, err := parseCertificate(caCertPEM)
+	if err != nil || cert.CheckSignatureFrom(caCert) != nil {
+		return time.Time{}, false
+	}
+	ips := []string{}
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	uris := []string{}
+	for _, u := range cert.URIs {
+		uris = append(uris, u.String())
+	}
+	if cert.Subject.CommonName != req.CommonName || cert.NotAfter.Sub(cert.NotBefore) != req.Duration ||
+		!equalStrings(cert.Subject.Organization, req.Organization) || !equalStrings(cert.DNSNames, req.DNSNames) ||
+		!equalStrings(ips, req.IPAddresses) || !equalStrings(uris, req.URISANs) {
+		return time.Time{}, false
+	}
+	return cert.NotAfter.Add(-req.RenewBefore), true
+}
+
+// equalStrings returns true if the lists hold the same strings, in any order
+func equalStrings(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func newCertificateTemplate(now time.Time, duration time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		NotBefore:    now,
+		NotAfter:     now.Add(duration),
+	}, nil
+}
+
+func encodeCertificate(der []byte, key *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseCertificateAuthority(certPEM []byte, keyPEM []byte) (*x509.Certificate, bool) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil || !cert.IsCA {
+		return nil, false
+	}
+	if _, err = parsePrivateKey(keyPEM); err != nil {
+		return nil, false
+	}
+	return cert, true
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("failed to decode PEM private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key")
+	}
+	return signer, nil
+}
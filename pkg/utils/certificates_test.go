@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/x509"
+	"reflect"
+	"testing"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIssueCertificate(t *testing.T) {
+	expose := true
+	cr := &appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Expose:  &expose,
+			Service: &appsodyv1beta1.AppsodyApplicationService{Certificate: &appsodyv1beta1.Certificate{}},
+			Route: &appsodyv1beta1.AppsodyRoute{
+				Host:        "orders.example.com",
+				Certificate: &appsodyv1beta1.Certificate{SecretName: "orders-public", RenewBefore: &metav1.Duration{Duration: 48 * time.Hour}},
+			},
+		},
+	}
+	svcReq, routeReq := GetServiceCertificateRequest(cr), GetRouteCertificateRequest(cr)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	caCert, caKey, err := NewCertificateAuthority(now)
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority: (%v)", err)
+	}
+	cert, key, err := IssueCertificate(svcReq, caCert, caKey, now)
+	if err != nil {
+		t.Fatalf("IssueCertificate: (%v)", err)
+	}
+	parsed, _ := parseCertificate(cert)
+	ca, _ := parseCertificate(caCert)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	_, verifyErr := parsed.Verify(x509.VerifyOptions{DNSName: "orders.shop.svc", Roots: roots, CurrentTime: now.Add(time.Hour)})
+	_, keyErr := parsePrivateKey(key)
+
+	secret := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key, "ca.crt": caCert}}
+	renewal, valid := GetCertificateRenewalTime(secret, svcReq, caCert)
+	_, validForRoute := GetCertificateRenewalTime(secret, routeReq, caCert)
+	otherCACert, _, _ := NewCertificateAuthority(now)
+	_, validForOtherCA := GetCertificateRenewalTime(secret, svcReq, otherCACert)
+	caRenewal, validCA := GetCertificateAuthorityRenewalTime(&corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: caCert, corev1.TLSPrivateKeyKey: caKey}})
+	_, leafIsCA := GetCertificateAuthorityRenewalTime(secret)
+
+	expose = false
+
+	tests := []Test{
+		{"service secret", "orders-svc-tls", svcReq.SecretName},
+		{"service dns names", []string{"orders.shop.svc"}, svcReq.DNSNames},
+		{"route secret", "orders-public", routeReq.SecretName},
+		{"route dns names", []string{"orders.example.com"}, routeReq.DNSNames},
+		{"route renew before", 48 * time.Hour, routeReq.RenewBefore},
+		{"verified", nil, verifyErr},
+		{"private key", nil, keyErr},
+		{"valid", true, valid},
+		{"renewal", now.Add(defaultCertificateDuration - defaultCertificateRenewBefore), renewal},
+		{"valid for other names", false, validForRoute},
+		{"valid for other CA", false, validForOtherCA},
+		{"valid CA", true, validCA},
+		{"CA renewal", now.Add(caDuration - caRenewBefore), caRenewal},
+		{"leaf is CA", false, leafIsCA},
+		{"not exposed", (*CertificateRequest)(nil), GetRouteCertificateRequest(cr)},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestRenewCertificateAuthority(t *testing.T) {
+	created := time.Now().UTC().Truncate(time.Second)
+	data, createdUpdate, err := RenewCertificateAuthority(nil, created)
+	if err != nil {
+		t.Fatalf("RenewCertificateAuthority: (%v)", err)
+	}
+	ca := data[corev1.TLSCertKey]
+	unchanged, _, _ := RenewCertificateAuthority(data, createdUpdate.Add(-time.Hour))
+
+	// The renewed CA is added to the bundle while the current CA keeps issuing the certificates
+	renewal := created.Add(caDuration - caRenewBefore)
+	staged, stagedUpdate, err := RenewCertificateAuthority(data, renewal)
+	if err != nil {
+		t.Fatalf("RenewCertificateAuthority: (%v)", err)
+	}
+	next := staged[caNextCertKey]
+
+	// The renewed CA replaces the current CA after the rollout period, and the current CA stays trusted until it expires
+	promoted, promotedUpdate, err := RenewCertificateAuthority(staged, stagedUpdate)
+	if err != nil {
+		t.Fatalf("RenewCertificateAuthority: (%v)", err)
+	}
+	expired, _, err := RenewCertificateAuthority(promoted, promotedUpdate)
+	if err != nil {
+		t.Fatalf("RenewCertificateAuthority: (%v)", err)
+	}
+
+	tests := []Test{
+		{"created bundle", string(ca), string(data["ca.crt"])},
+		{"created update", renewal, createdUpdate},
+		{"unchanged", true, reflect.DeepEqual(data, unchanged)},
+		{"staged CA", string(ca), string(staged[corev1.TLSCertKey])},
+		{"staged bundle", string(ca) + string(next), string(staged["ca.crt"])},
+		{"staged update", renewal.Add(caRolloutPeriod), stagedUpdate},
+		{"promoted CA", string(next), string(promoted[corev1.TLSCertKey])},
+		{"promoted key", string(staged[caNextKeyKey]), string(promoted[corev1.TLSPrivateKeyKey])},
+		{"promoted next CA", 0, len(promoted[caNextCertKey])},
+		{"promoted bundle", string(next) + string(ca), string(promoted["ca.crt"])},
+		{"promoted update", created.Add(caDuration), promotedUpdate},
+		{"expired bundle", string(next), string(expired["ca.crt"])},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"sort"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ConfigHashAnnotation is the pod template annotation holding a hash of the ConfigMaps and Secrets referenced by the
+// application, so that their changes roll out the pods
+const ConfigHashAnnotation = "config.appsody.dev/hash"
+
+// ConfigRolloutLabel is the label, set to "true", of the ConfigMaps and Secrets watched by the operator, so that their
+// changes roll out the pods of the applications referencing them right away. The changes of the other ConfigMaps and
+// Secrets referenced by the applications are polled.
+const ConfigRolloutLabel = "config.appsody.dev/rollout"
+
+// GetConfigRolloutReferences returns the sorted names of the ConfigMaps and Secrets referenced by the environment and
+// volumes of the application containers whose changes roll out the pods
+func GetConfigRolloutReferences(cr *appsodyv1beta1.AppsodyApplication) ([]string, []string) {
+	if !cr.IsConfigRolloutEnabled() {
+		return []string{}, []string{}
+	}
+	configMaps, secrets := map[string]bool{}, map[string]bool{}
+
+	addEnv := func(env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+		for _, e := range env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if e.ValueFrom.ConfigMapKeyRef != nil {
+				configMaps[e.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+			if e.ValueFrom.SecretKeyRef != nil {
+				secrets[e.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+		for _, e := range envFrom {
+			if e.ConfigMapRef != nil {
+				configMaps[e.ConfigMapRef.Name] = true
+			}
+			if e.SecretRef != nil {
+				secrets[e.SecretRef.Name] = true
+			}
+		}
+	}
+	addEnv(cr.Spec.Env, cr.Spec.EnvFrom)
+	for _, c := range cr.Spec.InitContainers {
+		addEnv(c.Env, c.EnvFrom)
+	}
+	for _, c := range cr.Spec.SidecarContainers {
+		addEnv(c.Env, c.EnvFrom)
+	}
+
+	for _, v := range cr.Spec.Volumes {
+		if v.ConfigMap != nil {
+			configMaps[v.ConfigMap.Name] = true
+		}
+		if v.Secret != nil {
+			secrets[v.Secret.SecretName] = true
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.ConfigMap != nil {
+					configMaps[source.ConfigMap.Name] = true
+				}
+				if source.Secret != nil {
+					secrets[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	if cr.Spec.ConfigRollout != nil {
+		for _, name := range cr.Spec.ConfigRollout.ExcludeConfigMaps {
+			delete(configMaps, name)
+		}
+		for _, name := range cr.Spec.ConfigRollout.ExcludeSecrets {
+			delete(secrets, name)
+		}
+	}
+	return sortedKeys(configMaps), sortedKeys(secrets)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HashConfig returns a hash of the data of the ConfigMaps and Secrets, regardless of their order
+func HashConfig(configMaps []corev1.ConfigMap, secrets []corev1.Secret) string {
+	data := map[string]interface{}{}
+	for _, cm := range configMaps {
+		data["configmap/"+cm.Name] = []interface{}{cm.Data, cm.BinaryData}
+	}
+	for _, secret := range secrets {
+		data["secret/"+secret.Name] = secret.Data
+	}
+	return GetHash(data)
+}
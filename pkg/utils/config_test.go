@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetConfigRolloutReferences(t *testing.T) {
+	ref := func(name string) corev1.LocalObjectReference { return corev1.LocalObjectReference{Name: name} }
+	cr := &appsodyv1beta1.AppsodyApplication{Spec: appsodyv1beta1.AppsodyApplicationSpec{
+		Env: []corev1.EnvVar{
+			{Name: "A", Value: "a"},
+			{Name: "B", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: ref("env-config"), Key: "b"}}},
+			{Name: "C", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: ref("env-secret"), Key: "c"}}},
+		},
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: ref("app-config")}}},
+		SidecarContainers: []corev1.Container{{Name: "proxy", EnvFrom: []corev1.EnvFromSource{
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: ref("proxy-secret")}},
+		}}},
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: ref("app-config")}}},
+			{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls-secret"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: ref("projected-config")}},
+			}}}},
+		},
+		ConfigRollout: &appsodyv1beta1.AppsodyConfigRollout{ExcludeSecrets: []string{"tls-secret"}},
+	}}
+
+	configMaps, secrets := GetConfigRolloutReferences(cr)
+	enabled := false
+	cr.Spec.ConfigRollout.Enabled = &enabled
+	disabledConfigMaps, disabledSecrets := GetConfigRolloutReferences(cr)
+	cr.Spec.ConfigRollout = nil
+	defaultConfigMaps, defaultSecrets := GetConfigRolloutReferences(cr)
+
+	tests := []Test{
+		{"config maps", []string{"app-config", "env-config", "projected-config"}, configMaps},
+		{"secrets", []string{"env-secret", "proxy-secret"}, secrets},
+		{"disabled config maps", []string{}, disabledConfigMaps},
+		{"disabled secrets", []string{}, disabledSecrets},
+		{"default config maps", []string{"app-config", "env-config", "projected-config"}, defaultConfigMaps},
+		{"default secrets", []string{"env-secret", "proxy-secret", "tls-secret"}, defaultSecrets},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestHashConfig(t *testing.T) {
+	a := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Data: map[string]string{"key": "1"}}
+	b := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Data: map[string]string{"key": "2"}}
+	secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Data: map[string][]byte{"key": []byte("1")}}
+
+	hash := HashConfig([]corev1.ConfigMap{a, b}, []corev1.Secret{secret})
+	if reordered := HashConfig([]corev1.ConfigMap{b, a}, []corev1.Secret{secret}); reordered != hash {
+		t.Errorf("hash of reordered ConfigMaps expected: (%v) actual: (%v)", hash, reordered)
+	}
+	secret.Data["key"] = []byte("2")
+	if changed := HashConfig([]corev1.ConfigMap{a, b}, []corev1.Secret{secret}); changed == hash {
+		t.Errorf("hash of changed Secret expected to differ from (%v)", hash)
+	}
+}
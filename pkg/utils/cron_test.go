@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule(t *testing.T) {
+	from := time.Date(2020, time.March, 4, 10, 30, 0, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		expr     string
+		timeZone string
+		next     time.Time
+		prev     time.Time
+	}{
+		{"*/15 * * * *", "", time.Date(2020, time.March, 4, 10, 45, 0, 0, time.UTC), time.Date(2020, time.March, 4, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", "", time.Date(2020, time.March, 4, 11, 0, 0, 0, time.UTC), time.Date(2020, time.March, 4, 10, 0, 0, 0, time.UTC)},
+		{"0 20 * * 1-5", "", time.Date(2020, time.March, 4, 20, 0, 0, 0, time.UTC), time.Date(2020, time.March, 3, 20, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", "", time.Date(2020, time.March, 8, 0, 0, 0, 0, time.UTC), time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", "", time.Date(2020, time.March, 8, 0, 0, 0, 0, time.UTC), time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", "", time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * *", "UTC", time.Date(2020, time.March, 5, 8, 0, 0, 0, time.UTC), time.Date(2020, time.March, 4, 8, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 12 1 jan *", "", time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr, tt.timeZone)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): (%v)", tt.expr, err)
+		}
+		if next := s.Next(from); !next.Equal(tt.next) {
+			t.Errorf("%q next expected: (%v) actual: (%v)", tt.expr, tt.next, next)
+		}
+		if prev := s.Prev(from); !prev.Equal(tt.prev) {
+			t.Errorf("%q prev expected: (%v) actual: (%v)", tt.expr, tt.prev, prev)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * * mon-", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(expr, ""); err == nil {
+			t.Errorf("ParseCron(%q) expected an error", expr)
+		}
+	}
+	if _, err := ParseCron("0 0 * * *", "Not/AZone"); err == nil {
+		t.Errorf("ParseCron with an invalid time zone expected an error")
+	}
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// EventingGroup is the API group of the Knative Eventing Triggers
+	EventingGroup = "eventing.knative.dev"
+
+	// SourcesGroup is the API group of the Knative Eventing sources
+	SourcesGroup = "sources.knative.dev"
+
+	// defaultBroker is the broker of the triggers that don't set one
+	defaultBroker = "default"
+)
+
+// GetEventTriggerName returns the name of the Trigger generated for a trigger of the application
+func GetEventTriggerName(cr *appsodyv1beta1.AppsodyApplication, trigger appsodyv1beta1.AppsodyEventTrigger) string {
+	return cr.Name + "-" + trigger.Name
+}
+
+// GetEventSourceName returns the name of the source generated for an event source of the application
+func GetEventSourceName(cr *appsodyv1beta1.AppsodyApplication, source appsodyv1beta1.AppsodyEventSource) string {
+	return cr.Name + "-" + source.Name
+}
+
+// GetEventSourceKind returns the kind of the Knative Eventing source of an event source of the application, or an
+// empty string
+func GetEventSourceKind(source appsodyv1beta1.AppsodyEventSource) string {
+	switch {
+	case source.Ping != nil:
+		return "PingSource"
+	case source.APIServer != nil:
+		return "ApiServerSource"
+	}
+	return ""
+}
+
+// GetEventSink returns the destination of the events of the application: its Knative Service when the API version
+// of the Knative Service is set, or the URL of its service otherwise
+func GetEventSink(cr *appsodyv1beta1.AppsodyApplication, knativeAPIVersion string) map[string]interface{} {
+	path := ""
+	if cr.Spec.Events != nil {
+		path = cr.Spec.Events.Path
+	}
+	if knativeAPIVersion != "" {
+		sink := map[string]interface{}{
+			"ref": map[string]interface{}{
+				"apiVersion": knativeAPIVersion,
+				"kind":       "Service",
+				"name":       cr.Name,
+				"namespace":  cr.Namespace,
+			},
+		}
+		if path != "" {
+			// Relative URIs are resolved against the address of the reference
+			sink["uri"] = path
+		}
+		return sink
+	}
+	if path == "" {
+		path = "/"
+	}
+	return map[string]interface{}{
+		"uri": fmt.Sprintf("http://%s:%d%s", getServiceHost(cr), cr.Spec.Service.GetPort(), path),
+	}
+}
+
+// CustomizeEventTrigger sets up the Trigger delivering the events of the broker matching the filter of the trigger
+// to the sink
+func CustomizeEventTrigger(obj *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication, trigger appsodyv1beta1.AppsodyEventTrigger, sink map[string]interface{}) {
+	obj.SetLabels(cr.GetLabels())
+	obj.SetAnnotations(oputils.MergeMaps(obj.GetAnnotations(), cr.GetAnnotations()))
+
+	broker := trigger.Broker
+	if broker == "" {
+		broker = defaultBroker
+	}
+	spec := map[string]interface{}{
+		"broker":     broker,
+		"subscriber": runtime.DeepCopyJSON(sink),
+	}
+	if len(trigger.Filter) > 0 {
+		attributes := map[string]interface{}{}
+		for k, v := range trigger.Filter {
+			attributes[k] = v
+		}
+		spec["filter"] = map[string]interface{}{"attributes": attributes}
+	}
+	obj.Object["spec"] = spec
+}
+
+// CustomizeEventSource sets up the Knative Eventing source of the event source, sending its events to the sink
+func CustomizeEventSource(obj *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication, source appsodyv1beta1.AppsodyEventSource, sink map[string]interface{}) {
+	obj.SetLabels(cr.GetLabels())
+	obj.SetAnnotations(oputils.MergeMaps(obj.GetAnnotations(), cr.GetAnnotations()))
+
+	spec := map[string]interface{}{"sink": runtime.DeepCopyJSON(sink)}
+	switch {
+	case source.Ping != nil:
+		spec["schedule"] = source.Ping.Schedule
+		if source.Ping.ContentType != "" {
+			spec["contentType"] = source.Ping.ContentType
+		}
+		if source.Ping.Data != "" {
+			spec["data"] = source.Ping.Data
+		}
+	case source.APIServer != nil:
+		mode := source.APIServer.Mode
+		if mode == "" {
+			mode = "Reference"
+		}
+		spec["mode"] = mode
+		resources := []interface{}{}
+		for _, r := range source.APIServer.Resources {
+			resource := map[string]interface{}{"apiVersion": r.APIVersion, "kind": r.Kind}
+			if r.Selector != nil {
+				if selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r.Selector); err == nil {
+					resource["selector"] = selector
+				}
+			}
+			resources = append(resources, resource)
+		}
+		spec["resources"] = resources
+		if source.APIServer.ServiceAccountName != "" {
+			spec["serviceAccountName"] = source.APIServer.ServiceAccountName
+		}
+	}
+	obj.Object["spec"] = spec
+}
+
+// IsEventResourceReady returns true if the Ready condition of the Knative Eventing resource is true, with the message
+// of the condition otherwise
+func IsEventResourceReady(obj *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if condition["status"] == "True" {
+			return true, ""
+		}
+		message, _ := condition["message"].(string)
+		return false, message
+	}
+	return false, ""
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCustomizeEvents(t *testing.T) {
+	cr := &appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Service: &appsodyv1beta1.AppsodyApplicationService{Port: 3000},
+			Events:  &appsodyv1beta1.AppsodyEvents{Path: "/events"},
+		},
+	}
+	trigger := appsodyv1beta1.AppsodyEventTrigger{Name: "created", Filter: map[string]string{"type": "order.created"}}
+	ping := appsodyv1beta1.AppsodyEventSource{Name: "tick", Ping: &appsodyv1beta1.AppsodyPingSource{Schedule: "*/5 * * * *", Data: "{}"}}
+	apiServer := appsodyv1beta1.AppsodyEventSource{Name: "pods", APIServer: &appsodyv1beta1.AppsodyAPIServerEventSource{
+		Resources: []appsodyv1beta1.AppsodyAPIServerResource{{APIVersion: "v1", Kind: "Pod", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "orders"}}}},
+	}}
+
+	serviceSink := GetEventSink(cr, "")
+	knativeSink := GetEventSink(cr, "serving.knative.dev/v1")
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	CustomizeEventTrigger(obj, cr, trigger, serviceSink)
+	broker, _, _ := unstructured.NestedString(obj.Object, "spec", "broker")
+	filter, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "filter", "attributes")
+	subscriber, _, _ := unstructured.NestedString(obj.Object, "spec", "subscriber", "uri")
+
+	CustomizeEventSource(obj, cr, ping, knativeSink)
+	schedule, _, _ := unstructured.NestedString(obj.Object, "spec", "schedule")
+	sinkRef, _, _ := unstructured.NestedString(obj.Object, "spec", "sink", "ref", "name")
+
+	CustomizeEventSource(obj, cr, apiServer, knativeSink)
+	mode, _, _ := unstructured.NestedString(obj.Object, "spec", "mode")
+	resources, _, _ := unstructured.NestedSlice(obj.Object, "spec", "resources")
+	selector, _, _ := unstructured.NestedStringMap(resources[0].(map[string]interface{}), "selector", "matchLabels")
+
+	notReady := &unstructured.Unstructured{Object: map[string]interface{}{"status": map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "message": "broker not found"}},
+	}}}
+	ready, message := IsEventResourceReady(notReady)
+
+	tests := []Test{
+		{"trigger name", "orders-created", GetEventTriggerName(cr, trigger)},
+		{"source kinds", "PingSource ApiServerSource", GetEventSourceKind(ping) + " " + GetEventSourceKind(apiServer)},
+		{"default broker", "default", broker},
+		{"filter", map[string]string{"type": "order.created"}, filter},
+		{"service subscriber", "http://orders.shop.svc.cluster.local:3000/events", subscriber},
+		{"knative sink uri", "/events", knativeSink["uri"]},
+		{"schedule", "*/5 * * * *", schedule},
+		{"sink ref", "orders", sinkRef},
+		{"default mode", "Reference", mode},
+		{"resource selector", map[string]string{"app": "orders"}, selector},
+		{"not ready", false, ready},
+		{"not ready message", "broker not found", message},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
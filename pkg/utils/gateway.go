@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// GatewayGroup is the API group of the Gateway API
+	GatewayGroup = "gateway.networking.k8s.io"
+
+	// OpConfigDefaultGateway is the operator configuration of the Gateway the HTTPRoutes of the applications are
+	// attached to by default, as "[namespace/]name". HTTPRoutes are only used when a Gateway is configured.
+	OpConfigDefaultGateway = "defaultGateway"
+
+	// GatewayListenerNotTLS reports that the Gateway has no HTTPS listener to terminate the TLS of the application
+	GatewayListenerNotTLS = "ListenerNotTLS"
+	// GatewayCertificateNotReferenced reports that no HTTPS listener of the Gateway serves the TLS secret of the
+	// application
+	GatewayCertificateNotReferenced = "CertificateNotReferenced"
+)
+
+// GetGatewayReference returns the Gateway the HTTPRoute of the application is attached to, from the route of the
+// application or else from the operator configuration, or nil if none is configured
+func GetGatewayReference(cr *appsodyv1beta1.AppsodyApplication) (*appsodyv1beta1.AppsodyGatewayReference, error) {
+	if cr.Spec.Route != nil && cr.Spec.Route.Gateway != nil {
+		gateway := cr.Spec.Route.Gateway.DeepCopy()
+		if gateway.Namespace == "" {
+			gateway.Namespace = cr.Namespace
+		}
+		return gateway, nil
+	}
+
+	config := strings.TrimSpace(common.Config[OpConfigDefaultGateway])
+	if config == "" {
+		return nil, nil
+	}
+	parts := strings.Split(config, "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return &appsodyv1beta1.AppsodyGatewayReference{Name: parts[0], Namespace: cr.Namespace}, nil
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return &appsodyv1beta1.AppsodyGatewayReference{Name: parts[1], Namespace: parts[0]}, nil
+	}
+	return nil, fmt.Errorf("invalid gateway %q in operator configuration %s, expected [namespace/]name", config, OpConfigDefaultGateway)
+}
+
+// CustomizeHTTPRoute sets up the HTTPRoute routing the requests received by the Gateway to the application's Service
+func CustomizeHTTPRoute(route *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication, gateway *appsodyv1beta1.AppsodyGatewayReference) {
+	route.SetLabels(cr.GetLabels())
+	host, path := GetRouteHost(cr), "/"
+	headers := []appsodyv1beta1.AppsodyHTTPHeaderMatch{}
+	if rt := cr.Spec.Route; rt != nil {
+		route.SetAnnotations(oputils.MergeMaps(route.GetAnnotations(), cr.GetAnnotations(), rt.Annotations))
+		if rt.Path != "" {
+			path = rt.Path
+		}
+		headers = rt.Headers
+	} else {
+		route.SetAnnotations(oputils.MergeMaps(route.GetAnnotations(), cr.GetAnnotations()))
+	}
+
+	parentRef := map[string]interface{}{
+		"group":     GatewayGroup,
+		"kind":      "Gateway",
+		"name":      gateway.Name,
+		"namespace": gateway.Namespace,
+	}
+	if gateway.SectionName != "" {
+		parentRef["sectionName"] = gateway.SectionName
+	}
+
+	match := map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":  "PathPrefix",
+			"value": path,
+		},
+	}
+	if len(headers) > 0 {
+		headerMatches := []interface{}{}
+		for _, h := range headers {
+			matchType := h.Type
+			if matchType == "" {
+				matchType = appsodyv1beta1.HTTPHeaderMatchExact
+			}
+			headerMatches = append(headerMatches, map[string]interface{}{
+				"type":  string(matchType),
+				"name":  h.Name,
+				"value": h.Value,
+			})
+		}
+		match["headers"] = headerMatches
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{match},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": cr.Name,
+						"port": int64(cr.Spec.Service.GetPort()),
+					},
+				},
+			},
+		},
+	}
+	if host != "" {
+		spec["hostnames"] = []interface{}{host}
+	}
+	route.Object["spec"] = spec
+}
+
+// GetGatewayTLSListener returns the name of the HTTPS listener of the Gateway whose certificates include the TLS secret
+// of the application, among the listeners named sectionName when it is set. Otherwise, it returns an empty name and
+// the reason the Gateway can't terminate the TLS of the application.
+func GetGatewayTLSListener(gateway *unstructured.Unstructured, sectionName string, secretNamespace string, secretName string) (string, string) {
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	reason := GatewayListenerNotTLS
+	for _, l := range listeners {
+		listener, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(listener, "name")
+		protocol, _, _ := unstructured.NestedString(listener, "protocol")
+		if (sectionName != "" && name != sectionName) || protocol != "HTTPS" {
+			continue
+		}
+		reason = GatewayCertificateNotReferenced
+		refs, _, _ := unstructured.NestedSlice(listener, "tls", "certificateRefs")
+		for _, r := range refs {
+			ref, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, _, _ := unstructured.NestedString(ref, "group")
+			kind, _, _ := unstructured.NestedString(ref, "kind")
+			refName, _, _ := unstructured.NestedString(ref, "name")
+			refNamespace, _, _ := unstructured.NestedString(ref, "namespace")
+			if refNamespace == "" {
+				refNamespace = gateway.GetNamespace()
+			}
+			if group == "" && (kind == "" || kind == "Secret") && refName == secretName && refNamespace == secretNamespace {
+				return name, ""
+			}
+		}
+	}
+	return "", reason
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetGatewayReference(t *testing.T) {
+	defer func(config common.OpConfig) { common.Config = config }(common.Config)
+	common.Config = common.OpConfig{}
+	cr := &appsodyv1beta1.AppsodyApplication{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team"}}
+
+	none, _ := GetGatewayReference(cr)
+	common.Config[OpConfigDefaultGateway] = "gateway"
+	sameNamespace, _ := GetGatewayReference(cr)
+	common.Config[OpConfigDefaultGateway] = "infra/shared"
+	otherNamespace, _ := GetGatewayReference(cr)
+	cr.Spec.Route = &appsodyv1beta1.AppsodyRoute{Gateway: &appsodyv1beta1.AppsodyGatewayReference{Name: "own", SectionName: "https"}}
+	app, _ := GetGatewayReference(cr)
+
+	tests := []Test{
+		{"no gateway", (*appsodyv1beta1.AppsodyGatewayReference)(nil), none},
+		{"gateway in the app namespace", &appsodyv1beta1.AppsodyGatewayReference{Name: "gateway", Namespace: "team"}, sameNamespace},
+		{"gateway in another namespace", &appsodyv1beta1.AppsodyGatewayReference{Name: "shared", Namespace: "infra"}, otherNamespace},
+		{"gateway of the app", &appsodyv1beta1.AppsodyGatewayReference{Name: "own", Namespace: "team", SectionName: "https"}, app},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+
+	cr.Spec.Route = nil
+	for _, config := range []string{"/gateway", "infra/", "a/b/c"} {
+		common.Config[OpConfigDefaultGateway] = config
+		if _, err := GetGatewayReference(cr); err == nil {
+			t.Errorf("GetGatewayReference with %q expected an error", config)
+		}
+	}
+}
+
+func TestCustomizeHTTPRoute(t *testing.T) {
+	defer func(config common.OpConfig) { common.Config = config }(common.Config)
+	common.Config = common.OpConfig{common.OpConfigDefaultHostname: "apps.example.com"}
+	cr := &appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team"},
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Service: &appsodyv1beta1.AppsodyApplicationService{Port: 3000},
+			Route: &appsodyv1beta1.AppsodyRoute{
+				Path:        "/api",
+				Annotations: map[string]string{"team": "web"},
+				Headers: []appsodyv1beta1.AppsodyHTTPHeaderMatch{
+					{Name: "X-Version", Value: "v2"},
+					{Name: "User-Agent", Value: ".*Mobile.*", Type: appsodyv1beta1.HTTPHeaderMatchRegularExpression},
+				},
+			},
+		},
+	}
+	gateway := &appsodyv1beta1.AppsodyGatewayReference{Name: "shared", Namespace: "infra", SectionName: "https"}
+	route := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	CustomizeHTTPRoute(route, cr, gateway)
+
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := rules[0].(map[string]interface{})
+	match := rule["matches"].([]interface{})[0].(map[string]interface{})
+
+	tests := []Test{
+		{"parent", map[string]interface{}{"group": GatewayGroup, "kind": "Gateway", "name": "shared", "namespace": "infra", "sectionName": "https"}, parentRefs[0]},
+		{"hostnames", []string{"app-team.apps.example.com"}, hostnames},
+		{"path", map[string]interface{}{"type": "PathPrefix", "value": "/api"}, match["path"]},
+		{"headers", []interface{}{
+			map[string]interface{}{"type": "Exact", "name": "X-Version", "value": "v2"},
+			map[string]interface{}{"type": "RegularExpression", "name": "User-Agent", "value": ".*Mobile.*"},
+		}, match["headers"]},
+		{"backend", []interface{}{map[string]interface{}{"name": "app", "port": int64(3000)}}, rule["backendRefs"]},
+		{"annotations", "web", route.GetAnnotations()["team"]},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestGetGatewayTLSListener(t *testing.T) {
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)},
+				map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": int64(443), "tls": map[string]interface{}{
+					"certificateRefs": []interface{}{
+						map[string]interface{}{"name": "wildcard-tls"},
+						map[string]interface{}{"kind": "Secret", "name": "app-tls", "namespace": "team"},
+					},
+				}},
+			},
+		},
+	}}
+	gateway.SetNamespace("infra")
+
+	resolved, resolvedReason := GetGatewayTLSListener(gateway, "", "team", "app-tls")
+	gatewayNamespace, gatewayNamespaceReason := GetGatewayTLSListener(gateway, "https", "infra", "wildcard-tls")
+	notTLS, notTLSReason := GetGatewayTLSListener(gateway, "http", "team", "app-tls")
+	notReferenced, notReferencedReason := GetGatewayTLSListener(gateway, "", "team", "other-tls")
+
+	tests := []Test{
+		{"resolved listener", "https", resolved},
+		{"resolved reason", "", resolvedReason},
+		{"certificate in the gateway namespace", "https", gatewayNamespace},
+		{"certificate in the gateway namespace reason", "", gatewayNamespaceReason},
+		{"listener without TLS", "", notTLS},
+		{"listener without TLS reason", GatewayListenerNotTLS, notTLSReason},
+		{"certificate not referenced", "", notReferenced},
+		{"certificate not referenced reason", GatewayCertificateNotReferenced, notReferencedReason},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	"github.com/blang/semver"
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+)
+
+// imageTagMatcher selects the tags allowed by an image update policy
+type imageTagMatcher struct {
+	pattern     *regexp.Regexp
+	semverRange semver.Range
+}
+
+func newImageTagMatcher(policy *appsodyv1beta1.AppsodyImageUpdatePolicy) (*imageTagMatcher, error) {
+	m := &imageTagMatcher{}
+	var err error
+	if policy.TagPattern != "" {
+		if m.pattern, err = regexp.Compile(policy.TagPattern); err != nil {
+			return nil, fmt.Errorf("invalid spec.imageUpdatePolicy.tagPattern: %v", err)
+		}
+	}
+	if policy.SemverRange != "" {
+		if m.semverRange, err = semver.ParseRange(policy.SemverRange); err != nil {
+			return nil, fmt.Errorf("invalid spec.imageUpdatePolicy.semverRange: %v", err)
+		}
+	}
+	return m, nil
+}
+
+// matches returns true if the tag matches the pattern and, when a range is set, is a release version in the range
+func (m *imageTagMatcher) matches(tag string) bool {
+	if m.pattern != nil && !m.pattern.MatchString(tag) {
+		return false
+	}
+	if m.semverRange != nil {
+		v, err := semver.ParseTolerant(tag)
+		if err != nil || len(v.Pre) > 0 || !m.semverRange(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNewerTag compares tags as semantic versions when both are, and lexically otherwise
+func isNewerTag(tag, than string) bool {
+	v, err := semver.ParseTolerant(tag)
+	if err == nil {
+		if vThan, err := semver.ParseTolerant(than); err == nil && !v.EQ(vThan) {
+			return v.GT(vThan)
+		}
+	}
+	return tag > than
+}
+
+// ValidateImageUpdatePolicy checks the tag pattern and semantic version range of an image update policy
+func ValidateImageUpdatePolicy(policy *appsodyv1beta1.AppsodyImageUpdatePolicy) error {
+	_, err := newImageTagMatcher(policy)
+	return err
+}
+
+// SelectImageUpdate returns the image with the newest of the tags allowed by the update policy, or an empty string if
+// none of the tags is allowed
+func SelectImageUpdate(image string, tags []string, policy *appsodyv1beta1.AppsodyImageUpdatePolicy) (string, error) {
+	m, err := newImageTagMatcher(policy)
+	if err != nil {
+		return "", err
+	}
+	newest := ""
+	for _, tag := range tags {
+		if m.matches(tag) && (newest == "" || isNewerTag(tag, newest)) {
+			newest = tag
+		}
+	}
+	if newest == "" {
+		return "", nil
+	}
+	return withImageTag(image, newest)
+}
+
+// IsImageUpdateAllowed returns true if the image is from the repository of the application image and its tag is
+// allowed by the update policy
+func IsImageUpdateAllowed(image string, applicationImage string, policy *appsodyv1beta1.AppsodyImageUpdatePolicy) bool {
+	ref, err := imagereference.Parse(image)
+	if err != nil || ref.Tag == "" {
+		return false
+	}
+	if repository, err := withImageTag(applicationImage, ref.Tag); err != nil || repository != image {
+		return false
+	}
+	m, err := newImageTagMatcher(policy)
+	return err == nil && m.matches(ref.Tag)
+}
+
+func withImageTag(image string, tag string) (string, error) {
+	ref, err := imagereference.Parse(image)
+	if err != nil {
+		return "", err
+	}
+	ref.Tag = tag
+	ref.ID = ""
+	return ref.Exact(), nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+)
+
+func TestSelectImageUpdate(t *testing.T) {
+	tags := []string{"latest", "v1.9.0", "1.10.0", "1.11.0-beta", "2.0.0", "nightly-20200301", "nightly-20200304"}
+
+	tests := []struct {
+		policy   appsodyv1beta1.AppsodyImageUpdatePolicy
+		expected string
+	}{
+		{appsodyv1beta1.AppsodyImageUpdatePolicy{SemverRange: ">=1.0.0 <2.0.0"}, "quay.io/ns/app:1.10.0"},
+		{appsodyv1beta1.AppsodyImageUpdatePolicy{SemverRange: ">=1.0.0"}, "quay.io/ns/app:2.0.0"},
+		{appsodyv1beta1.AppsodyImageUpdatePolicy{SemverRange: "<1.10.0"}, "quay.io/ns/app:v1.9.0"},
+		{appsodyv1beta1.AppsodyImageUpdatePolicy{TagPattern: "^nightly-"}, "quay.io/ns/app:nightly-20200304"},
+		{appsodyv1beta1.AppsodyImageUpdatePolicy{TagPattern: "^v", SemverRange: ">=1.0.0"}, "quay.io/ns/app:v1.9.0"},
+		{appsodyv1beta1.AppsodyImageUpdatePolicy{SemverRange: ">=3.0.0"}, ""},
+	}
+
+	for _, tt := range tests {
+		image, err := SelectImageUpdate("quay.io/ns/app:1.0.0", tags, &tt.policy)
+		if err != nil {
+			t.Fatalf("SelectImageUpdate(%v): (%v)", tt.policy, err)
+		}
+		if image != tt.expected {
+			t.Errorf("%v expected: (%v) actual: (%v)", tt.policy, tt.expected, image)
+		}
+	}
+
+	policy := &appsodyv1beta1.AppsodyImageUpdatePolicy{SemverRange: "<2.0.0"}
+	if !IsImageUpdateAllowed("quay.io/ns/app:1.10.0", "quay.io/ns/app:1.0.0", policy) {
+		t.Errorf("image update expected to be allowed")
+	}
+	if IsImageUpdateAllowed("quay.io/ns/other:1.10.0", "quay.io/ns/app:1.0.0", policy) {
+		t.Errorf("image update from another repository expected not to be allowed")
+	}
+
+	for _, policy := range []appsodyv1beta1.AppsodyImageUpdatePolicy{{TagPattern: "("}, {SemverRange: "~>1"}} {
+		if err := ValidateImageUpdatePolicy(&policy); err == nil {
+			t.Errorf("ValidateImageUpdatePolicy(%v) expected an error", policy)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	certmngrv1alpha2 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// IngressGroup is the API group of Ingresses
+	IngressGroup = "networking.k8s.io"
+
+	// OpConfigDefaultIngressClass is the operator configuration of the IngressClass of the Ingresses of the
+	// applications that don't set one
+	OpConfigDefaultIngressClass = "defaultIngressClass"
+
+	// IngressClassAnnotation selects the ingress controller of networking.k8s.io/v1beta1 Ingresses
+	IngressClassAnnotation = "kubernetes.io/ingress.class"
+
+	// BackendProtocolAnnotation sets the protocol of the application port for the NGINX ingress controller
+	BackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+)
+
+// GetRouteHost returns the host the application is exposed on, from its route or else generated from the
+// defaultHostname operator configuration, or an empty string
+func GetRouteHost(cr *appsodyv1beta1.AppsodyApplication) string {
+	if cr.Spec.Route != nil && cr.Spec.Route.Host != "" {
+		return cr.Spec.Route.Host
+	}
+	if common.Config[common.OpConfigDefaultHostname] != "" {
+		return cr.Name + "-" + cr.Namespace + "." + common.Config[common.OpConfigDefaultHostname]
+	}
+	return ""
+}
+
+// GetIngressClassName returns the IngressClass of the Ingress of the application, or an empty string to use the
+// default class of the cluster
+func GetIngressClassName(cr *appsodyv1beta1.AppsodyApplication) string {
+	if cr.Spec.Route != nil && cr.Spec.Route.IngressClassName != "" {
+		return cr.Spec.Route.IngressClassName
+	}
+	return strings.TrimSpace(common.Config[OpConfigDefaultIngressClass])
+}
+
+// GetRouteTLSSecretName returns the secret holding the TLS certificate set in the route of the application, either
+// directly or issued from the route certificate, or an empty string
+func GetRouteTLSSecretName(cr *appsodyv1beta1.AppsodyApplication) string {
+	rt := cr.Spec.Route
+	if rt == nil {
+		return ""
+	}
+	if rt.CertificateSecretRef != nil && *rt.CertificateSecretRef != "" {
+		return *rt.CertificateSecretRef
+	}
+	if rt.Certificate != nil {
+		// Same secret as the route certificate created by ReconcileCertificate
+		if rt.Certificate.SecretName != "" {
+			return rt.Certificate.SecretName
+		}
+		return cr.Name + "-route-tls"
+	}
+	return ""
+}
+
+// IsIngressCertificateNeeded returns true if the route of the application requests TLS with a termination but has
+// neither a certificate nor a certificate secret, so that a certificate must be issued for the Ingress host
+func IsIngressCertificateNeeded(cr *appsodyv1beta1.AppsodyApplication) bool {
+	return cr.Spec.Route != nil && cr.Spec.Route.Termination != nil && GetRouteTLSSecretName(cr) == ""
+}
+
+// CustomizeIngressCertificate sets up the cert-manager Certificate of the Ingress host, issued by the default issuer
+// of the operator configuration
+func CustomizeIngressCertificate(crt *certmngrv1alpha2.Certificate, cr *appsodyv1beta1.AppsodyApplication, host string, secretName string) {
+	crt.Labels = cr.GetLabels()
+	crt.Annotations = oputils.MergeMaps(crt.Annotations, cr.GetAnnotations())
+	crt.Spec.CommonName = host
+	crt.Spec.DNSNames = []string{host}
+	crt.Spec.SecretName = secretName
+	crt.Spec.IssuerRef.Name = common.Config[common.OpConfigPropDefaultIssuer]
+	crt.Spec.IssuerRef.Kind = ""
+	if common.Config[common.OpConfigPropUseClusterIssuer] != "false" {
+		crt.Spec.IssuerRef.Kind = "ClusterIssuer"
+	}
+	if crt.Spec.Duration == nil {
+		crt.Spec.Duration = &metav1.Duration{Duration: time.Hour * 24 * 365}
+	}
+	if crt.Spec.RenewBefore == nil {
+		crt.Spec.RenewBefore = &metav1.Duration{Duration: time.Hour * 24 * 31}
+	}
+}
+
+// CustomizeIngress sets up the Ingress of the application for the API version of the Ingress, networking.k8s.io/v1
+// or networking.k8s.io/v1beta1. TLS is enabled when the host and the TLS secret are not empty.
+func CustomizeIngress(ing *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication, host string, tlsSecretName string) {
+	v1 := ing.GetAPIVersion() == IngressGroup+"/v1"
+	ing.SetLabels(cr.GetLabels())
+	path := ""
+	annotations := oputils.MergeMaps(ing.GetAnnotations(), cr.GetAnnotations())
+	if rt := cr.Spec.Route; rt != nil {
+		path = rt.Path
+		annotations = oputils.MergeMaps(annotations, rt.Annotations)
+	}
+
+	className := GetIngressClassName(cr)
+	spec := map[string]interface{}{}
+	if v1 {
+		delete(annotations, IngressClassAnnotation)
+		if className != "" {
+			spec["ingressClassName"] = className
+		}
+	} else if className != "" {
+		annotations[IngressClassAnnotation] = className
+	}
+	// gRPC is proxied over HTTP/2, unless the backend protocol is already set
+	if _, ok := annotations[BackendProtocolAnnotation]; !ok && cr.Spec.Service.Protocol == appsodyv1beta1.ServiceProtocolGRPC {
+		if cr.Spec.Service.IsTLS() {
+			annotations[BackendProtocolAnnotation] = "GRPCS"
+		} else {
+			annotations[BackendProtocolAnnotation] = "GRPC"
+		}
+	}
+	ing.SetAnnotations(annotations)
+
+	portName := cr.Spec.Service.PortName
+	var backend map[string]interface{}
+	if v1 {
+		port := map[string]interface{}{"number": int64(cr.Spec.Service.GetPort())}
+		if portName != "" {
+			port = map[string]interface{}{"name": portName}
+		}
+		backend = map[string]interface{}{
+			"service": map[string]interface{}{"name": cr.Name, "port": port},
+		}
+	} else {
+		if portName == "" {
+			portName = strconv.Itoa(int(cr.Spec.Service.GetPort())) + "-tcp"
+		}
+		backend = map[string]interface{}{"serviceName": cr.Name, "servicePort": portName}
+	}
+
+	httpPath := map[string]interface{}{"backend": backend}
+	if v1 {
+		// The path type is required by networking.k8s.io/v1, which also requires paths to be absolute
+		if path == "" {
+			path = "/"
+		}
+		httpPath["pathType"] = "Prefix"
+	}
+	if path != "" {
+		httpPath["path"] = path
+	}
+	rule := map[string]interface{}{
+		"http": map[string]interface{}{"paths": []interface{}{httpPath}},
+	}
+	if host != "" {
+		rule["host"] = host
+	}
+	spec["rules"] = []interface{}{rule}
+
+	if tlsSecretName != "" && host != "" {
+		spec["tls"] = []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{host},
+				"secretName": tlsSecretName,
+			},
+		}
+	}
+	ing.Object["spec"] = spec
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetRouteTLSSecretName(t *testing.T) {
+	secretRef := "my-tls"
+	edge := routev1.TLSTerminationEdge
+	cr := func(route *appsodyv1beta1.AppsodyRoute) *appsodyv1beta1.AppsodyApplication {
+		return &appsodyv1beta1.AppsodyApplication{ObjectMeta: metav1.ObjectMeta{Name: "app"}, Spec: appsodyv1beta1.AppsodyApplicationSpec{Route: route}}
+	}
+	withSecret := &appsodyv1beta1.AppsodyRoute{Certificate: &appsodyv1beta1.Certificate{SecretName: "crt-tls"}}
+	certificate := &appsodyv1beta1.AppsodyRoute{Certificate: &appsodyv1beta1.Certificate{}}
+	termination := &appsodyv1beta1.AppsodyRoute{Termination: &edge}
+
+	tests := []Test{
+		{"no route", "", GetRouteTLSSecretName(cr(nil))},
+		{"secret ref", "my-tls", GetRouteTLSSecretName(cr(&appsodyv1beta1.AppsodyRoute{CertificateSecretRef: &secretRef, Certificate: &appsodyv1beta1.Certificate{}}))},
+		{"certificate secret", "crt-tls", GetRouteTLSSecretName(cr(withSecret))},
+		{"certificate", "app-route-tls", GetRouteTLSSecretName(cr(certificate))},
+		{"certificate needed", true, IsIngressCertificateNeeded(cr(termination))},
+		{"certificate not needed", false, IsIngressCertificateNeeded(cr(&appsodyv1beta1.AppsodyRoute{Termination: &edge, CertificateSecretRef: &secretRef}))},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestCustomizeIngress(t *testing.T) {
+	defer func(config common.OpConfig) { common.Config = config }(common.Config)
+	common.Config = common.OpConfig{OpConfigDefaultIngressClass: "nginx"}
+	cr := &appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team"},
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Service: &appsodyv1beta1.AppsodyApplicationService{Port: 3000},
+			Route:   &appsodyv1beta1.AppsodyRoute{Host: "app.example.com"},
+		},
+	}
+
+	v1 := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	v1.SetAPIVersion(IngressGroup + "/v1")
+	CustomizeIngress(v1, cr, "app.example.com", "app-tls")
+	v1Class, _, _ := unstructured.NestedString(v1.Object, "spec", "ingressClassName")
+	v1Rules, _, _ := unstructured.NestedSlice(v1.Object, "spec", "rules")
+	v1TLS, _, _ := unstructured.NestedSlice(v1.Object, "spec", "tls")
+
+	cr.Spec.Route.IngressClassName = "traefik"
+	cr.Spec.Service.Protocol = appsodyv1beta1.ServiceProtocolGRPC
+	v1beta1 := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	v1beta1.SetAPIVersion(IngressGroup + "/v1beta1")
+	CustomizeIngress(v1beta1, cr, "", "app-tls")
+	v1beta1Rules, _, _ := unstructured.NestedSlice(v1beta1.Object, "spec", "rules")
+	_, v1beta1HasTLS, _ := unstructured.NestedSlice(v1beta1.Object, "spec", "tls")
+
+	tests := []Test{
+		{"v1 class", "nginx", v1Class},
+		{"v1 rule", map[string]interface{}{
+			"host": "app.example.com",
+			"http": map[string]interface{}{"paths": []interface{}{map[string]interface{}{
+				"path":     "/",
+				"pathType": "Prefix",
+				"backend":  map[string]interface{}{"service": map[string]interface{}{"name": "app", "port": map[string]interface{}{"number": int64(3000)}}},
+			}}},
+		}, v1Rules[0]},
+		{"v1 tls", []interface{}{map[string]interface{}{"hosts": []interface{}{"app.example.com"}, "secretName": "app-tls"}}, v1TLS},
+		{"v1beta1 class", "traefik", v1beta1.GetAnnotations()[IngressClassAnnotation]},
+		{"v1beta1 rule", map[string]interface{}{
+			"http": map[string]interface{}{"paths": []interface{}{map[string]interface{}{
+				"backend": map[string]interface{}{"serviceName": "app", "servicePort": "3000-tcp"},
+			}}},
+		}, v1beta1Rules[0]},
+		{"v1beta1 tls without host", false, v1beta1HasTLS},
+		{"v1 backend protocol", "", v1.GetAnnotations()[BackendProtocolAnnotation]},
+		{"grpc backend protocol", "GRPC", v1beta1.GetAnnotations()[BackendProtocolAnnotation]},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"sort"
+
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// KedaAPIVersion is the API version of KEDA ScaledObjects and TriggerAuthentications
+const KedaAPIVersion = "keda.sh/v1alpha1"
+
+// CustomizeScaledObject sets up the KEDA ScaledObject of an event-driven application
+func CustomizeScaledObject(so *unstructured.Unstructured, autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling, cr *appsodyv1beta1.AppsodyApplication) error {
+	so.SetLabels(cr.GetLabels())
+	so.SetAnnotations(oputils.MergeMaps(so.GetAnnotations(), cr.GetAnnotations()))
+
+	eventDriven := autoscaling.EventDriven
+	spec := map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       GetScaleTargetKind(cr),
+			"name":       cr.Name,
+		},
+		"maxReplicaCount": int64(autoscaling.MaxReplicas),
+	}
+	if autoscaling.MinReplicas != nil {
+		spec["minReplicaCount"] = int64(*autoscaling.MinReplicas)
+	}
+	if eventDriven.PollingInterval != nil {
+		spec["pollingInterval"] = int64(*eventDriven.PollingInterval)
+	}
+	if eventDriven.CooldownPeriod != nil {
+		spec["cooldownPeriod"] = int64(*eventDriven.CooldownPeriod)
+	}
+
+	triggers := []interface{}{}
+	for _, t := range eventDriven.Triggers {
+		metadata := map[string]interface{}{}
+		for k, v := range t.Metadata {
+			metadata[k] = v
+		}
+		trigger := map[string]interface{}{"type": t.Type, "metadata": metadata}
+		if eventDriven.Authentication != nil {
+			trigger["authenticationRef"] = map[string]interface{}{"name": cr.Name}
+		}
+		triggers = append(triggers, trigger)
+	}
+	spec["triggers"] = triggers
+
+	if autoscaling.Behavior != nil {
+		behavior, err := runtime.DefaultUnstructuredConverter.ToUnstructured(autoscaling.Behavior)
+		if err != nil {
+			return err
+		}
+		spec["advanced"] = map[string]interface{}{
+			"horizontalPodAutoscalerConfig": map[string]interface{}{"behavior": behavior},
+		}
+	}
+	so.Object["spec"] = spec
+	return nil
+}
+
+// CustomizeTriggerAuthentication sets up the KEDA TriggerAuthentication that passes secret keys to the triggers
+func CustomizeTriggerAuthentication(ta *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication) {
+	ta.SetLabels(cr.GetLabels())
+	ta.SetAnnotations(oputils.MergeMaps(ta.GetAnnotations(), cr.GetAnnotations()))
+
+	auth := cr.Spec.Autoscaling.EventDriven.Authentication
+	parameters := []string{}
+	for p := range auth.Parameters {
+		parameters = append(parameters, p)
+	}
+	sort.Strings(parameters)
+
+	refs := []interface{}{}
+	for _, p := range parameters {
+		refs = append(refs, map[string]interface{}{
+			"parameter": p,
+			"name":      auth.SecretName,
+			"key":       auth.Parameters[p],
+		})
+	}
+	ta.Object["spec"] = map[string]interface{}{"secretTargetRef": refs}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"strconv"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "github.com/knative/serving/pkg/apis/serving/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// KnativeServingGroup is the API group of the Knative Services
+	KnativeServingGroup = "serving.knative.dev"
+
+	// Annotations of the revision template configuring the Knative autoscaler. The camel case keys are accepted by
+	// all Knative versions serving v1alpha1 or v1.
+	knativeMinScaleAnnotation                      = "autoscaling.knative.dev/minScale"
+	knativeMaxScaleAnnotation                      = "autoscaling.knative.dev/maxScale"
+	knativeTargetUtilizationPercentageAnnotation   = "autoscaling.knative.dev/targetUtilizationPercentage"
+	knativeScaleToZeroPodRetentionPeriodAnnotation = "autoscaling.knative.dev/scaleToZeroPodRetentionPeriod"
+)
+
+// CustomizeKnativeAutoscaling sets the concurrency, the request timeout and the autoscaling annotations of the
+// revision template of the Knative Service from the knative configuration of the application
+func CustomizeKnativeAutoscaling(ksvc *servingv1alpha1.Service, knative *appsodyv1beta1.AppsodyKnative) {
+	if knative == nil {
+		knative = &appsodyv1beta1.AppsodyKnative{}
+	}
+
+	spec := &ksvc.Spec.Template.Spec
+	spec.ContainerConcurrency = 0
+	if knative.ContainerConcurrency != nil {
+		spec.ContainerConcurrency = servingv1beta1.RevisionContainerConcurrencyType(*knative.ContainerConcurrency)
+	}
+	spec.TimeoutSeconds = knative.TimeoutSeconds
+
+	meta := &ksvc.Spec.Template.ObjectMeta
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	setKnativeAnnotation(meta.Annotations, knativeMinScaleAnnotation, knative.MinScale)
+	setKnativeAnnotation(meta.Annotations, knativeMaxScaleAnnotation, knative.MaxScale)
+	setKnativeAnnotation(meta.Annotations, knativeTargetUtilizationPercentageAnnotation, knative.TargetUtilizationPercentage)
+	if knative.ScaleToZeroPodRetentionPeriod != "" {
+		meta.Annotations[knativeScaleToZeroPodRetentionPeriodAnnotation] = knative.ScaleToZeroPodRetentionPeriod
+	} else {
+		delete(meta.Annotations, knativeScaleToZeroPodRetentionPeriodAnnotation)
+	}
+}
+
+// setKnativeAnnotation sets the autoscaling annotation to the value, or removes it when the value is not set
+func setKnativeAnnotation(annotations map[string]string, key string, value *int32) {
+	if value == nil {
+		delete(annotations, key)
+		return
+	}
+	annotations[key] = strconv.Itoa(int(*value))
+}
+
+// CustomizeKnativeTraffic splits the traffic of the Knative Service between the revisions of the knative
+// configuration of the application, or routes all the traffic to the latest revision when it is not set
+func CustomizeKnativeTraffic(ksvc *servingv1alpha1.Service, cr *appsodyv1beta1.AppsodyApplication) {
+	ksvc.Spec.Traffic = nil
+	if cr.Spec.Knative == nil {
+		return
+	}
+	for _, t := range cr.Spec.Knative.Traffic {
+		target := servingv1alpha1.TrafficTarget{}
+		target.Tag = t.Tag
+		if t.RevisionName != "" {
+			target.RevisionName = t.RevisionName
+		} else {
+			latestRevision := true
+			target.LatestRevision = &latestRevision
+		}
+		if t.Percent != nil {
+			target.Percent = int(*t.Percent)
+		}
+		ksvc.Spec.Traffic = append(ksvc.Spec.Traffic, target)
+	}
+}
+
+// GetKnativeStatus returns the status of the revisions of the Knative Service and of their traffic
+func GetKnativeStatus(ksvc *servingv1alpha1.Service, apiVersion string) *appsodyv1beta1.StatusKnative {
+	status := &appsodyv1beta1.StatusKnative{
+		APIVersion:                apiVersion,
+		LatestCreatedRevisionName: ksvc.Status.LatestCreatedRevisionName,
+		LatestReadyRevisionName:   ksvc.Status.LatestReadyRevisionName,
+	}
+	if ksvc.Status.URL != nil {
+		status.URL = ksvc.Status.URL.String()
+	}
+	for _, t := range ksvc.Status.Traffic {
+		traffic := appsodyv1beta1.StatusKnativeTraffic{
+			RevisionName:   t.RevisionName,
+			LatestRevision: t.LatestRevision != nil && *t.LatestRevision,
+			Percent:        int32(t.Percent),
+			Tag:            t.Tag,
+		}
+		if t.URL != nil {
+			traffic.URL = t.URL.String()
+		}
+		status.Traffic = append(status.Traffic, traffic)
+	}
+	return status
+}
+
+// IsKnativeServiceReady returns true if Knative has observed the latest generation of the Knative Service and its
+// Ready condition is true
+func IsKnativeServiceReady(obj *unstructured.Unstructured) bool {
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if observedGeneration < obj.GetGeneration() {
+		return false
+	}
+	ready, _ := IsEventResourceReady(obj)
+	return ready
+}
+
+// IsKnativeConfigurationReady returns true if Knative has observed the latest generation of the Knative Service and
+// its ConfigurationsReady condition is true, meaning that its latest revision is ready to serve
+func IsKnativeConfigurationReady(obj *unstructured.Unstructured) bool {
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if observedGeneration < obj.GetGeneration() {
+		return false
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == "ConfigurationsReady" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "github.com/knative/serving/pkg/apis/serving/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/apis"
+)
+
+func TestCustomizeKnativeAutoscaling(t *testing.T) {
+	concurrency, timeout, minScale, maxScale, utilization := int64(10), int64(60), int32(1), int32(5), int32(70)
+	cr := &appsodyv1beta1.AppsodyApplication{
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Knative: &appsodyv1beta1.AppsodyKnative{
+				ContainerConcurrency:          &concurrency,
+				TimeoutSeconds:                &timeout,
+				MinScale:                      &minScale,
+				MaxScale:                      &maxScale,
+				TargetUtilizationPercentage:   &utilization,
+				ScaleToZeroPodRetentionPeriod: "1m",
+			},
+		},
+	}
+
+	ksvc := &servingv1alpha1.Service{}
+	ksvc.Spec.Template = &servingv1alpha1.RevisionTemplateSpec{}
+	CustomizeKnativeAutoscaling(ksvc, cr.Spec.Knative)
+	annotations := ksvc.Spec.Template.Annotations
+	tests := []Test{
+		{"container concurrency", servingv1beta1.RevisionContainerConcurrencyType(10), ksvc.Spec.Template.Spec.ContainerConcurrency},
+		{"timeout", &timeout, ksvc.Spec.Template.Spec.TimeoutSeconds},
+		{"min scale", "1", annotations[knativeMinScaleAnnotation]},
+		{"max scale", "5", annotations[knativeMaxScaleAnnotation]},
+		{"target utilization", "70", annotations[knativeTargetUtilizationPercentageAnnotation]},
+		{"scale to zero retention", "1m", annotations[knativeScaleToZeroPodRetentionPeriodAnnotation]},
+	}
+
+	// The autoscaling annotations are removed with the knative configuration
+	cr.Spec.Knative = nil
+	CustomizeKnativeAutoscaling(ksvc, cr.Spec.Knative)
+	tests = append(tests, []Test{
+		{"default container concurrency", servingv1beta1.RevisionContainerConcurrencyType(0), ksvc.Spec.Template.Spec.ContainerConcurrency},
+		{"default timeout", (*int64)(nil), ksvc.Spec.Template.Spec.TimeoutSeconds},
+		{"annotations removed", map[string]string{}, ksvc.Spec.Template.Annotations},
+	}...)
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestCustomizeKnativeTraffic(t *testing.T) {
+	ninety, ten := int32(90), int32(10)
+	cr := &appsodyv1beta1.AppsodyApplication{
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Knative: &appsodyv1beta1.AppsodyKnative{
+				Traffic: []appsodyv1beta1.AppsodyKnativeTraffic{
+					{RevisionName: "app-00001", Percent: &ninety},
+					{Percent: &ten, Tag: "preview"},
+				},
+			},
+		},
+	}
+
+	ksvc := &servingv1alpha1.Service{}
+	CustomizeKnativeTraffic(ksvc, cr)
+	traffic := ksvc.Spec.Traffic
+
+	latestRevision := true
+	ksvc.Status.LatestReadyRevisionName = "app-00002"
+	ksvc.Status.URL = &apis.URL{Scheme: "http", Host: "app.team.example.com"}
+	ksvc.Status.Traffic = []servingv1alpha1.TrafficTarget{{TrafficTarget: servingv1beta1.TrafficTarget{
+		Tag:            "preview",
+		RevisionName:   "app-00002",
+		LatestRevision: &latestRevision,
+		Percent:        10,
+		URL:            &apis.URL{Scheme: "http", Host: "preview-app.team.example.com"},
+	}}}
+	status := GetKnativeStatus(ksvc, "serving.knative.dev/v1")
+
+	cr.Spec.Knative = nil
+	CustomizeKnativeTraffic(ksvc, cr)
+
+	tests := []Test{
+		{"traffic targets", 2, len(traffic)},
+		{"pinned revision", "app-00001", traffic[0].RevisionName},
+		{"pinned percent", 90, traffic[0].Percent},
+		{"pinned latest revision", (*bool)(nil), traffic[0].LatestRevision},
+		{"latest revision", true, *traffic[1].LatestRevision},
+		{"tag", "preview", traffic[1].Tag},
+		{"default traffic", []servingv1alpha1.TrafficTarget(nil), ksvc.Spec.Traffic},
+		{"status", &appsodyv1beta1.StatusKnative{
+			APIVersion:              "serving.knative.dev/v1",
+			URL:                     "http://app.team.example.com",
+			LatestReadyRevisionName: "app-00002",
+			Traffic: []appsodyv1beta1.StatusKnativeTraffic{
+				{RevisionName: "app-00002", LatestRevision: true, Percent: 10, Tag: "preview", URL: "http://preview-app.team.example.com"},
+			},
+		}, status},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestIsKnativeServiceReady(t *testing.T) {
+	ksvc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	ksvc.SetGeneration(2)
+	created := IsKnativeServiceReady(ksvc)
+
+	ksvc.Object["status"] = map[string]interface{}{
+		"observedGeneration": int64(1),
+		"conditions":         []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+	}
+	stale := IsKnativeServiceReady(ksvc)
+
+	ksvc.Object["status"].(map[string]interface{})["observedGeneration"] = int64(2)
+	ready := IsKnativeServiceReady(ksvc)
+
+	tests := []Test{
+		{"created", false, created},
+		{"previous generation ready", false, stale},
+		{"ready", true, ready},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+func TestIsKnativeConfigurationReady(t *testing.T) {
+	ksvc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	ksvc.SetGeneration(2)
+	ksvc.Object["status"] = map[string]interface{}{
+		"observedGeneration": int64(2),
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "ConfigurationsReady", "status": "Unknown"},
+			map[string]interface{}{"type": "Ready", "status": "Unknown"},
+		},
+	}
+	starting := IsKnativeConfigurationReady(ksvc)
+
+	// The latest revision is ready before the Route of the Knative Service
+	ksvc.Object["status"].(map[string]interface{})["conditions"].([]interface{})[0] = map[string]interface{}{"type": "ConfigurationsReady", "status": "True"}
+	ready := IsKnativeConfigurationReady(ksvc)
+
+	ksvc.SetGeneration(3)
+	stale := IsKnativeConfigurationReady(ksvc)
+
+	tests := []Test{
+		{"revision starting", false, starting},
+		{"revision ready", true, ready},
+		{"previous generation ready", false, stale},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// OpConfigNetworkPolicyIngressNamespaceSelector is the operator configuration of the label selector of the
+	// namespaces of the router or ingress controller, allowed to reach exposed applications
+	OpConfigNetworkPolicyIngressNamespaceSelector = "networkPolicyIngressNamespaceSelector"
+	// OpConfigNetworkPolicyMonitoringNamespaceSelector is the operator configuration of the label selector of the
+	// namespaces of Prometheus, allowed to reach monitored applications
+	OpConfigNetworkPolicyMonitoringNamespaceSelector = "networkPolicyMonitoringNamespaceSelector"
+
+	// NamespaceNameLabel is the label holding the name of a namespace, set on all namespaces since Kubernetes 1.21
+	NamespaceNameLabel = "kubernetes.io/metadata.name"
+
+	// openShiftPolicyGroupLabel groups the OpenShift namespaces of the router and of the monitoring stack
+	openShiftPolicyGroupLabel = "network.openshift.io/policy-group"
+)
+
+// GetNetworkPolicyNamespaceSelector returns the label selector of the namespaces in the operator configuration key.
+// When it is not configured, it selects the OpenShift namespaces of the policy group on OpenShift, and returns nil
+// otherwise, as these namespaces can't be told apart from the others.
+func GetNetworkPolicyNamespaceSelector(key string, openShiftPolicyGroup string, isOpenShift bool) (*metav1.LabelSelector, error) {
+	if config := strings.TrimSpace(common.Config[key]); config != "" {
+		return metav1.ParseToLabelSelector(config)
+	}
+	if isOpenShift {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{openShiftPolicyGroupLabel: openShiftPolicyGroup}}, nil
+	}
+	return nil, nil
+}
+
+// IsConsumerOf returns true if the consumer application consumes the service provided by the provider application
+func IsConsumerOf(consumer *appsodyv1beta1.AppsodyApplication, provider *appsodyv1beta1.AppsodyApplication) bool {
+	if consumer.Spec.Service == nil {
+		return false
+	}
+	for _, c := range consumer.Spec.Service.Consumes {
+		namespace := c.Namespace
+		if namespace == "" {
+			namespace = consumer.Namespace
+		}
+		if c.Name == provider.Name && namespace == provider.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// applicationPeer returns the peer selecting the pods of an application, seen from the namespace of the policy
+func applicationPeer(name string, namespace string, policyNamespace string) networkingv1.NetworkPolicyPeer {
+	peer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/instance": name}},
+	}
+	if namespace != policyNamespace {
+		peer.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{NamespaceNameLabel: namespace}}
+	}
+	return peer
+}
+
+// getServiceTargetPorts returns the container ports of the service of the application
+func getServiceTargetPorts(cr *appsodyv1beta1.AppsodyApplication) []networkingv1.NetworkPolicyPort {
+	svc := cr.Spec.Service
+	port := svc.Port
+	if svc.TargetPort != nil {
+		port = *svc.TargetPort
+	}
+	ports := []int32{port}
+	for _, p := range svc.Ports {
+		if targetPort := p.TargetPort.IntValue(); targetPort != 0 {
+			ports = append(ports, int32(targetPort))
+		} else {
+			ports = append(ports, p.Port)
+		}
+	}
+
+	policyPorts := []networkingv1.NetworkPolicyPort{}
+	for _, p := range ports {
+		protocol, value := corev1.ProtocolTCP, intstr.FromInt(int(p))
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &value})
+	}
+	return policyPorts
+}
+
+// CustomizeNetworkPolicy sets up the NetworkPolicy of the application. The application pods only accept traffic on
+// the service ports from the router or ingress controller when exposed and from the consumers of the application, on
+// all ports from Prometheus when monitored, and from the listed peers. The router and Prometheus are not allowed when
+// the selector of their namespaces is nil. When egress is restricted, the application
+// pods can only reach DNS, the services they consume, and the listed peers.
+func CustomizeNetworkPolicy(np *networkingv1.NetworkPolicy, cr *appsodyv1beta1.AppsodyApplication, consumers []appsodyv1beta1.AppsodyApplication, ingressNamespaces *metav1.LabelSelector, monitoringNamespaces *metav1.LabelSelector) {
+	np.Labels = cr.GetLabels()
+	np.Annotations = oputils.MergeMaps(np.Annotations, cr.GetAnnotations())
+	np.Spec.PodSelector = metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/instance": cr.Name}}
+	np.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+
+	ports := getServiceTargetPorts(cr)
+	np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{}
+	if cr.Spec.Expose != nil && *cr.Spec.Expose && ingressNamespaces != nil {
+		np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: ingressNamespaces}},
+			Ports: ports,
+		})
+	}
+	if len(consumers) > 0 {
+		rule := networkingv1.NetworkPolicyIngressRule{Ports: ports}
+		for _, consumer := range consumers {
+			rule.From = append(rule.From, applicationPeer(consumer.Name, consumer.Namespace, cr.Namespace))
+		}
+		np.Spec.Ingress = append(np.Spec.Ingress, rule)
+	}
+	if cr.Spec.Monitoring != nil && monitoringNamespaces != nil {
+		np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: monitoringNamespaces}},
+		})
+	}
+	if len(cr.Spec.NetworkPolicy.From) > 0 {
+		np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: cr.Spec.NetworkPolicy.From})
+	}
+
+	np.Spec.Egress = nil
+	egress := cr.Spec.NetworkPolicy.Egress
+	if egress == nil {
+		return
+	}
+	np.Spec.PolicyTypes = append(np.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+	udp, tcp, dns := corev1.ProtocolUDP, corev1.ProtocolTCP, intstr.FromInt(53)
+	np.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{
+		{Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &dns}, {Protocol: &tcp, Port: &dns}}},
+	}
+	if cr.Spec.Service != nil && len(cr.Spec.Service.Consumes) > 0 {
+		rule := networkingv1.NetworkPolicyEgressRule{}
+		for _, c := range cr.Spec.Service.Consumes {
+			namespace := c.Namespace
+			if namespace == "" {
+				namespace = cr.Namespace
+			}
+			rule.To = append(rule.To, applicationPeer(c.Name, namespace, cr.Namespace))
+		}
+		np.Spec.Egress = append(np.Spec.Egress, rule)
+	}
+	if len(egress.To) > 0 {
+		np.Spec.Egress = append(np.Spec.Egress, networkingv1.NetworkPolicyEgressRule{To: egress.To})
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCustomizeNetworkPolicy(t *testing.T) {
+	expose, targetPort := true, int32(8080)
+	cr := &appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Expose:     &expose,
+			Service:    &appsodyv1beta1.AppsodyApplicationService{Port: 3000, TargetPort: &targetPort, Consumes: []appsodyv1beta1.ServiceBindingConsumes{{Name: "db", Namespace: "data"}}},
+			Monitoring: &appsodyv1beta1.AppsodyApplicationMonitoring{},
+			NetworkPolicy: &appsodyv1beta1.AppsodyNetworkPolicy{
+				From:   []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}}},
+				Egress: &appsodyv1beta1.AppsodyNetworkPolicyEgress{},
+			},
+		},
+	}
+	frontend := appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "shop"},
+		Spec:       appsodyv1beta1.AppsodyApplicationSpec{Service: &appsodyv1beta1.AppsodyApplicationService{Consumes: []appsodyv1beta1.ServiceBindingConsumes{{Name: "orders"}}}},
+	}
+	other := appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "other"},
+		Spec:       appsodyv1beta1.AppsodyApplicationSpec{Service: &appsodyv1beta1.AppsodyApplicationService{Consumes: []appsodyv1beta1.ServiceBindingConsumes{{Name: "orders"}}}},
+	}
+
+	defer func(config common.OpConfig) { common.Config = config }(common.Config)
+	common.Config = common.OpConfig{OpConfigNetworkPolicyIngressNamespaceSelector: "ingress=true"}
+	ingressNamespaces, _ := GetNetworkPolicyNamespaceSelector(OpConfigNetworkPolicyIngressNamespaceSelector, "ingress", true)
+	monitoringNamespaces, _ := GetNetworkPolicyNamespaceSelector(OpConfigNetworkPolicyMonitoringNamespaceSelector, "monitoring", true)
+
+	np := &networkingv1.NetworkPolicy{}
+	CustomizeNetworkPolicy(np, cr, []appsodyv1beta1.AppsodyApplication{frontend}, ingressNamespaces, monitoringNamespaces)
+
+	tests := []Test{
+		{"consumer", true, IsConsumerOf(&frontend, cr)},
+		{"consumer in another namespace", false, IsConsumerOf(&other, cr)},
+		{"policy types", []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, np.Spec.PolicyTypes},
+		{"ingress rules", 4, len(np.Spec.Ingress)},
+		{"ingress namespaces", map[string]string{"ingress": "true"}, np.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels},
+		{"target port", 8080, np.Spec.Ingress[0].Ports[0].Port.IntValue()},
+		{"consumer peer", map[string]string{"app.kubernetes.io/instance": "frontend"}, np.Spec.Ingress[1].From[0].PodSelector.MatchLabels},
+		{"consumer in same namespace", (*metav1.LabelSelector)(nil), np.Spec.Ingress[1].From[0].NamespaceSelector},
+		{"monitoring namespaces", map[string]string{openShiftPolicyGroupLabel: "monitoring"}, np.Spec.Ingress[2].From[0].NamespaceSelector.MatchLabels},
+		{"peers", cr.Spec.NetworkPolicy.From, np.Spec.Ingress[3].From},
+		{"egress rules", 2, len(np.Spec.Egress)},
+		{"consumed namespace", map[string]string{NamespaceNameLabel: "data"}, np.Spec.Egress[1].To[0].NamespaceSelector.MatchLabels},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+
+	// Off OpenShift, the router and Prometheus are not allowed until the selectors of their namespaces are configured
+	ingressNamespaces, _ = GetNetworkPolicyNamespaceSelector(OpConfigNetworkPolicyIngressNamespaceSelector, "ingress", false)
+	unconfiguredNamespaces, _ := GetNetworkPolicyNamespaceSelector(OpConfigNetworkPolicyMonitoringNamespaceSelector, "monitoring", false)
+	CustomizeNetworkPolicy(np, cr, nil, ingressNamespaces, unconfiguredNamespaces)
+	unconfiguredTests := []Test{
+		{"unconfigured namespaces", (*metav1.LabelSelector)(nil), unconfiguredNamespaces},
+		{"ingress rules", 2, len(np.Spec.Ingress)},
+		{"ingress namespaces", map[string]string{"ingress": "true"}, np.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels},
+		{"peers", cr.Spec.NetworkPolicy.From, np.Spec.Ingress[1].From},
+	}
+	for _, tt := range unconfiguredTests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("unconfigured %s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+
+	// Without any allowed peer, all the traffic to the application pods is denied
+	cr.Spec.Expose, cr.Spec.Monitoring, cr.Spec.NetworkPolicy = nil, nil, &appsodyv1beta1.AppsodyNetworkPolicy{}
+	CustomizeNetworkPolicy(np, cr, nil, ingressNamespaces, monitoringNamespaces)
+	denyTests := []Test{
+		{"policy types", []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, np.Spec.PolicyTypes},
+		{"ingress rules", []networkingv1.NetworkPolicyIngressRule{}, np.Spec.Ingress},
+		{"egress rules", []networkingv1.NetworkPolicyEgressRule(nil), np.Spec.Egress},
+	}
+	for _, tt := range denyTests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("default deny %s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
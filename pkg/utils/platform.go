@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ArchitectureLabel is the node label holding the architecture of the node
+const ArchitectureLabel = "kubernetes.io/arch"
+
+// imageManifest holds the fields used by the operator of a manifest list, an image index, or the manifest of a
+// single-platform image
+type imageManifest struct {
+	Manifests []struct {
+		Digest   string         `json:"digest"`
+		Platform *imagePlatform `json:"platform"`
+	} `json:"manifests"`
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+type imagePlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// imageConfig holds the fields used by the operator of the configuration of a single-platform image
+type imageConfig struct {
+	imagePlatform
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// getManifest returns the manifest of the image tagged or referenced by digest as reference
+func (s *registrySession) getManifest(reference string) (*imageManifest, error) {
+	resp, err := s.do(http.MethodGet, s.url("manifests/"+reference), strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get manifest %s of %s: registry returned %s", reference, s.ref.RepositoryName(), resp.Status)
+	}
+	manifest := &imageManifest{}
+	if err = json.NewDecoder(resp.Body).Decode(manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) == 0 && manifest.Config.Digest == "" {
+		return nil, fmt.Errorf("unsupported format of manifest %s of %s", reference, s.ref.RepositoryName())
+	}
+	return manifest, nil
+}
+
+// getImageConfig returns the configuration blob of a single-platform image
+func (s *registrySession) getImageConfig(digest string) (*imageConfig, error) {
+	content, err := s.getBlob(digest)
+	if err != nil {
+		return nil, err
+	}
+	config := &imageConfig{}
+	if err = json.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to read image configuration %s: %v", digest, err)
+	}
+	return config, nil
+}
+
+// reference returns the digest of the image if it is referenced by digest, or else its tag
+func (s *registrySession) reference() string {
+	if s.ref.ID != "" {
+		return s.ref.ID
+	}
+	return s.ref.Tag
+}
+
+// GetImageArchitectures returns the sorted architectures of the linux platforms of an image, read from its manifest
+// list, or from its configuration when it is a single-platform image. Credentials for the registry are read from the
+// pull secret, if any.
+func GetImageArchitectures(client *http.Client, image string, pullSecret *corev1.Secret) ([]string, error) {
+	s, err := newRegistrySession(client, image, pullSecret)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := s.getManifest(s.reference())
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := []imagePlatform{}
+	if len(manifest.Manifests) > 0 {
+		for _, m := range manifest.Manifests {
+			if m.Platform != nil {
+				platforms = append(platforms, *m.Platform)
+			}
+		}
+	} else {
+		config, err := s.getImageConfig(manifest.Config.Digest)
+		if err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, config.imagePlatform)
+	}
+
+	archs := map[string]bool{}
+	for _, p := range platforms {
+		// Attestation manifests are listed with the platform unknown/unknown
+		if p.OS == "linux" && p.Architecture != "" {
+			archs[p.Architecture] = true
+		}
+	}
+	return sortedKeys(archs), nil
+}
+
+// CustomizeArchitectureAffinity requires the pods to be scheduled on nodes with one of the architectures
+func CustomizeArchitectureAffinity(pts *corev1.PodTemplateSpec, archs []string) {
+	if pts.Spec.Affinity == nil {
+		pts.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pts.Spec.Affinity.NodeAffinity == nil {
+		pts.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := pts.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	nodeSelector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector.NodeSelectorTerms = append(nodeSelector.NodeSelectorTerms, corev1.NodeSelectorTerm{})
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		nodeSelector.NodeSelectorTerms[i].MatchExpressions = append(nodeSelector.NodeSelectorTerms[i].MatchExpressions,
+			corev1.NodeSelectorRequirement{
+				Key:      ArchitectureLabel,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   archs,
+			},
+		)
+	}
+}
+
+// GetUnsupportedArchitectures returns the requested architectures that the image doesn't provide
+func GetUnsupportedArchitectures(requested []string, provided []string) []string {
+	unsupported := []string{}
+	for _, arch := range requested {
+		if !containsString(provided, arch) {
+			unsupported = append(unsupported, arch)
+		}
+	}
+	return unsupported
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetImageArchitectures(t *testing.T) {
+	config := []byte(`{"architecture":"s390x","os":"linux","config":{}}`)
+	sum := sha256.Sum256(config)
+	configDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ns/multi/manifests/1.0":
+			fmt.Fprint(w, `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.list.v2+json","manifests":[
+				{"digest":"sha256:1","platform":{"architecture":"arm64","os":"linux"}},
+				{"digest":"sha256:2","platform":{"architecture":"amd64","os":"linux"}},
+				{"digest":"sha256:3","platform":{"architecture":"arm","os":"linux","variant":"v7"}},
+				{"digest":"sha256:4","platform":{"architecture":"amd64","os":"windows"}},
+				{"digest":"sha256:5","platform":{"architecture":"unknown","os":"unknown"}}]}`)
+		case "/v2/ns/single/manifests/1.0":
+			fmt.Fprintf(w, `{"schemaVersion":2,"config":{"digest":"%s"},"layers":[]}`, configDigest)
+		case "/v2/ns/single/blobs/" + configDigest:
+			w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	multi, err := GetImageArchitectures(server.Client(), registry+"/ns/multi:1.0", nil)
+	if err != nil {
+		t.Fatalf("GetImageArchitectures of a multi-arch image: (%v)", err)
+	}
+	single, err := GetImageArchitectures(server.Client(), registry+"/ns/single:1.0", nil)
+	if err != nil {
+		t.Fatalf("GetImageArchitectures of a single-arch image: (%v)", err)
+	}
+	tests := []Test{
+		{"multi-arch image", []string{"amd64", "arm", "arm64"}, multi},
+		{"single-arch image", []string{"s390x"}, single},
+		{"unsupported architectures", []string{"ppc64le"}, GetUnsupportedArchitectures([]string{"amd64", "ppc64le"}, multi)},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+
+	if _, err = GetImageArchitectures(server.Client(), registry+"/ns/missing:1.0", nil); err == nil {
+		t.Errorf("GetImageArchitectures of a missing image expected an error")
+	}
+}
+
+func TestCustomizeArchitectureAffinity(t *testing.T) {
+	pts := &corev1.PodTemplateSpec{}
+	CustomizeArchitectureAffinity(pts, []string{"amd64", "arm64"})
+	terms := pts.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	expected := []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: ArchitectureLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64", "arm64"}},
+	}}}
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("node selector terms expected: (%v) actual: (%v)", expected, terms)
+	}
+}
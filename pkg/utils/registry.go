@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// manifestMediaTypes are the manifest formats accepted when resolving a tag, so that the digest of a multi-arch image
+// is the digest of its manifest list
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+type registryCredentials struct {
+	username, password string
+}
+
+// registrySession sends requests to the v2 API of the registry of an image, reusing the authorization obtained for
+// the first request that required one
+type registrySession struct {
+	client        *http.Client
+	ref           imagereference.DockerImageReference
+	registry      string
+	creds         *registryCredentials
+	authorization string
+}
+
+func newRegistrySession(client *http.Client, image string, pullSecret *corev1.Secret) (*registrySession, error) {
+	ref, err := imagereference.Parse(image)
+	if err != nil {
+		return nil, err
+	}
+	ref = ref.DockerClientDefaults()
+	creds, err := getRegistryCredentials(pullSecret, ref.Registry)
+	if err != nil {
+		return nil, err
+	}
+	return &registrySession{client: client, ref: ref, registry: ref.AsV2().Registry, creds: creds}, nil
+}
+
+func (s *registrySession) url(path string) *url.URL {
+	return &url.URL{Scheme: "https", Host: s.registry, Path: fmt.Sprintf("/v2/%s/%s", s.ref.RepositoryName(), path)}
+}
+
+// do sends a request to the registry. The caller must close the body of the response.
+func (s *registrySession) do(method string, u *url.URL, accept string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequest(method, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if s.authorization != "" {
+			req.Header.Set("Authorization", s.authorization)
+		}
+		return s.client.Do(req)
+	}
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+	if s.authorization, err = getAuthorization(s.client, resp.Header.Get("WWW-Authenticate"), s.creds); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// ResolveImageDigest resolves the tag of an image to its digest by querying the registry's v2 API, and returns the
+// image referenced by digest. Credentials for the registry are read from the pull secret, if any.
+func ResolveImageDigest(client *http.Client, image string, pullSecret *corev1.Secret) (string, error) {
+	pinned, err := imagereference.Parse(image)
+	if err != nil {
+		return "", err
+	}
+	if pinned.ID != "" {
+		return image, nil
+	}
+	s, err := newRegistrySession(client, image, pullSecret)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.do(http.MethodHead, s.url("manifests/"+s.ref.Tag), strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve the digest of image %q: registry returned %s", image, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("failed to resolve the digest of image %q: registry did not return a digest", image)
+	}
+
+	pinned.Tag = ""
+	pinned.ID = digest
+	return pinned.Exact(), nil
+}
+
+// ListImageTags returns the tags of the repository of an image by querying the registry's v2 API. Credentials for the
+// registry are read from the pull secret, if any.
+func ListImageTags(client *http.Client, image string, pullSecret *corev1.Secret) ([]string, error) {
+	s, err := newRegistrySession(client, image, pullSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	next := s.url("tags/list")
+	for next != nil {
+		resp, err := s.do(http.MethodGet, next, "application/json")
+		if err != nil {
+			return nil, err
+		}
+		page := struct {
+			Tags []string `json:"tags"`
+		}{}
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		} else {
+			err = fmt.Errorf("failed to list the tags of image %q: registry returned %s", image, resp.Status)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+		next, err = nextPage(next, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// nextPage returns the URL of the next page from a Link header such as `</v2/ns/app/tags/list?last=b&n=2>; rel="next"`,
+// or nil on the last page
+func nextPage(current *url.URL, link string) (*url.URL, error) {
+	if !strings.Contains(link, `rel="next"`) {
+		return nil, nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("invalid Link header %q", link)
+	}
+	next, err := current.Parse(link[start+1 : end])
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// getAuthorization returns the Authorization header requested by the registry's challenge
+func getAuthorization(client *http.Client, challenge string, creds *registryCredentials) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if creds == nil {
+			return "", fmt.Errorf("registry requires credentials, set spec.pullSecret")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.username+":"+creds.password)), nil
+	case "bearer":
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return "", fmt.Errorf("invalid registry authentication realm %q", params["realm"])
+		}
+		query := tokenURL.Query()
+		if params["service"] != "" {
+			query.Set("service", params["service"])
+		}
+		if params["scope"] != "" {
+			query.Set("scope", params["scope"])
+		}
+		tokenURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if creds != nil {
+			req.SetBasicAuth(creds.username, creds.password)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to get a registry token: %s", resp.Status)
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", err
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	default:
+		return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+}
+
+// parseChallenge parses a WWW-Authenticate header such as `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq == -1 {
+			break
+		}
+		key := strings.TrimSpace(rest[:eq])
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.Index(rest, ","); comma != -1 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[strings.ToLower(key)] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return parts[0], params
+}
+
+// getRegistryCredentials returns the credentials for the registry found in a docker config pull secret, or nil
+func getRegistryCredentials(secret *corev1.Secret, registry string) (*registryCredentials, error) {
+	if secret == nil {
+		return nil, nil
+	}
+	type authEntry struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	auths := map[string]authEntry{}
+	if data, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		config := struct {
+			Auths map[string]authEntry `json:"auths"`
+		}{}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse pull secret %q: %v", secret.Name, err)
+		}
+		auths = config.Auths
+	} else if data, ok := secret.Data[corev1.DockerConfigKey]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return nil, fmt.Errorf("failed to parse pull secret %q: %v", secret.Name, err)
+		}
+	}
+
+	for server, entry := range auths {
+		if normalizeRegistry(server) != normalizeRegistry(registry) {
+			continue
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode credentials of pull secret %q: %v", secret.Name, err)
+			}
+			userPass := strings.SplitN(string(decoded), ":", 2)
+			if len(userPass) == 2 {
+				return &registryCredentials{username: userPass[0], password: userPass[1]}, nil
+			}
+		}
+		return &registryCredentials{username: entry.Username, password: entry.Password}, nil
+	}
+	return nil, nil
+}
+
+// normalizeRegistry reduces the server of a docker config entry to a host, mapping the Docker Hub aliases to docker.io
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	if i := strings.Index(server, "/"); i != -1 {
+		server = server[:i]
+	}
+	if imagereference.IsRegistryDockerHub(server) {
+		return imagereference.DockerDefaultRegistry
+	}
+	return server
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolveImageDigest(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	basicAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Authorization") != basicAuth || r.URL.Query().Get("scope") != "repository:ns/app:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token":"abc"}`)
+		case "/v2/ns/app/manifests/1.0":
+			if r.Method != http.MethodHead || !strings.Contains(r.Header.Get("Accept"), "manifest.list.v2+json") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:ns/app:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "https://")
+	pullSecret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{"https://%s":{"auth":"%s"}}}`, registry, base64.StdEncoding.EncodeToString([]byte("user:pass")))),
+		},
+	}
+
+	image, err := ResolveImageDigest(server.Client(), registry+"/ns/app:1.0", pullSecret)
+	if err != nil {
+		t.Fatalf("ResolveImageDigest: (%v)", err)
+	}
+	if expected := registry + "/ns/app@" + digest; image != expected {
+		t.Errorf("image expected: (%v) actual: (%v)", expected, image)
+	}
+
+	if _, err = ResolveImageDigest(server.Client(), registry+"/ns/app:1.0", nil); err == nil {
+		t.Error("expected an error resolving the image without credentials")
+	}
+	if _, err = ResolveImageDigest(server.Client(), registry+"/ns/missing:1.0", pullSecret); err == nil {
+		t.Error("expected an error resolving a missing image")
+	}
+
+	pinned := registry + "/ns/app@" + digest
+	if image, err = ResolveImageDigest(server.Client(), pinned, nil); err != nil || image != pinned {
+		t.Errorf("image expected: (%v) actual: (%v, %v)", pinned, image, err)
+	}
+}
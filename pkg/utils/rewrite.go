@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/application-stacks/runtime-component-operator/pkg/common"
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// OpConfigImageRewriteRules is the operator configuration of the registry rewrite rules, with one
+// `<prefix> -> <replacement>` rule per line, e.g. "docker.io/ -> mirror.corp/dockerhub/"
+const OpConfigImageRewriteRules = "imageRewriteRules"
+
+// ImageRewriteRule replaces the prefix of image references
+type ImageRewriteRule struct {
+	Prefix      string
+	Replacement string
+}
+
+// GetImageRewriteRules returns the registry rewrite rules of the operator configuration
+func GetImageRewriteRules() ([]ImageRewriteRule, error) {
+	return ParseImageRewriteRules(common.Config[OpConfigImageRewriteRules])
+}
+
+// ParseImageRewriteRules parses rewrite rules, ignoring empty lines and lines starting with #
+func ParseImageRewriteRules(config string) ([]ImageRewriteRule, error) {
+	rules := []ImageRewriteRule{}
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, "->")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid image rewrite rule %q in operator configuration %s, expected <prefix> -> <replacement>", line, OpConfigImageRewriteRules)
+		}
+		rules = append(rules, ImageRewriteRule{Prefix: strings.TrimSpace(parts[0]), Replacement: strings.TrimSpace(parts[1])})
+	}
+	return rules, nil
+}
+
+// RewriteImage applies the rule with the longest matching prefix to the image. Rules are matched against the image as
+// written and against its fully qualified form, so that "docker.io/library/" matches "nginx:1.19".
+func RewriteImage(image string, rules []ImageRewriteRule) string {
+	candidates := []string{image}
+	if ref, err := imagereference.Parse(image); err == nil {
+		qualified := ref.DockerClientDefaults()
+		qualified.Tag = ref.Tag
+		if q := qualified.Exact(); q != image {
+			candidates = append(candidates, q)
+		}
+	}
+
+	rewritten, matched := image, 0
+	for _, rule := range rules {
+		for _, candidate := range candidates {
+			if len(rule.Prefix) > matched && strings.HasPrefix(candidate, rule.Prefix) {
+				rewritten, matched = rule.Replacement+strings.TrimPrefix(candidate, rule.Prefix), len(rule.Prefix)
+			}
+		}
+	}
+	return rewritten
+}
+
+// RewriteContainerImages returns a copy of the containers with their images rewritten
+func RewriteContainerImages(containers []corev1.Container, rules []ImageRewriteRule) []corev1.Container {
+	if len(containers) == 0 || len(rules) == 0 {
+		return containers
+	}
+	rewritten := make([]corev1.Container, len(containers))
+	for i := range containers {
+		containers[i].DeepCopyInto(&rewritten[i])
+		rewritten[i].Image = RewriteImage(containers[i].Image, rules)
+	}
+	return rewritten
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestRewriteImage(t *testing.T) {
+	rules, err := ParseImageRewriteRules(`
+# Docker Hub images
+docker.io/ -> mirror.corp/dockerhub/
+docker.io/library/ -> mirror.corp/library/
+quay.io/ -> mirror.corp/quay/
+`)
+	if err != nil {
+		t.Fatalf("ParseImageRewriteRules: (%v)", err)
+	}
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"quay.io/my-repo/my-app:1.0", "mirror.corp/quay/my-repo/my-app:1.0"},
+		{"docker.io/my-repo/my-app:1.0", "mirror.corp/dockerhub/my-repo/my-app:1.0"},
+		{"my-repo/my-app:1.0", "mirror.corp/dockerhub/my-repo/my-app:1.0"},
+		{"nginx", "mirror.corp/library/nginx"},
+		{"nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", "mirror.corp/library/nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		{"registry.corp/team/app:2.0", "registry.corp/team/app:2.0"},
+	}
+	for _, tt := range tests {
+		if actual := RewriteImage(tt.image, rules); actual != tt.expected {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.image, tt.expected, actual)
+		}
+	}
+
+	for _, config := range []string{"docker.io/", "docker.io/ -> ", "a -> b -> c"} {
+		if _, err = ParseImageRewriteRules(config); err == nil {
+			t.Errorf("ParseImageRewriteRules(%q) expected an error", config)
+		}
+	}
+}
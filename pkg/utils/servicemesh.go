@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"strconv"
+
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// IstioNetworkingGroup is the API group of the Istio VirtualServices and DestinationRules
+	IstioNetworkingGroup = "networking.istio.io"
+
+	// SidecarInjectAnnotation is the pod annotation enabling or disabling the injection of the Istio sidecar
+	SidecarInjectAnnotation = "sidecar.istio.io/inject"
+
+	// meshGateway is the reserved gateway of the sidecars of the mesh
+	meshGateway = "mesh"
+)
+
+// CustomizeSidecarInjection sets the sidecar injection annotation of the pod template when the application is in the
+// service mesh, and removes it otherwise
+func CustomizeSidecarInjection(meta *metav1.ObjectMeta, cr *appsodyv1beta1.AppsodyApplication) {
+	if cr.Spec.ServiceMesh == nil {
+		delete(meta.Annotations, SidecarInjectAnnotation)
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[SidecarInjectAnnotation] = strconv.FormatBool(cr.IsSidecarInjectionEnabled())
+}
+
+// getServiceHost returns the fully qualified host of the service of the application
+func getServiceHost(cr *appsodyv1beta1.AppsodyApplication) string {
+	return cr.Name + "." + cr.Namespace + ".svc.cluster.local"
+}
+
+// CustomizeVirtualService sets up the VirtualService routing the service and the hosts of the service mesh
+// configuration to the application, with the timeout and retries of the HTTP requests
+func CustomizeVirtualService(vs *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication) {
+	mesh := cr.Spec.ServiceMesh
+	vs.SetLabels(cr.GetLabels())
+	vs.SetAnnotations(oputils.MergeMaps(vs.GetAnnotations(), cr.GetAnnotations()))
+
+	hosts := []interface{}{getServiceHost(cr)}
+	for _, host := range mesh.Hosts {
+		hosts = append(hosts, host)
+	}
+	spec := map[string]interface{}{"hosts": hosts}
+	if len(mesh.Gateways) > 0 {
+		gateways := []interface{}{meshGateway}
+		for _, gateway := range mesh.Gateways {
+			gateways = append(gateways, gateway)
+		}
+		spec["gateways"] = gateways
+	}
+
+	route := []interface{}{
+		map[string]interface{}{
+			"destination": map[string]interface{}{
+				"host": getServiceHost(cr),
+				"port": map[string]interface{}{"number": int64(cr.Spec.Service.Port)},
+			},
+		},
+	}
+	switch mesh.Protocol {
+	case "tcp":
+		spec["tcp"] = []interface{}{map[string]interface{}{"route": route}}
+	case "tls":
+		// TLS routes require SNI matches, so the hosts are routed to the application with their SNI
+		sniHosts := []interface{}{}
+		sniHosts = append(sniHosts, hosts...)
+		spec["tls"] = []interface{}{map[string]interface{}{
+			"match": []interface{}{map[string]interface{}{"sniHosts": sniHosts}},
+			"route": route,
+		}}
+	default:
+		httpRoute := map[string]interface{}{"route": route}
+		if mesh.Timeout != "" {
+			httpRoute["timeout"] = mesh.Timeout
+		}
+		if mesh.Retries != nil {
+			retries := map[string]interface{}{"attempts": int64(mesh.Retries.Attempts)}
+			if mesh.Retries.PerTryTimeout != "" {
+				retries["perTryTimeout"] = mesh.Retries.PerTryTimeout
+			}
+			if mesh.Retries.RetryOn != "" {
+				retries["retryOn"] = mesh.Retries.RetryOn
+			}
+			httpRoute["retries"] = retries
+		}
+		spec["http"] = []interface{}{httpRoute}
+	}
+	vs.Object["spec"] = spec
+}
+
+// CustomizeDestinationRule sets up the DestinationRule of the service of the application, with the TLS mode and the
+// circuit breaker of the service mesh configuration
+func CustomizeDestinationRule(dr *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication) {
+	mesh := cr.Spec.ServiceMesh
+	dr.SetLabels(cr.GetLabels())
+	dr.SetAnnotations(oputils.MergeMaps(dr.GetAnnotations(), cr.GetAnnotations()))
+
+	tlsMode := mesh.TLSMode
+	if tlsMode == "" {
+		tlsMode = "ISTIO_MUTUAL"
+	}
+	trafficPolicy := map[string]interface{}{
+		"tls": map[string]interface{}{"mode": tlsMode},
+	}
+
+	if cb := mesh.CircuitBreaker; cb != nil {
+		connectionPool := map[string]interface{}{}
+		if cb.MaxConnections != nil {
+			connectionPool["tcp"] = map[string]interface{}{"maxConnections": int64(*cb.MaxConnections)}
+		}
+		if cb.MaxPendingRequests != nil {
+			connectionPool["http"] = map[string]interface{}{"http1MaxPendingRequests": int64(*cb.MaxPendingRequests)}
+		}
+		if len(connectionPool) > 0 {
+			trafficPolicy["connectionPool"] = connectionPool
+		}
+
+		consecutiveErrors, interval, baseEjectionTime := int64(5), "10s", "30s"
+		if cb.ConsecutiveErrors != nil {
+			consecutiveErrors = int64(*cb.ConsecutiveErrors)
+		}
+		if cb.Interval != "" {
+			interval = cb.Interval
+		}
+		if cb.BaseEjectionTime != "" {
+			baseEjectionTime = cb.BaseEjectionTime
+		}
+		outlierDetection := map[string]interface{}{
+			"interval":         interval,
+			"baseEjectionTime": baseEjectionTime,
+		}
+		// consecutiveErrors is deprecated in networking.istio.io/v1beta1 in favor of consecutive5xxErrors
+		if dr.GetAPIVersion() == IstioNetworkingGroup+"/v1alpha3" {
+			outlierDetection["consecutiveErrors"] = consecutiveErrors
+		} else {
+			outlierDetection["consecutive5xxErrors"] = consecutiveErrors
+		}
+		if cb.MaxEjectionPercent != nil {
+			outlierDetection["maxEjectionPercent"] = int64(*cb.MaxEjectionPercent)
+		}
+		trafficPolicy["outlierDetection"] = outlierDetection
+	}
+
+	dr.Object["spec"] = map[string]interface{}{
+		"host":          getServiceHost(cr),
+		"trafficPolicy": trafficPolicy,
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCustomizeServiceMesh(t *testing.T) {
+	sidecarInjection, maxConnections, consecutiveErrors := false, int32(100), int32(3)
+	cr := &appsodyv1beta1.AppsodyApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: appsodyv1beta1.AppsodyApplicationSpec{
+			Service: &appsodyv1beta1.AppsodyApplicationService{Port: 3000},
+			ServiceMesh: &appsodyv1beta1.AppsodyServiceMesh{
+				Hosts:          []string{"orders.example.com"},
+				Gateways:       []string{"istio-system/public"},
+				Timeout:        "5s",
+				Retries:        &appsodyv1beta1.AppsodyServiceMeshRetries{Attempts: 3, PerTryTimeout: "2s"},
+				CircuitBreaker: &appsodyv1beta1.AppsodyCircuitBreaker{MaxConnections: &maxConnections, ConsecutiveErrors: &consecutiveErrors},
+			},
+		},
+	}
+
+	meta := &metav1.ObjectMeta{}
+	CustomizeSidecarInjection(meta, cr)
+	injected := meta.Annotations[SidecarInjectAnnotation]
+	cr.Spec.ServiceMesh.SidecarInjection = &sidecarInjection
+	CustomizeSidecarInjection(meta, cr)
+	notInjected := meta.Annotations[SidecarInjectAnnotation]
+
+	vs := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	CustomizeVirtualService(vs, cr)
+	hosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts")
+	gateways, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "gateways")
+	httpRoutes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	httpRoute, _ := httpRoutes[0].(map[string]interface{})
+	attempts, _, _ := unstructured.NestedInt64(httpRoute, "retries", "attempts")
+
+	dr := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	dr.SetAPIVersion(IstioNetworkingGroup + "/v1beta1")
+	CustomizeDestinationRule(dr, cr)
+	tlsMode, _, _ := unstructured.NestedString(dr.Object, "spec", "trafficPolicy", "tls", "mode")
+	maxConn, _, _ := unstructured.NestedInt64(dr.Object, "spec", "trafficPolicy", "connectionPool", "tcp", "maxConnections")
+	consecutive5xx, _, _ := unstructured.NestedInt64(dr.Object, "spec", "trafficPolicy", "outlierDetection", "consecutive5xxErrors")
+	interval, _, _ := unstructured.NestedString(dr.Object, "spec", "trafficPolicy", "outlierDetection", "interval")
+
+	dr.SetAPIVersion(IstioNetworkingGroup + "/v1alpha3")
+	CustomizeDestinationRule(dr, cr)
+	consecutiveAlpha, _, _ := unstructured.NestedInt64(dr.Object, "spec", "trafficPolicy", "outlierDetection", "consecutiveErrors")
+
+	cr.Spec.ServiceMesh = nil
+	CustomizeSidecarInjection(meta, cr)
+	_, removed := meta.Annotations[SidecarInjectAnnotation]
+
+	tests := []Test{
+		{"sidecar injected", "true", injected},
+		{"sidecar not injected", "false", notInjected},
+		{"sidecar annotation removed", false, removed},
+		{"hosts", []string{"orders.shop.svc.cluster.local", "orders.example.com"}, hosts},
+		{"gateways", []string{"mesh", "istio-system/public"}, gateways},
+		{"timeout", "5s", httpRoute["timeout"]},
+		{"retry attempts", int64(3), attempts},
+		{"tls mode", "ISTIO_MUTUAL", tlsMode},
+		{"max connections", int64(100), maxConn},
+		{"consecutive 5xx errors", int64(3), consecutive5xx},
+		{"default interval", "10s", interval},
+		{"v1alpha3 consecutive errors", int64(3), consecutiveAlpha},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.actual, tt.expected) {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
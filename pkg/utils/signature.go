@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignSignatureType       = "cosign container image signature"
+
+	// maxBlobSize limits the size of the signature payloads and image configurations read from registries
+	maxBlobSize = 1 << 20
+)
+
+// ImagePolicy lists the public keys trusted to sign the images of the applications of some namespaces or stacks
+type ImagePolicy struct {
+	// Namespaces the policy applies to. The policy applies to all namespaces when empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Stacks the policy applies to. The policy applies to all stacks when empty.
+	Stacks []string `json:"stacks,omitempty"`
+	// PEM encoded public keys
+	PublicKeys []string `json:"publicKeys"`
+
+	keys []crypto.PublicKey
+	// err keeps the reason an invalid policy can't be used, so that the images in its scope fail verification
+	err error
+}
+
+// ParseImagePolicy parses an image policy from the data of the image policy ConfigMap. An invalid policy is returned
+// along with the error and fails the verification of all the images in its scope. The scope of a policy that can't be
+// read at all covers every application.
+func ParseImagePolicy(name string, data string) (*ImagePolicy, error) {
+	policy := &ImagePolicy{}
+	err := yaml.Unmarshal([]byte(data), policy)
+	if err != nil {
+		policy = &ImagePolicy{}
+	} else {
+		policy.keys, err = parsePublicKeys(policy.PublicKeys)
+	}
+	if err != nil {
+		policy.err = fmt.Errorf("image policy %s is invalid: %v", name, err)
+		return policy, policy.err
+	}
+	return policy, nil
+}
+
+func parsePublicKeys(publicKeys []string) ([]crypto.PublicKey, error) {
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("image policy has no public keys")
+	}
+	keys := []crypto.PublicKey{}
+	for _, publicKey := range publicKeys {
+		block, _ := pem.Decode([]byte(publicKey))
+		if block == nil {
+			return nil, fmt.Errorf("image policy has a public key that is not PEM encoded")
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Err returns the reason the policy is invalid, or nil when the policy is valid
+func (p *ImagePolicy) Err() error {
+	return p.err
+}
+
+// AppliesTo returns true if the policy applies to applications of the stack in the namespace
+func (p *ImagePolicy) AppliesTo(namespace string, stack string) bool {
+	return (len(p.Namespaces) == 0 || containsString(p.Namespaces, namespace)) && (len(p.Stacks) == 0 || containsString(p.Stacks, stack))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyImageSignature checks that an image referenced by digest has a cosign signature, stored in its repository, made
+// with one of the public keys of the policy. Credentials for the registry are read from the pull secret, if any.
+func VerifyImageSignature(client *http.Client, image string, pullSecret *corev1.Secret, policy *ImagePolicy) error {
+	if policy.err != nil {
+		return policy.err
+	}
+	ref, err := imagereference.Parse(image)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(ref.ID, "sha256:") {
+		return fmt.Errorf("image %q must be referenced by digest to verify its signature", image)
+	}
+	s, err := newRegistrySession(client, image, pullSecret)
+	if err != nil {
+		return err
+	}
+
+	// cosign stores the signatures of an image in the tag sha256-<hex>.sig of its repository
+	resp, err := s.do(http.MethodGet, s.url("manifests/"+strings.Replace(ref.ID, ":", "-", 1)+".sig"), "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json")
+	if err != nil {
+		return err
+	}
+	manifest := struct {
+		Layers []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		err = json.NewDecoder(resp.Body).Decode(&manifest)
+	case http.StatusNotFound:
+		err = fmt.Errorf("image %q is not signed", image)
+	default:
+		err = fmt.Errorf("failed to get the signatures of image %q: registry returned %s", image, resp.Status)
+	}
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, layer := range manifest.Layers {
+		signature, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureAnnotation])
+		if err != nil || len(signature) == 0 {
+			continue
+		}
+		payload, err := s.getBlob(layer.Digest)
+		if err != nil {
+			return err
+		}
+		if !isSignaturePayloadFor(payload, ref.ID) {
+			continue
+		}
+		for _, key := range policy.keys {
+			if verifySignature(key, payload, signature) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("image %q has no signature made with a trusted public key", image)
+}
+
+// getBlob returns the content of a blob of the repository after checking its digest
+func (s *registrySession) getBlob(digest string) ([]byte, error) {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return nil, fmt.Errorf("unsupported blob digest %q", digest)
+	}
+	resp, err := s.do(http.MethodGet, s.url("blobs/"+digest), "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get blob %s: registry returned %s", digest, resp.Status)
+	}
+	content, err := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxBlobSize})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	if "sha256:"+hex.EncodeToString(sum[:]) != digest {
+		return nil, fmt.Errorf("blob %s does not match its digest", digest)
+	}
+	return content, nil
+}
+
+// isSignaturePayloadFor returns true if the simple signing payload is a cosign signature of the image digest
+func isSignaturePayloadFor(payload []byte, digest string) bool {
+	simpleSigning := struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+			Type string `json:"type"`
+		} `json:"critical"`
+	}{}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return false
+	}
+	return simpleSigning.Critical.Type == cosignSignatureType && simpleSigning.Critical.Image.DockerManifestDigest == digest
+}
+
+func verifySignature(key crypto.PublicKey, payload []byte, signature []byte) bool {
+	hash := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		sig := struct{ R, S *big.Int }{}
+		if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) > 0 {
+			return false
+		}
+		return ecdsa.Verify(k, hash[:], sig.R, sig.S)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyImageSignature(t *testing.T) {
+	signed := "sha256:" + strings.Repeat("a", 64)
+	unsigned := "sha256:" + strings.Repeat("b", 64)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: (%v)", err)
+	}
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"ns/app"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, signed))
+	hash := sha256.Sum256(payload)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("Sign: (%v)", err)
+	}
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatalf("Marshal signature: (%v)", err)
+	}
+	payloadDigest := "sha256:" + hex.EncodeToString(hash[:])
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"layers": []interface{}{map[string]interface{}{
+			"mediaType":   "application/vnd.dev.cosign.simplesigning.v1+json",
+			"digest":      payloadDigest,
+			"annotations": map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		}},
+	})
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/ns/app/manifests/" + strings.Replace(signed, ":", "-", 1) + ".sig":
+			w.Write(manifest)
+		case "/v2/ns/app/blobs/" + payloadDigest:
+			w.Write(payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	image := strings.TrimPrefix(server.URL, "https://") + "/ns/app"
+
+	policy, err := ParseImagePolicy("policy", fmt.Sprintf("publicKeys:\n- |\n%s", indent(publicKeyPEM(t, &key.PublicKey))))
+	if err != nil {
+		t.Fatalf("ParseImagePolicy: (%v)", err)
+	}
+	if err = VerifyImageSignature(server.Client(), image+"@"+signed, nil, policy); err != nil {
+		t.Errorf("VerifyImageSignature of a signed image: (%v)", err)
+	}
+	if err = VerifyImageSignature(server.Client(), image+"@"+unsigned, nil, policy); err == nil {
+		t.Errorf("VerifyImageSignature of an unsigned image expected an error")
+	}
+	if err = VerifyImageSignature(server.Client(), image+":1.0", nil, policy); err == nil {
+		t.Errorf("VerifyImageSignature of an image referenced by tag expected an error")
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: (%v)", err)
+	}
+	otherPolicy, err := ParseImagePolicy("policy", fmt.Sprintf("publicKeys:\n- |\n%s", indent(publicKeyPEM(t, &otherKey.PublicKey))))
+	if err != nil {
+		t.Fatalf("ParseImagePolicy: (%v)", err)
+	}
+	if err = VerifyImageSignature(server.Client(), image+"@"+signed, nil, otherPolicy); err == nil {
+		t.Errorf("VerifyImageSignature with an untrusted key expected an error")
+	}
+
+	policy.Namespaces, policy.Stacks = []string{"prod"}, []string{"nodejs"}
+	appliesTests := []Test{
+		{"namespace and stack", true, policy.AppliesTo("prod", "nodejs")},
+		{"other namespace", false, policy.AppliesTo("dev", "nodejs")},
+		{"other stack", false, policy.AppliesTo("prod", "java-microprofile")},
+	}
+	for _, tt := range appliesTests {
+		if tt.actual != tt.expected {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+
+	invalid, err := ParseImagePolicy("invalid", "namespaces: [prod]\npublicKeys: [not a key]")
+	if err == nil {
+		t.Errorf("ParseImagePolicy with an invalid key expected an error")
+	}
+	unreadable, uerr := ParseImagePolicy("unreadable", "publicKeys: {")
+	if uerr == nil {
+		t.Errorf("ParseImagePolicy with invalid YAML expected an error")
+	}
+	invalidTests := []Test{
+		{"invalid policy error", err, invalid.Err()},
+		{"invalid policy scope", true, invalid.AppliesTo("prod", "nodejs")},
+		{"invalid policy other namespace", false, invalid.AppliesTo("dev", "nodejs")},
+		{"unreadable policy scope", true, unreadable.AppliesTo("dev", "nodejs")},
+		{"invalid policy verification", err, VerifyImageSignature(server.Client(), image+"@"+signed, nil, invalid)},
+	}
+	for _, tt := range invalidTests {
+		if tt.actual != tt.expected {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
+
+type Test struct {
+	test     string
+	expected interface{}
+	actual   interface{}
+}
+
+func publicKeyPEM(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: (%v)", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func indent(s string) string {
+	return "  " + strings.Replace(strings.TrimSpace(s), "\n", "\n  ", -1)
+}
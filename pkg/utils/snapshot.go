@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// VolumeSnapshotGroup is the API group of volume snapshots
+	VolumeSnapshotGroup = "snapshot.storage.k8s.io"
+
+	// BackupIDLabel groups the snapshots taken at the same time
+	BackupIDLabel = "backup.appsody.dev/id"
+	// BackupClaimLabel holds the name of the claim a snapshot was taken from
+	BackupClaimLabel = "backup.appsody.dev/claim"
+	// BackupTriggerLabel holds the reason a snapshot was taken
+	BackupTriggerLabel = "backup.appsody.dev/trigger"
+	// BackupTemplateHashLabel holds the hash of the pod template a rollout snapshot was taken for
+	BackupTemplateHashLabel = "backup.appsody.dev/pod-template-hash"
+	// BackupClaimAnnotation holds the claim a snapshot was taken from so that it can be recreated
+	BackupClaimAnnotation = "backup.appsody.dev/claim"
+
+	// BackupTriggerRollout marks snapshots taken before a rollout
+	BackupTriggerRollout = "rollout"
+	// BackupTriggerScheduled marks snapshots taken on schedule
+	BackupTriggerScheduled = "scheduled"
+
+	backupIDFormat = "20060102-150405"
+)
+
+// GetBackupID returns the ID of a backup taken at the given time
+func GetBackupID(t time.Time) string {
+	return t.UTC().Format(backupIDFormat)
+}
+
+// ParseBackupID returns the time a backup was taken at
+func ParseBackupID(id string) (time.Time, error) {
+	return time.ParseInLocation(backupIDFormat, id, time.UTC)
+}
+
+// GetHash returns a short, stable hash of the JSON representation of obj
+func GetHash(obj interface{}) string {
+	data, _ := json.Marshal(obj)
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+}
+
+// CustomizeVolumeSnapshot sets up a volume snapshot of the given claim
+func CustomizeVolumeSnapshot(snapshot *unstructured.Unstructured, pvc *corev1.PersistentVolumeClaim, backupID string, trigger string, cr *appsodyv1beta1.AppsodyApplication) error {
+	labels := snapshot.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["app.kubernetes.io/instance"] = cr.Name
+	labels["app.kubernetes.io/managed-by"] = "appsody-operator"
+	labels[BackupIDLabel] = backupID
+	labels[BackupClaimLabel] = pvc.Name
+	labels[BackupTriggerLabel] = trigger
+	snapshot.SetLabels(labels)
+
+	// Keep what is needed to recreate the claim, without anything tied to the bound volume
+	claim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Labels: pvc.Labels},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources:        pvc.Spec.Resources,
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeMode:       pvc.Spec.VolumeMode,
+		},
+	}
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return err
+	}
+	annotations := snapshot.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[BackupClaimAnnotation] = string(data)
+	snapshot.SetAnnotations(annotations)
+
+	if err = unstructured.SetNestedField(snapshot.Object, pvc.Name, "spec", "source", "persistentVolumeClaimName"); err != nil {
+		return err
+	}
+	if backup := cr.Spec.Storage.Backup; backup != nil && backup.VolumeSnapshotClassName != nil {
+		return unstructured.SetNestedField(snapshot.Object, *backup.VolumeSnapshotClassName, "spec", "volumeSnapshotClassName")
+	}
+	return nil
+}
+
+// GetVolumeSnapshotName returns the name of the snapshot of a claim taken as part of a backup
+func GetVolumeSnapshotName(claimName string, backupID string) string {
+	return claimName + "-" + backupID
+}
+
+// GetSnapshotStatus returns the status of a volume snapshot as reported in the CR
+func GetSnapshotStatus(snapshot *unstructured.Unstructured) appsodyv1beta1.StatusSnapshot {
+	status := appsodyv1beta1.StatusSnapshot{
+		Name:      snapshot.GetName(),
+		BackupID:  snapshot.GetLabels()[BackupIDLabel],
+		ClaimName: snapshot.GetLabels()[BackupClaimLabel],
+		Trigger:   snapshot.GetLabels()[BackupTriggerLabel],
+	}
+	status.ReadyToUse, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	if creationTime, ok, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime"); ok {
+		if t, err := time.Parse(time.RFC3339, creationTime); err == nil {
+			status.CreationTime = &metav1.Time{Time: t}
+		}
+	}
+	return status
+}
+
+// NewClaimFromSnapshot returns a claim, with the same name as the original one, that is populated from the given snapshot
+func NewClaimFromSnapshot(snapshot *unstructured.Unstructured) (*corev1.PersistentVolumeClaim, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	data, ok := snapshot.GetAnnotations()[BackupClaimAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("volume snapshot %q is missing the %q annotation", snapshot.GetName(), BackupClaimAnnotation)
+	}
+	if err := json.Unmarshal([]byte(data), pvc); err != nil {
+		return nil, err
+	}
+	pvc.Name = snapshot.GetLabels()[BackupClaimLabel]
+	pvc.Namespace = snapshot.GetNamespace()
+
+	// The restored volume must be at least as large as the snapshot
+	if restoreSize, ok, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); ok {
+		if size, err := resource.ParseQuantity(restoreSize); err == nil {
+			if requested, found := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; !found || requested.Cmp(size) < 0 {
+				if pvc.Spec.Resources.Requests == nil {
+					pvc.Spec.Resources.Requests = corev1.ResourceList{}
+				}
+				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			}
+		}
+	}
+
+	apiGroup := VolumeSnapshotGroup
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     snapshot.GetName(),
+	}
+	return pvc, nil
+}
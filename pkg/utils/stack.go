@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ImageStackIDLabel is the label of Appsody-built images holding the id of their stack
+	ImageStackIDLabel = "dev.appsody.stack.id"
+	// ImageStackVersionLabel is the label of Appsody-built images holding the version of their stack
+	ImageStackVersionLabel = "dev.appsody.stack.version"
+)
+
+// GetImageLabels returns the labels of an image, read from its configuration. The labels of a multi-arch image are read
+// from its linux/amd64 image, or else from its first linux image. Credentials for the registry are read from the pull
+// secret, if any.
+func GetImageLabels(client *http.Client, image string, pullSecret *corev1.Secret) (map[string]string, error) {
+	s, err := newRegistrySession(client, image, pullSecret)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := s.getManifest(s.reference())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(manifest.Manifests) > 0 {
+		digest := ""
+		for _, m := range manifest.Manifests {
+			if m.Platform != nil && m.Platform.OS == "linux" {
+				if m.Platform.Architecture == "amd64" {
+					digest = m.Digest
+					break
+				}
+				if digest == "" {
+					digest = m.Digest
+				}
+			}
+		}
+		if digest == "" {
+			digest = manifest.Manifests[0].Digest
+		}
+		if manifest, err = s.getManifest(digest); err != nil {
+			return nil, err
+		}
+		if manifest.Config.Digest == "" {
+			return map[string]string{}, nil
+		}
+	}
+	config, err := s.getImageConfig(manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if config.Config.Labels == nil {
+		return map[string]string{}, nil
+	}
+	return config.Config.Labels, nil
+}
+
+// GetImageStack returns the Appsody stack id and version of an image from its labels. Values that are not valid label
+// values are ignored.
+func GetImageStack(labels map[string]string) (string, string) {
+	id, version := labels[ImageStackIDLabel], labels[ImageStackVersionLabel]
+	if len(validation.IsValidLabelValue(id)) > 0 {
+		id = ""
+	}
+	if id == "" || len(validation.IsValidLabelValue(version)) > 0 {
+		version = ""
+	}
+	return id, version
+}
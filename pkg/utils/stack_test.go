@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetImageLabels(t *testing.T) {
+	config := []byte(`{"architecture":"amd64","os":"linux","config":{"Labels":{"dev.appsody.stack.id":"nodejs-express","dev.appsody.stack.version":"0.4.2"}}}`)
+	sum := sha256.Sum256(config)
+	configDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ns/app/manifests/1.0":
+			fmt.Fprint(w, `{"schemaVersion":2,"manifests":[
+				{"digest":"sha256:arm64","platform":{"architecture":"arm64","os":"linux"}},
+				{"digest":"sha256:amd64","platform":{"architecture":"amd64","os":"linux"}}]}`)
+		case "/v2/ns/app/manifests/sha256:amd64":
+			fmt.Fprintf(w, `{"schemaVersion":2,"config":{"digest":"%s"},"layers":[]}`, configDigest)
+		case "/v2/ns/app/blobs/" + configDigest:
+			w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	image := strings.TrimPrefix(server.URL, "https://") + "/ns/app:1.0"
+
+	labels, err := GetImageLabels(server.Client(), image, nil)
+	if err != nil {
+		t.Fatalf("GetImageLabels: (%v)", err)
+	}
+	id, version := GetImageStack(labels)
+	invalidID, invalidVersion := GetImageStack(map[string]string{ImageStackIDLabel: "not a label value", ImageStackVersionLabel: "1.0"})
+	tests := []Test{
+		{"stack id", "nodejs-express", id},
+		{"stack version", "0.4.2", version},
+		{"invalid stack id", "", invalidID},
+		{"version of invalid stack id", "", invalidVersion},
+	}
+	for _, tt := range tests {
+		if tt.actual != tt.expected {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+}
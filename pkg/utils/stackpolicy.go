@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blang/semver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// StackPolicyEnforcement defines what happens to the applications using a stack that is not allowed by a stack policy
+type StackPolicyEnforcement string
+
+const (
+	// StackPolicyEnforcementWarn reports the violation and keeps reconciling the application
+	StackPolicyEnforcementWarn StackPolicyEnforcement = "Warn"
+	// StackPolicyEnforcementBlock reports the violation and stops reconciling the application
+	StackPolicyEnforcementBlock StackPolicyEnforcement = "Block"
+
+	// stackDeprecationDateFormat is the format of the deprecation dates of the allowed stacks
+	stackDeprecationDateFormat = "2006-01-02"
+)
+
+// StackPolicy lists the stacks allowed for the applications of some namespaces
+type StackPolicy struct {
+	// Namespaces the policy applies to
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Labels of the namespaces the policy applies to. The policy applies to all namespaces when neither namespaces nor
+	// namespaceSelector are set.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Warn (default) or Block
+	Enforcement StackPolicyEnforcement `json:"enforcement,omitempty"`
+	Stacks      []AllowedStack         `json:"stacks"`
+
+	selector labels.Selector
+}
+
+// AllowedStack is a stack allowed by a stack policy
+type AllowedStack struct {
+	ID string `json:"id"`
+	// Semantic version range of the allowed stack versions. All versions are allowed when empty.
+	Versions string `json:"versions,omitempty"`
+	// Date the stack is deprecated on, as YYYY-MM-DD
+	DeprecationDate string `json:"deprecationDate,omitempty"`
+	// Stack to use instead of the deprecated stack
+	Replacement string `json:"replacement,omitempty"`
+
+	versions    semver.Range
+	deprecation time.Time
+}
+
+// StackCompliance is the result of checking a stack against a stack policy
+type StackCompliance struct {
+	// Reason the stack is not compliant, or empty when it is
+	Reason  string
+	Message string
+	// Time the stack is deprecated on, when it is compliant and deprecated in the future
+	Deprecation time.Time
+}
+
+// ParseStackPolicy parses a stack policy from the data of the stack policy ConfigMap
+func ParseStackPolicy(data string) (*StackPolicy, error) {
+	policy := &StackPolicy{}
+	if err := yaml.Unmarshal([]byte(data), policy); err != nil {
+		return nil, err
+	}
+	switch policy.Enforcement {
+	case "":
+		policy.Enforcement = StackPolicyEnforcementWarn
+	case StackPolicyEnforcementWarn, StackPolicyEnforcementBlock:
+	default:
+		return nil, fmt.Errorf("invalid stack policy enforcement %q, expected Warn or Block", policy.Enforcement)
+	}
+	if policy.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		policy.selector = selector
+	}
+	for i := range policy.Stacks {
+		stack := &policy.Stacks[i]
+		if stack.ID == "" {
+			return nil, fmt.Errorf("stack policy has a stack without id")
+		}
+		var err error
+		if stack.Versions != "" {
+			if stack.versions, err = semver.ParseRange(stack.Versions); err != nil {
+				return nil, fmt.Errorf("invalid versions of stack %s: %v", stack.ID, err)
+			}
+		}
+		if stack.DeprecationDate != "" {
+			if stack.deprecation, err = time.ParseInLocation(stackDeprecationDateFormat, stack.DeprecationDate, time.UTC); err != nil {
+				return nil, fmt.Errorf("invalid deprecation date of stack %s, expected YYYY-MM-DD: %v", stack.ID, err)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// AppliesTo returns true if the policy applies to the applications of the namespace
+func (p *StackPolicy) AppliesTo(namespace string, namespaceLabels map[string]string) bool {
+	if len(p.Namespaces) == 0 && p.selector == nil {
+		return true
+	}
+	return containsString(p.Namespaces, namespace) || (p.selector != nil && namespaceLabels != nil && p.selector.Matches(labels.Set(namespaceLabels)))
+}
+
+// Check checks a stack against the policy. The version is not checked when it is empty.
+func (p *StackPolicy) Check(id string, version string, now time.Time) StackCompliance {
+	var v *semver.Version
+	if version != "" {
+		if parsed, err := semver.ParseTolerant(version); err == nil {
+			v = &parsed
+		}
+	}
+
+	found, replacement := false, ""
+	for _, stack := range p.Stacks {
+		if stack.ID != id {
+			continue
+		}
+		found = true
+		if stack.Replacement != "" {
+			replacement = stack.Replacement
+		}
+		if stack.versions != nil && v != nil && !stack.versions(*v) {
+			continue
+		}
+		if !stack.deprecation.IsZero() && !now.Before(stack.deprecation) {
+			return StackCompliance{Reason: "StackDeprecated", Message: withReplacement(fmt.Sprintf("Stack %s was deprecated on %s", id, stack.DeprecationDate), stack.Replacement)}
+		}
+		return StackCompliance{Deprecation: stack.deprecation}
+	}
+	if found {
+		return StackCompliance{Reason: "StackVersionNotAllowed", Message: withReplacement(fmt.Sprintf("Version %s of stack %s is not allowed", version, id), replacement)}
+	}
+	return StackCompliance{Reason: "StackNotAllowed", Message: fmt.Sprintf("Stack %s is not allowed", id)}
+}
+
+func withReplacement(message string, replacement string) string {
+	if replacement == "" {
+		return message
+	}
+	return fmt.Sprintf("%s, use stack %s instead", message, replacement)
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStackPolicy(t *testing.T) {
+	policy, err := ParseStackPolicy(`
+namespaceSelector:
+  matchLabels:
+    env: prod
+enforcement: Block
+stacks:
+- id: nodejs-express
+  versions: ">=0.4.0"
+  replacement: nodejs-fastify
+- id: java-microprofile
+  deprecationDate: "2026-06-30"
+  replacement: java-openliberty
+- id: java-openliberty
+  deprecationDate: "2027-06-30"
+`)
+	if err != nil {
+		t.Fatalf("ParseStackPolicy: (%v)", err)
+	}
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []Test{
+		{"applies to selected namespace", true, policy.AppliesTo("team-a", map[string]string{"env": "prod"})},
+		{"applies to other namespace", false, policy.AppliesTo("team-a", map[string]string{"env": "dev"})},
+		{"allowed version", "", policy.Check("nodejs-express", "0.4.2", now).Reason},
+		{"unknown version", "", policy.Check("nodejs-express", "", now).Reason},
+		{"version not allowed", "StackVersionNotAllowed", policy.Check("nodejs-express", "0.2.1", now).Reason},
+		{"version not allowed message", "Version 0.2.1 of stack nodejs-express is not allowed, use stack nodejs-fastify instead", policy.Check("nodejs-express", "0.2.1", now).Message},
+		{"deprecated", "StackDeprecated", policy.Check("java-microprofile", "", now).Reason},
+		{"deprecated message", "Stack java-microprofile was deprecated on 2026-06-30, use stack java-openliberty instead", policy.Check("java-microprofile", "", now).Message},
+		{"deprecation scheduled", time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC), policy.Check("java-openliberty", "", now).Deprecation},
+		{"not allowed", "StackNotAllowed", policy.Check("python-flask", "", now).Reason},
+	}
+	for _, tt := range tests {
+		if tt.actual != tt.expected {
+			t.Errorf("%s expected: (%v) actual: (%v)", tt.test, tt.expected, tt.actual)
+		}
+	}
+
+	for _, data := range []string{"enforcement: Deny", "stacks: [{versions: '>=1.0.0'}]", "stacks: [{id: a, deprecationDate: tomorrow}]", "stacks: [{id: a, versions: latest}]"} {
+		if _, err = ParseStackPolicy(data); err == nil {
+			t.Errorf("ParseStackPolicy(%q) expected an error", data)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// SharedStorageLabel marks volume claims shared by all pods of an application
+	SharedStorageLabel = "storage.appsody.dev/shared"
+
+	defaultSharedVolumeName = "pvc"
+
+	// ResourceRecommendationConflict is the reason of the Reconciled condition when the VerticalPodAutoscaler would
+	// update the resources of an application autoscaled on CPU utilization
+	ResourceRecommendationConflict = "ResourceRecommendationConflict"
+
+	// ResourceRecommendationNotSupported is the reason of the Reconciled condition when resource recommendation is
+	// requested for a Knative Service, which the VerticalPodAutoscaler can't target
+	ResourceRecommendationNotSupported = "ResourceRecommendationNotSupported"
+)
+
+// GetSharedVolumeName returns the name of the pod volume backed by the shared volume claim
+func GetSharedVolumeName(cr *appsodyv1beta1.AppsodyApplication) string {
+	if vct := cr.Spec.Storage.VolumeClaimTemplate; vct != nil && vct.Name != "" {
+		return vct.Name
+	}
+	return defaultSharedVolumeName
+}
+
+// GetSharedStorageClaimName returns the name of the volume claim shared by all pods of the application
+func GetSharedStorageClaimName(cr *appsodyv1beta1.AppsodyApplication) string {
+	return GetSharedVolumeName(cr) + "-" + cr.Name
+}
+
+// IsStatefulSetClaim returns true if the volume claim was created from a volume claim template of the StatefulSet.
+// The claims of the pods of a StatefulSet are named <template>-<StatefulSet>-<ordinal>.
+func IsStatefulSetClaim(claimName string, statefulSet *appsv1.StatefulSet) bool {
+	for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+		prefix := template.Name + "-" + statefulSet.Name + "-"
+		ordinal := strings.TrimPrefix(claimName, prefix)
+		if strings.HasPrefix(claimName, prefix) && ordinal != "" && strings.Trim(ordinal, "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomizeSharedPersistentVolumeClaim sets up the volume claim shared by all pods of the application.
+// Most of the claim's spec is immutable, so only the requested size is updated after the claim is created.
+func CustomizeSharedPersistentVolumeClaim(pvc *corev1.PersistentVolumeClaim, cr *appsodyv1beta1.AppsodyApplication) {
+	storage := cr.Spec.Storage
+	pvc.Labels = oputils.MergeMaps(pvc.Labels, cr.GetLabels())
+	pvc.Labels[SharedStorageLabel] = "true"
+	pvc.Annotations = oputils.MergeMaps(pvc.Annotations, cr.GetAnnotations())
+
+	spec := corev1.PersistentVolumeClaimSpec{}
+	if storage.VolumeClaimTemplate != nil {
+		spec = *storage.VolumeClaimTemplate.Spec.DeepCopy()
+		pvc.Labels = oputils.MergeMaps(pvc.Labels, storage.VolumeClaimTemplate.Labels)
+		pvc.Annotations = oputils.MergeMaps(pvc.Annotations, storage.VolumeClaimTemplate.Annotations)
+	} else {
+		spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse(storage.Size),
+		}
+	}
+	if len(spec.AccessModes) == 0 {
+		spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	}
+
+	if pvc.CreationTimestamp.IsZero() {
+		pvc.Spec = spec
+		return
+	}
+
+	if size, ok := spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	}
+}
+
+// CustomizeSharedStorage adds the shared volume claim to the pod template and mounts it into the application container
+func CustomizeSharedStorage(pts *corev1.PodTemplateSpec, cr *appsodyv1beta1.AppsodyApplication) {
+	volumeName := GetSharedVolumeName(cr)
+
+	found := false
+	for _, v := range pts.Spec.Volumes {
+		if v.Name == volumeName {
+			found = true
+		}
+	}
+	if !found {
+		pts.Spec.Volumes = append(pts.Spec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: GetSharedStorageClaimName(cr),
+				},
+			},
+		})
+	}
+
+	appContainer := oputils.GetAppContainer(pts.Spec.Containers)
+	if cr.Spec.Storage.MountPath != "" {
+		found = false
+		for _, vm := range appContainer.VolumeMounts {
+			if vm.Name == volumeName {
+				found = true
+			}
+		}
+		if !found {
+			appContainer.VolumeMounts = append(appContainer.VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: cr.Spec.Storage.MountPath,
+			})
+		}
+	}
+}
+
+// Validate returns an error if the application can't be reconciled as specified
+func Validate(cr *appsodyv1beta1.AppsodyApplication) error {
+	if _, err := oputils.Validate(cr); err != nil {
+		return err
+	}
+	if rr := cr.Spec.ResourceRecommendation; rr != nil && rr.GetUpdateMode() == appsodyv1beta1.ResourceRecommendationUpdateModeAuto && IsCPUAutoscaled(cr.Spec.Autoscaling) {
+		return NewReconcileError(ResourceRecommendationConflict, "validation failed: spec.resourceRecommendation.updateMode cannot be Auto when spec.autoscaling scales on CPU utilization, as both would react to the same metric")
+	}
+	if cr.Spec.ResourceRecommendation != nil && cr.Spec.CreateKnativeService != nil && *cr.Spec.CreateKnativeService {
+		return NewReconcileError(ResourceRecommendationNotSupported, "validation failed: spec.resourceRecommendation cannot be set when spec.createKnativeService is true, as Knative scales the resources of its revisions")
+	}
+	if cr.Spec.ImageUpdatePolicy != nil {
+		if err := ValidateImageUpdatePolicy(cr.Spec.ImageUpdatePolicy); err != nil {
+			return fmt.Errorf("validation failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// NewReconcileError returns an error reported with the given reason on the Reconciled condition
+func NewReconcileError(reason string, message string) error {
+	return &kerrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  metav1.StatusReason(reason),
+		Message: message,
+	}}
+}
+
+// CustomizeTCPReadinessProbe probes the readiness of gRPC applications by opening a TCP connection to their port,
+// unless readinessProbe is set. This only checks that the port accepts connections, not the gRPC health of the
+// application: the supported Kubernetes API has no gRPC probes, and grpc_health_probe may not be in the image.
+// The probe is derived from the protocol on each reconcile instead of being saved in the CR, so that it goes away
+// when the protocol changes.
+func CustomizeTCPReadinessProbe(pts *corev1.PodTemplateSpec, cr *appsodyv1beta1.AppsodyApplication) {
+	svc := cr.Spec.Service
+	if svc == nil || svc.Protocol != appsodyv1beta1.ServiceProtocolGRPC || cr.Spec.ReadinessProbe != nil {
+		return
+	}
+	port := svc.Port
+	if svc.TargetPort != nil {
+		port = *svc.TargetPort
+	}
+	appContainer := oputils.GetAppContainer(pts.Spec.Containers)
+	appContainer.ReadinessProbe = &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(int(port))},
+		},
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+	}
+}
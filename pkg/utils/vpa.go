@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	oputils "github.com/application-stacks/runtime-component-operator/pkg/utils"
+	appsodyv1beta1 "github.com/appsody/appsody-operator/pkg/apis/appsody/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// VerticalPodAutoscalerAPIVersion is the API version of VerticalPodAutoscalers
+	VerticalPodAutoscalerAPIVersion = "autoscaling.k8s.io/v1"
+
+	appContainerName = "app"
+)
+
+// CustomizeVerticalPodAutoscaler sets up the VerticalPodAutoscaler of the application's Deployment or StatefulSet
+func CustomizeVerticalPodAutoscaler(vpa *unstructured.Unstructured, cr *appsodyv1beta1.AppsodyApplication) {
+	vpa.SetLabels(cr.GetLabels())
+	vpa.SetAnnotations(oputils.MergeMaps(vpa.GetAnnotations(), cr.GetAnnotations()))
+	vpa.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       GetScaleTargetKind(cr),
+			"name":       cr.Name,
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": string(cr.Spec.ResourceRecommendation.GetUpdateMode()),
+		},
+	}
+}
+
+// GetResourceRecommendation returns the resources recommended by the VerticalPodAutoscaler for the application container,
+// or nil if there is no recommendation yet. Limits are recommended in proportion to the requests, as the
+// VerticalPodAutoscaler does when it applies recommendations.
+func GetResourceRecommendation(vpa *unstructured.Unstructured, resources *corev1.ResourceRequirements) *appsodyv1beta1.StatusResourceRecommendation {
+	containers, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok || container["containerName"] != appContainerName {
+			continue
+		}
+		target, _, _ := unstructured.NestedStringMap(container, "target")
+		recommendation := &appsodyv1beta1.StatusResourceRecommendation{Requests: corev1.ResourceList{}}
+		for name, value := range target {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				continue
+			}
+			recommendation.Requests[corev1.ResourceName(name)] = quantity
+			if resources == nil {
+				continue
+			}
+			request, hasRequest := resources.Requests[corev1.ResourceName(name)]
+			limit, hasLimit := resources.Limits[corev1.ResourceName(name)]
+			if hasRequest && hasLimit && request.MilliValue() > 0 {
+				if recommendation.Limits == nil {
+					recommendation.Limits = corev1.ResourceList{}
+				}
+				ratio := float64(limit.MilliValue()) / float64(request.MilliValue())
+				recommendation.Limits[corev1.ResourceName(name)] = *resource.NewMilliQuantity(int64(math.Ceil(float64(quantity.MilliValue())*ratio)), quantity.Format)
+			}
+		}
+		return recommendation
+	}
+	return nil
+}
+
+// GetRecommendationDifferences describes the requested resources that differ from the recommendation by more than the
+// given percentage
+func GetRecommendationDifferences(recommendation *appsodyv1beta1.StatusResourceRecommendation, resources *corev1.ResourceRequirements, tolerancePercentage int32) []string {
+	differences := []string{}
+	if recommendation == nil || resources == nil {
+		return differences
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		recommended, hasRecommendation := recommendation.Requests[name]
+		requested, hasRequest := resources.Requests[name]
+		if !hasRecommendation || !hasRequest || requested.MilliValue() == 0 {
+			continue
+		}
+		difference := math.Abs(float64(recommended.MilliValue()-requested.MilliValue())) / float64(requested.MilliValue()) * 100
+		if difference > float64(tolerancePercentage) {
+			differences = append(differences, fmt.Sprintf("%s request %s (recommended %s)", name, requested.String(), recommended.String()))
+		}
+	}
+	return differences
+}
+
+// IsCPUAutoscaled returns true if a HorizontalPodAutoscaler scales the application on CPU utilization
+func IsCPUAutoscaled(autoscaling *appsodyv1beta1.AppsodyApplicationAutoScaling) bool {
+	if autoscaling == nil || autoscaling.IsEventDriven() {
+		return false
+	}
+	for _, metric := range GetMetrics(autoscaling) {
+		if metric.Resource != nil && metric.Resource.Name == corev1.ResourceCPU {
+			return true
+		}
+	}
+	// The HorizontalPodAutoscaler targets 80% CPU utilization when no metric is set
+	return autoscaling.TargetMemoryUtilizationPercentage == nil && len(autoscaling.Metrics) == 0
+}